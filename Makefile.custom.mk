@@ -6,6 +6,16 @@ ginkgo-run: ## Runs ginkgo against the test suites.
 	@echo "====> $@"
 	ginkgo --skip-package /X -v ./...
 
+.PHONY: test-unit
+test-unit: ## Runs the unit tests, without the test suites that need a cluster.
+	@echo "====> $@"
+	go test ./cmd/... ./internal/...
+
+.PHONY: test-local
+test-local: ## Runs the local provider suite against an envtest API server.
+	@echo "====> $@"
+	KUBEBUILDER_ASSETS="$$(setup-envtest use -p path)" ginkgo -v ./providers/local/...
+
 .PHONY: ginkgo-lint
 ginkgo-lint: ## Runs ginkgolinter.
 	@echo "====> $@"
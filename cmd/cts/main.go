@@ -0,0 +1,215 @@
+// Command cts discovers and runs the cluster test suites under providers/ and
+// merges their reports into a single JUnit/JSON report and run summary.
+//
+// Usage:
+//
+//	cts run [flags] [root...] [ginkgo args...] [-- ginkgo args...]
+//	cts list [flags] [root...]
+//	cts snapshots [report-dir|snapshots.json|test-results.json...]
+//
+// Suites are selected by provider (e.g. capa), suite name (e.g. standard, upgrade,
+// private) and Ginkgo label filter. Roots default to ./providers.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/runner"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
+)
+
+const defaultReportDir = "/tmp/reports"
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "run":
+		err = runCmd(os.Args[2:])
+	case "list":
+		err = listCmd(os.Args[2:])
+	case "snapshots":
+		err = snapshotsCmd(os.Args[2:])
+	case "-h", "--help", "help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", os.Args[1]) // nolint:errcheck
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err) // nolint:errcheck
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, `Usage: cts <command> [flags] [root...] [-- ginkgo args...]
+
+Commands:
+  run         Run the selected suites and merge their reports
+  list        List the selected suites
+  snapshots   Print the crust-gather commands to replay the clusters of a failed run
+`) // nolint:errcheck
+}
+
+type selectFlags struct {
+	providers  string
+	suites     string
+	skipSuites string
+}
+
+func (f *selectFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.providers, "provider", "", "Comma-separated providers to run, e.g. capa,capz (default all)")
+	fs.StringVar(&f.suites, "suite", "", "Comma-separated suite names to run, e.g. standard,private (default all)")
+	fs.StringVar(&f.skipSuites, "skip-suite", "", "Comma-separated suite names to skip, e.g. upgrade")
+}
+
+func (f *selectFlags) discover(roots []string) ([]runner.Suite, error) {
+	if len(roots) == 0 {
+		roots = []string{"./providers"}
+	}
+
+	all, err := runner.Discover(roots)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := runner.Filter{
+		Providers:  splitList(f.providers),
+		Suites:     splitList(f.suites),
+		SkipSuites: splitList(f.skipSuites),
+	}
+
+	suites := []runner.Suite{}
+	for _, suite := range all {
+		if filter.Matches(suite) {
+			suites = append(suites, suite)
+		}
+	}
+	return suites, nil
+}
+
+func listCmd(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	var sel selectFlags
+	sel.register(fs)
+	_ = fs.Parse(args)
+
+	suites, err := sel.discover(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	for _, suite := range suites {
+		fmt.Printf("%s\t%s\n", suite.Slug(), suite.Path)
+	}
+	return nil
+}
+
+func runCmd(args []string) error {
+	ginkgoArgs := []string{}
+	for i, arg := range args {
+		if arg == "--" {
+			ginkgoArgs = args[i+1:]
+			args = args[:i]
+			break
+		}
+	}
+
+	reportDir := os.Getenv("REPORT_DIR")
+	if reportDir == "" {
+		reportDir = defaultReportDir
+	}
+
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	var sel selectFlags
+	sel.register(fs)
+	opts := runner.Options{Output: os.Stdout}
+	fs.StringVar(&opts.Ginkgo, "ginkgo", "ginkgo", "Path to the ginkgo binary")
+	fs.StringVar(&opts.ReportDir, "report-dir", reportDir, "Directory to write reports to (env REPORT_DIR)")
+	fs.DurationVar(&opts.Timeout, "timeout", 4*time.Hour, "Timeout for each suite")
+	fs.IntVar(&opts.Parallel, "parallel", 1, "Number of suites to run at the same time")
+	fs.StringVar(&opts.LabelFilter, "label-filter", "", "Ginkgo label filter applied to every suite")
+	timeoutOverrides := fs.String("timeout-overrides", "", "Test timeout overrides passed to every suite, e.g. deployAppsTimeout=30m")
+	_ = fs.Parse(args)
+
+	if *timeoutOverrides != "" {
+		if _, err := timeout.ParseOverrides(*timeoutOverrides); err != nil {
+			return fmt.Errorf("invalid --timeout-overrides: %w", err)
+		}
+		opts.Env = append(opts.Env, fmt.Sprintf("%s=%s", timeout.EnvOverrides, *timeoutOverrides))
+	}
+
+	// Anything following the roots is forwarded to ginkgo, matching the previous
+	// entrypoint.sh invocation of `<root> [ginkgo args...]`.
+	roots := fs.Args()
+	for i, arg := range roots {
+		if strings.HasPrefix(arg, "-") {
+			ginkgoArgs = append(append([]string{}, roots[i:]...), ginkgoArgs...)
+			roots = roots[:i]
+			break
+		}
+	}
+	opts.GinkgoArgs = ginkgoArgs
+
+	if os.Getenv("E2E_KUBECONFIG") == "" {
+		return errors.New("the env var 'E2E_KUBECONFIG' must be provided")
+	}
+
+	suites, err := sel.discover(roots)
+	if err != nil {
+		return err
+	}
+	if len(suites) == 0 {
+		return errors.New("no suites matched the selection")
+	}
+
+	if err := os.MkdirAll(opts.ReportDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	for _, suite := range suites {
+		fmt.Printf("Running suite %s (%s)\n", suite.Slug(), suite.Path)
+	}
+	results := runner.Run(ctx, suites, opts)
+
+	summary, err := runner.MergeReports(opts.ReportDir, results)
+	fmt.Println()
+	runner.PrintSummary(os.Stdout, summary)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\nWarning: some reports could not be merged: %v\n", err) // nolint:errcheck
+	}
+
+	if !summary.Passed {
+		return errors.New("one or more suites failed")
+	}
+	return nil
+}
+
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/snapshot"
+)
+
+// snapshotsCmd prints the crust-gather commands replaying the clusters of a failed run.
+// The path is a report directory, a snapshots.json or a Ginkgo JSON report, so it works
+// both with a full report directory and with only the merged report of a CI run.
+func snapshotsCmd(args []string) error {
+	fs := flag.NewFlagSet("snapshots", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: cts snapshots [report-dir|snapshots.json|test-results.json...]\n\nPaths default to $REPORT_DIR or %s.\n", defaultReportDir) // nolint:errcheck
+	}
+	_ = fs.Parse(args)
+
+	paths := fs.Args()
+	if len(paths) == 0 {
+		reportDir := os.Getenv(snapshot.EnvReportDir)
+		if reportDir == "" {
+			reportDir = defaultReportDir
+		}
+		paths = []string{reportDir}
+	}
+
+	indexes := []snapshot.Index{}
+	for _, path := range paths {
+		found, err := snapshot.Load(path)
+		if err != nil {
+			return fmt.Errorf("failed to load snapshots from %s: %w", path, err)
+		}
+		indexes = append(indexes, found...)
+	}
+
+	if len(indexes) == 0 {
+		fmt.Println("No crust-gather snapshots were recorded. Snapshots are only collected when a suite fails.")
+		return nil
+	}
+
+	printServeCommands(os.Stdout, indexes)
+	return nil
+}
+
+func printServeCommands(w io.Writer, indexes []snapshot.Index) {
+	for i, index := range indexes {
+		if i > 0 {
+			fmt.Fprintln(w) // nolint:errcheck
+		}
+
+		suite := index.Suite
+		if suite == "" {
+			suite = "unknown suite"
+		}
+		fmt.Fprintf(w, "# %s: cluster %s/%s\n", suite, index.Namespace, index.Cluster) // nolint:errcheck
+
+		for _, s := range index.Snapshots {
+			if !s.Available() {
+				fmt.Fprintf(w, "# %s snapshot of %s was not collected (%s)", s.Target, s.Cluster, s.Result) // nolint:errcheck
+				if s.Error != "" {
+					fmt.Fprintf(w, ": %s", s.Error) // nolint:errcheck
+				}
+				fmt.Fprintln(w) // nolint:errcheck
+				continue
+			}
+
+			note := ""
+			if s.Result == snapshot.ResultDegraded {
+				note = ", without pod logs"
+			}
+			fmt.Fprintf(w, "# %s snapshot of %s (%s%s, collected at %s in %s)\n", // nolint:errcheck
+				s.Target, s.Cluster, s.Result, note, s.StartedAt.Format(time.RFC3339), s.Duration)
+			fmt.Fprintln(w, s.ServeCommand()) // nolint:errcheck
+		}
+	}
+}
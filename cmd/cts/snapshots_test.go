@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/snapshot"
+)
+
+func TestPrintServeCommands(t *testing.T) {
+	startedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	indexes := []snapshot.Index{
+		{
+			Suite:     "capa-standard",
+			Cluster:   "t-capa",
+			Namespace: "org-giantswarm",
+			Snapshots: []snapshot.Snapshot{
+				{Target: snapshot.TargetWC, Cluster: "t-capa", Backend: snapshot.BackendOCI, Reference: "registry/snapshots:t-capa-wc", Result: snapshot.ResultOK, StartedAt: startedAt, Duration: "2m0s"},
+				{Target: snapshot.TargetMC, Cluster: "golem", Backend: snapshot.BackendOCI, Reference: "registry/snapshots:t-capa-mc", Result: snapshot.ResultFailed, Error: "context deadline exceeded"},
+			},
+		},
+		{
+			Cluster:   "t-capz",
+			Namespace: "org-giantswarm",
+			Snapshots: []snapshot.Snapshot{
+				{Target: snapshot.TargetWC, Cluster: "t-capz", Backend: snapshot.BackendTarball, Reference: "/reports/snapshots/t-capz-wc.tar.gz", Result: snapshot.ResultDegraded, StartedAt: startedAt, Duration: "1m0s"},
+				{Target: snapshot.TargetMC, Cluster: "golem", Result: snapshot.ResultFailed},
+			},
+		},
+	}
+
+	expected := `# capa-standard: cluster org-giantswarm/t-capa
+# wc snapshot of t-capa (ok, collected at 2026-01-02T03:04:05Z in 2m0s)
+kubectl crust-gather serve --reference registry/snapshots:t-capa-wc
+# mc snapshot of golem was not collected (failed): context deadline exceeded
+
+# unknown suite: cluster org-giantswarm/t-capz
+# wc snapshot of t-capz (degraded, without pod logs, collected at 2026-01-02T03:04:05Z in 1m0s)
+kubectl crust-gather serve --archive /reports/snapshots/t-capz-wc.tar.gz
+# mc snapshot of golem was not collected (failed)
+`
+
+	out := &bytes.Buffer{}
+	printServeCommands(out, indexes)
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
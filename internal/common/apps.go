@@ -26,19 +26,19 @@ import (
 	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
 )
 
-func RunApps(cfg *TestConfig) {
+func RunApps(env *state.Environment, cfg *TestConfig) {
 	Context("default apps and helm releases", func() {
 		It("all HelmReleases are deployed without issues", func() {
-			timeout := state.GetTestTimeout(timeout.DeployApps, 15*time.Minute)
+			timeout := env.Timeout(timeout.DeployApps)
 			logger.Log("Waiting for all HelmReleases to be deployed. Timeout: %s", timeout.String())
 
 			// Get all HelmReleases in the cluster organization namespace
 			helmReleaseList := &helmv2.HelmReleaseList{}
-			err := state.GetFramework().MC().List(state.GetContext(), helmReleaseList, ctrl.InNamespace(state.GetCluster().Organization.GetNamespace()))
+			err := env.MC().List(env.Context(), helmReleaseList, ctrl.InNamespace(env.Cluster().Organization.GetNamespace()))
 			Expect(err).NotTo(HaveOccurred())
 
 			if len(helmReleaseList.Items) == 0 {
-				logger.Log("No HelmReleases found in namespace %s", state.GetCluster().Organization.GetNamespace())
+				logger.Log("No HelmReleases found in namespace %s", env.Cluster().Organization.GetNamespace())
 				return
 			}
 
@@ -47,27 +47,27 @@ func RunApps(cfg *TestConfig) {
 				helmReleaseNamespacedNames = append(helmReleaseNamespacedNames, types.NamespacedName{Name: hr.GetName(), Namespace: hr.GetNamespace()})
 			}
 
-			Eventually(wait.Consistent(helmrelease.AreAllReady(state.GetContext(), state.GetFramework().MC(), helmReleaseNamespacedNames), 5, 10*time.Second)).
+			Eventually(wait.Consistent(helmrelease.AreAllReady(env.Context(), env.MC(), helmReleaseNamespacedNames), 5, 10*time.Second)).
 				WithTimeout(timeout).
 				WithPolling(10*time.Second).
 				Should(
 					Succeed(),
 					failurehandler.Bundle(
-						failurehandler.HelmReleasesNotReady(state.GetFramework(), state.GetCluster()),
-						failurehandler.PodsNotReady(state.GetFramework(), state.GetCluster()),
-						reportHelmReleaseOwningTeams(),
+						failurehandler.HelmReleasesNotReady(env.Framework(), env.Cluster()),
+						failurehandler.PodsNotReady(env.Framework(), env.Cluster()),
+						reportHelmReleaseOwningTeams(env),
 					),
 				)
 		})
 
 		It("all default apps are deployed without issues", func() {
-			timeout := state.GetTestTimeout(timeout.DeployApps, 15*time.Minute)
+			timeout := env.Timeout(timeout.DeployApps)
 			logger.Log("Waiting for all apps to be deployed. Timeout: %s", timeout.String())
-			logger.Log("Checking default apps deployed from the unified %s app.", state.GetCluster().ClusterApp.AppName)
+			logger.Log("Checking default apps deployed from the unified %s app.", env.Cluster().ClusterApp.AppName)
 
 			// Wait for all default-apps apps to be deployed
 			appList := &v1alpha1.AppList{}
-			err := state.GetFramework().MC().List(state.GetContext(), appList, ctrl.InNamespace(state.GetCluster().Organization.GetNamespace()), getDefaultAppsSelector())
+			err := env.MC().List(env.Context(), appList, ctrl.InNamespace(env.Cluster().Organization.GetNamespace()), getDefaultAppsSelector(env))
 			Expect(err).NotTo(HaveOccurred())
 
 			if len(appList.Items) == 0 {
@@ -79,14 +79,14 @@ func RunApps(cfg *TestConfig) {
 				appNamespacedNames = append(appNamespacedNames, types.NamespacedName{Name: app.Name, Namespace: app.Namespace})
 			}
 
-			Eventually(wait.IsAllAppDeployed(state.GetContext(), state.GetFramework().MC(), appNamespacedNames)).
+			Eventually(wait.IsAllAppDeployed(env.Context(), env.MC(), appNamespacedNames)).
 				WithTimeout(timeout).
 				WithPolling(10*time.Second).
 				Should(
 					BeTrue(),
 					failurehandler.Bundle(
-						failurehandler.AppIssues(state.GetFramework(), state.GetCluster()),
-						reportOwningTeams(),
+						failurehandler.AppIssues(env.Framework(), env.Cluster()),
+						reportOwningTeams(env),
 					),
 				)
 		})
@@ -94,29 +94,29 @@ func RunApps(cfg *TestConfig) {
 	Context("observability-bundle apps", func() {
 		It("all observability-bundle apps are deployed without issues", func() {
 			if !cfg.ObservabilityBundleInstalled {
-				Skip("observability-bundle is not installed")
+				skipUnsupported(cfg, CapabilityObservabilityBundle, "observability-bundle is not installed")
 			}
 
 			helper.SetResponsibleTeam(helper.TeamAtlas)
 
 			// We need to wait for the observability-bundle app to be deployed before we can check the apps it deploys.
-			observabilityAppsAppName := fmt.Sprintf("%s-%s", state.GetCluster().Name, "observability-bundle")
+			observabilityAppsAppName := fmt.Sprintf("%s-%s", env.Cluster().Name, "observability-bundle")
 
 			if !resourceExists("observability-bundle App CR", func() (bool, error) {
-				return appExists(observabilityAppsAppName, state.GetCluster().GetNamespace())
+				return appExists(env, observabilityAppsAppName, env.Cluster().GetNamespace())
 			}) {
 				Skip("observability-bundle App CR not found; the cluster chart deploys it as a HelmRelease, which the HelmRelease sibling assertion covers.")
 			}
 
-			bundleTimeout := state.GetTestTimeout(timeout.BundleApps, 5*time.Minute)
-			Eventually(wait.IsAppDeployed(state.GetContext(), state.GetFramework().MC(), observabilityAppsAppName, state.GetCluster().GetNamespace())).
+			bundleTimeout := env.Timeout(timeout.BundleApps)
+			Eventually(wait.IsAppDeployed(env.Context(), env.MC(), observabilityAppsAppName, env.Cluster().GetNamespace())).
 				WithTimeout(bundleTimeout).
 				WithPolling(5 * time.Second).
 				Should(BeTrue())
 
 			// Wait for all observability-bundle apps to be deployed
 			appList := &v1alpha1.AppList{}
-			err := state.GetFramework().MC().List(state.GetContext(), appList, ctrl.InNamespace(state.GetCluster().Organization.GetNamespace()), ctrl.MatchingLabels{"giantswarm.io/managed-by": observabilityAppsAppName})
+			err := env.MC().List(env.Context(), appList, ctrl.InNamespace(env.Cluster().Organization.GetNamespace()), ctrl.MatchingLabels{"giantswarm.io/managed-by": observabilityAppsAppName})
 			Expect(err).NotTo(HaveOccurred())
 
 			appNamespacedNames := []types.NamespacedName{}
@@ -124,58 +124,58 @@ func RunApps(cfg *TestConfig) {
 				appNamespacedNames = append(appNamespacedNames, types.NamespacedName{Name: app.Name, Namespace: app.Namespace})
 			}
 
-			Eventually(wait.IsAllAppDeployed(state.GetContext(), state.GetFramework().MC(), appNamespacedNames)).
+			Eventually(wait.IsAllAppDeployed(env.Context(), env.MC(), appNamespacedNames)).
 				WithTimeout(8*time.Minute).
 				WithPolling(10*time.Second).
 				Should(
 					BeTrue(),
-					failurehandler.AppIssues(state.GetFramework(), state.GetCluster()),
+					failurehandler.AppIssues(env.Framework(), env.Cluster()),
 				)
 		})
 
 		It("all observability-bundle HelmReleases are deployed without issues", func() {
 			if !cfg.ObservabilityBundleInstalled {
-				Skip("observability-bundle is not installed")
+				skipUnsupported(cfg, CapabilityObservabilityBundle, "observability-bundle is not installed")
 			}
 
 			helper.SetResponsibleTeam(helper.TeamAtlas)
 
-			parent := fmt.Sprintf("%s-%s", state.GetCluster().Name, "observability-bundle")
+			parent := fmt.Sprintf("%s-%s", env.Cluster().Name, "observability-bundle")
 			if !resourceExists("observability-bundle HelmRelease", func() (bool, error) {
-				return helmReleaseExists(parent, state.GetCluster().GetNamespace())
+				return helmReleaseExists(env, parent, env.Cluster().GetNamespace())
 			}) {
 				Skip("observability-bundle HelmRelease not found; the cluster chart deploys it as an App CR, which the App-CR sibling assertion covers.")
 			}
 
-			waitForBundleHelmReleases(parent, 8*time.Minute)
+			waitForBundleHelmReleases(env, parent, 8*time.Minute)
 		})
 	})
 	Context("security-bundle apps", func() {
 		It("all security-bundle apps are deployed without issues", func() {
 			if !cfg.SecurityBundleInstalled {
-				Skip("security-bundle is not installed")
+				skipUnsupported(cfg, CapabilitySecurityBundle, "security-bundle is not installed")
 			}
 
 			helper.SetResponsibleTeam(helper.TeamShield)
 
 			// We need to wait for the security-bundle app to be deployed before we can check the apps it deploys.
-			securityAppsAppName := fmt.Sprintf("%s-%s", state.GetCluster().Name, "security-bundle")
+			securityAppsAppName := fmt.Sprintf("%s-%s", env.Cluster().Name, "security-bundle")
 
 			if !resourceExists("security-bundle App CR", func() (bool, error) {
-				return appExists(securityAppsAppName, state.GetCluster().GetNamespace())
+				return appExists(env, securityAppsAppName, env.Cluster().GetNamespace())
 			}) {
 				Skip("security-bundle App CR not found; the cluster chart deploys it as a HelmRelease, which the HelmRelease sibling assertion covers.")
 			}
 
-			bundleTimeout := state.GetTestTimeout(timeout.BundleApps, 5*time.Minute)
-			Eventually(wait.IsAppDeployed(state.GetContext(), state.GetFramework().MC(), securityAppsAppName, state.GetCluster().GetNamespace())).
+			bundleTimeout := env.Timeout(timeout.BundleApps)
+			Eventually(wait.IsAppDeployed(env.Context(), env.MC(), securityAppsAppName, env.Cluster().GetNamespace())).
 				WithTimeout(bundleTimeout).
 				WithPolling(5 * time.Second).
 				Should(BeTrue())
 
 			// Wait for all security-bundle apps to be deployed
 			appList := &v1alpha1.AppList{}
-			err := state.GetFramework().MC().List(state.GetContext(), appList, ctrl.InNamespace(state.GetCluster().Organization.GetNamespace()), ctrl.MatchingLabels{"giantswarm.io/managed-by": securityAppsAppName})
+			err := env.MC().List(env.Context(), appList, ctrl.InNamespace(env.Cluster().Organization.GetNamespace()), ctrl.MatchingLabels{"giantswarm.io/managed-by": securityAppsAppName})
 			Expect(err).NotTo(HaveOccurred())
 
 			appNamespacedNames := []types.NamespacedName{}
@@ -183,30 +183,30 @@ func RunApps(cfg *TestConfig) {
 				appNamespacedNames = append(appNamespacedNames, types.NamespacedName{Name: app.Name, Namespace: app.Namespace})
 			}
 
-			Eventually(wait.IsAllAppDeployed(state.GetContext(), state.GetFramework().MC(), appNamespacedNames)).
+			Eventually(wait.IsAllAppDeployed(env.Context(), env.MC(), appNamespacedNames)).
 				WithTimeout(10*time.Minute).
 				WithPolling(10*time.Second).
 				Should(
 					BeTrue(),
-					failurehandler.AppIssues(state.GetFramework(), state.GetCluster()),
+					failurehandler.AppIssues(env.Framework(), env.Cluster()),
 				)
 		})
 
 		It("all security-bundle HelmReleases are deployed without issues", func() {
 			if !cfg.SecurityBundleInstalled {
-				Skip("security-bundle is not installed")
+				skipUnsupported(cfg, CapabilitySecurityBundle, "security-bundle is not installed")
 			}
 
 			helper.SetResponsibleTeam(helper.TeamShield)
 
-			parent := fmt.Sprintf("%s-%s", state.GetCluster().Name, "security-bundle")
+			parent := fmt.Sprintf("%s-%s", env.Cluster().Name, "security-bundle")
 			if !resourceExists("security-bundle HelmRelease", func() (bool, error) {
-				return helmReleaseExists(parent, state.GetCluster().GetNamespace())
+				return helmReleaseExists(env, parent, env.Cluster().GetNamespace())
 			}) {
 				Skip("security-bundle HelmRelease not found; the cluster chart deploys it as an App CR, which the App-CR sibling assertion covers.")
 			}
 
-			waitForBundleHelmReleases(parent, 10*time.Minute)
+			waitForBundleHelmReleases(env, parent, 10*time.Minute)
 		})
 	})
 }
@@ -217,18 +217,18 @@ func RunApps(cfg *TestConfig) {
 // variant) to be Ready too. childrenTimeout bounds the children's wait; the
 // parent uses the shared BundleApps timeout (default 5m) to match the
 // App-based sibling's behaviour.
-func waitForBundleHelmReleases(parentName string, childrenTimeout time.Duration) {
-	mc := state.GetFramework().MC()
-	org := state.GetCluster().Organization.GetNamespace()
+func waitForBundleHelmReleases(env *state.Environment, parentName string, childrenTimeout time.Duration) {
+	mc := env.MC()
+	org := env.Cluster().Organization.GetNamespace()
 
-	parentTimeout := state.GetTestTimeout(timeout.BundleApps, 5*time.Minute)
-	Eventually(helmrelease.IsHelmReleaseReady(state.GetContext(), mc, parentName, org)).
+	parentTimeout := env.Timeout(timeout.BundleApps)
+	Eventually(helmrelease.IsHelmReleaseReady(env.Context(), mc, parentName, org)).
 		WithTimeout(parentTimeout).
 		WithPolling(5 * time.Second).
 		Should(BeTrue())
 
 	helmReleaseList := &helmv2.HelmReleaseList{}
-	err := mc.List(state.GetContext(), helmReleaseList, ctrl.InNamespace(org), ctrl.MatchingLabels{"giantswarm.io/managed-by": parentName})
+	err := mc.List(env.Context(), helmReleaseList, ctrl.InNamespace(org), ctrl.MatchingLabels{"giantswarm.io/managed-by": parentName})
 	Expect(err).NotTo(HaveOccurred())
 
 	children := make([]types.NamespacedName, 0, len(helmReleaseList.Items))
@@ -236,14 +236,14 @@ func waitForBundleHelmReleases(parentName string, childrenTimeout time.Duration)
 		children = append(children, types.NamespacedName{Name: hr.GetName(), Namespace: hr.GetNamespace()})
 	}
 
-	Eventually(wait.Consistent(helmrelease.AreAllReady(state.GetContext(), mc, children), 5, 10*time.Second)).
+	Eventually(wait.Consistent(helmrelease.AreAllReady(env.Context(), mc, children), 5, 10*time.Second)).
 		WithTimeout(childrenTimeout).
 		WithPolling(10*time.Second).
 		Should(
 			Succeed(),
 			failurehandler.Bundle(
-				failurehandler.HelmReleasesNotReady(state.GetFramework(), state.GetCluster()),
-				reportHelmReleaseOwningTeams(),
+				failurehandler.HelmReleasesNotReady(env.Framework(), env.Cluster()),
+				reportHelmReleaseOwningTeams(env),
 			),
 		)
 }
@@ -271,9 +271,9 @@ func resourceExists(desc string, check func() (bool, error)) bool {
 // other error is returned so the caller can retry rather than treat it as
 // absent. Used by bundle assertions to decide whether the cluster chart is in
 // App-CR mode for this bundle.
-func appExists(name, namespace string) (bool, error) {
+func appExists(env *state.Environment, name, namespace string) (bool, error) {
 	app := &v1alpha1.App{}
-	err := state.GetFramework().MC().Get(state.GetContext(), ctrl.ObjectKey{Name: name, Namespace: namespace}, app)
+	err := env.MC().Get(env.Context(), ctrl.ObjectKey{Name: name, Namespace: namespace}, app)
 	if err == nil {
 		return true, nil
 	}
@@ -288,9 +288,9 @@ func appExists(name, namespace string) (bool, error) {
 // (false, nil); any other error is returned so the caller can retry rather than
 // treat it as absent. Used by bundle assertions to decide whether the cluster
 // chart is in HelmRelease mode for this bundle.
-func helmReleaseExists(name, namespace string) (bool, error) {
+func helmReleaseExists(env *state.Environment, name, namespace string) (bool, error) {
 	hr := &helmv2.HelmRelease{}
-	err := state.GetFramework().MC().Get(state.GetContext(), ctrl.ObjectKey{Name: name, Namespace: namespace}, hr)
+	err := env.MC().Get(env.Context(), ctrl.ObjectKey{Name: name, Namespace: namespace}, hr)
 	if err == nil {
 		return true, nil
 	}
@@ -300,15 +300,15 @@ func helmReleaseExists(name, namespace string) (bool, error) {
 	return false, err
 }
 
-func getDefaultAppsSelector() ctrl.MatchingLabels {
+func getDefaultAppsSelector(env *state.Environment) ctrl.MatchingLabels {
 	// All providers now use unified cluster apps that deploy default apps directly via Helm
 	return ctrl.MatchingLabels{
-		"giantswarm.io/cluster":        state.GetCluster().Name,
+		"giantswarm.io/cluster":        env.Cluster().Name,
 		"app.kubernetes.io/managed-by": "Helm",
 	}
 }
 
-func reportOwningTeams() failurehandler.FailureHandler {
+func reportOwningTeams(env *state.Environment) failurehandler.FailureHandler {
 	return failurehandler.Wrap(func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		defer cancel()
@@ -316,7 +316,7 @@ func reportOwningTeams() failurehandler.FailureHandler {
 		logger.Log("Attempting to get responsible teams for any failing Apps")
 
 		appList := &v1alpha1.AppList{}
-		err := state.GetFramework().MC().List(ctx, appList, ctrl.InNamespace(state.GetCluster().Organization.GetNamespace()), getDefaultAppsSelector())
+		err := env.MC().List(ctx, appList, ctrl.InNamespace(env.Cluster().Organization.GetNamespace()), getDefaultAppsSelector(env))
 		if err != nil {
 			logger.Log("Failed to get Apps - %v", err)
 			return
@@ -328,6 +328,16 @@ func reportOwningTeams() failurehandler.FailureHandler {
 				if ok && !helper.SetResponsibleTeamFromLabel(teamLabel) {
 					logger.Log("Unknown owner team - App='%s', TeamLabel='%s'", app.Name, teamLabel)
 				}
+
+				team, _ := helper.TeamFromLabel(teamLabel)
+				helper.RecordFailingResource(helper.FailingResource{
+					Kind:      "App",
+					Name:      app.Name,
+					Namespace: app.Namespace,
+					Team:      team,
+					Reason:    app.Status.Release.Status,
+					Message:   app.Status.Release.Reason,
+				})
 			}
 		}
 
@@ -335,7 +345,7 @@ func reportOwningTeams() failurehandler.FailureHandler {
 }
 
 // reportHelmReleaseOwningTeams reports the teams responsible for failing HelmReleases
-func reportHelmReleaseOwningTeams() failurehandler.FailureHandler {
+func reportHelmReleaseOwningTeams(env *state.Environment) failurehandler.FailureHandler {
 	return failurehandler.Wrap(func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 		defer cancel()
@@ -343,7 +353,7 @@ func reportHelmReleaseOwningTeams() failurehandler.FailureHandler {
 		logger.Log("Attempting to get responsible teams for any failing HelmReleases")
 
 		helmReleaseList := &helmv2.HelmReleaseList{}
-		err := state.GetFramework().MC().List(ctx, helmReleaseList, ctrl.InNamespace(state.GetCluster().Organization.GetNamespace()))
+		err := env.MC().List(ctx, helmReleaseList, ctrl.InNamespace(env.Cluster().Organization.GetNamespace()))
 		if err != nil {
 			logger.Log("Failed to get HelmReleases - %v", err)
 			return
@@ -352,9 +362,24 @@ func reportHelmReleaseOwningTeams() failurehandler.FailureHandler {
 		for _, hr := range helmReleaseList.Items {
 			condition := apimeta.FindStatusCondition(hr.Status.Conditions, "Ready")
 			if condition == nil || condition.Status != metav1.ConditionTrue {
-				if teamLabel, ok := hr.GetLabels()["application.giantswarm.io/team"]; ok && !helper.SetResponsibleTeamFromLabel(teamLabel) {
+				teamLabel, ok := hr.GetLabels()["application.giantswarm.io/team"]
+				if ok && !helper.SetResponsibleTeamFromLabel(teamLabel) {
 					logger.Log("Unknown owner team - HelmRelease='%s', TeamLabel='%s'", hr.GetName(), teamLabel)
 				}
+
+				team, _ := helper.TeamFromLabel(teamLabel)
+				resource := helper.FailingResource{
+					Kind:      "HelmRelease",
+					Name:      hr.GetName(),
+					Namespace: hr.GetNamespace(),
+					Team:      team,
+					Reason:    "NoReadyCondition",
+				}
+				if condition != nil {
+					resource.Reason = condition.Reason
+					resource.Message = condition.Message
+				}
+				helper.RecordFailingResource(resource)
 			}
 		}
 	})
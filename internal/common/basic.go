@@ -25,7 +25,7 @@ import (
 	. "github.com/onsi/gomega"    //nolint:staticcheck
 )
 
-func runBasic(cfg *TestConfig) {
+func runBasic(env *state.Environment, cfg *TestConfig) {
 	Context("basic", func() {
 		var wcClient *client.Client
 
@@ -35,18 +35,18 @@ func runBasic(cfg *TestConfig) {
 			// the whole spec.
 			Eventually(func() error {
 				var err error
-				wcClient, err = state.GetFramework().WC(state.GetCluster().Name)
+				wcClient, err = env.WC()
 				return err
 			}).
-				WithTimeout(1 * time.Minute).
+				WithTimeout(env.Timeout(timeout.ClientSetup)).
 				WithPolling(5 * time.Second).
 				Should(Succeed())
 		})
 
 		It("should be able to connect to the management cluster", func() {
-			connectionTimeout := state.GetTestTimeout(timeout.ClusterConnection, 3*time.Minute)
+			connectionTimeout := env.Timeout(timeout.ClusterConnection)
 			Eventually(func() error {
-				return state.GetFramework().MC().CheckConnection()
+				return env.MC().CheckConnection()
 			}).
 				WithTimeout(connectionTimeout).
 				WithPolling(5 * time.Second).
@@ -54,7 +54,7 @@ func runBasic(cfg *TestConfig) {
 		})
 
 		It("should be able to connect to the workload cluster", func() {
-			connectionTimeout := state.GetTestTimeout(timeout.ClusterConnection, 3*time.Minute)
+			connectionTimeout := env.Timeout(timeout.ClusterConnection)
 			Eventually(func() error {
 				return wcClient.CheckConnection()
 			}).
@@ -64,7 +64,7 @@ func runBasic(cfg *TestConfig) {
 		})
 
 		It("has all the control-plane nodes running", func() {
-			replicas, err := state.GetFramework().GetExpectedControlPlaneReplicas(state.GetContext(), state.GetCluster().Name, state.GetCluster().GetNamespace())
+			replicas, err := env.Framework().GetExpectedControlPlaneReplicas(env.Context(), env.Cluster().Name, env.Cluster().GetNamespace())
 			Expect(err).NotTo(HaveOccurred())
 
 			// Skip this test is the cluster is a managed cluster (e.g. EKS)
@@ -72,30 +72,30 @@ func runBasic(cfg *TestConfig) {
 				Skip("ControlPlane is not supported.")
 			}
 
-			wcClient, err := state.GetFramework().WC(state.GetCluster().Name)
+			wcClient, err := env.WC()
 			Expect(err).NotTo(HaveOccurred())
 
 			Eventually(
 				wait.ConsistentWaitCondition(
-					wait.AreNumNodesReady(state.GetContext(), wcClient, int(replicas), &cr.MatchingLabels{"node-role.kubernetes.io/control-plane": ""}),
+					wait.AreNumNodesReady(env.Context(), wcClient, int(replicas), &cr.MatchingLabels{"node-role.kubernetes.io/control-plane": ""}),
 					5,
 					5*time.Second,
 				)).
-				WithTimeout(15 * time.Minute).
+				WithTimeout(env.Timeout(timeout.ControlPlaneNodesReady)).
 				WithPolling(wait.DefaultInterval).
 				Should(Succeed())
 		})
 
 		It("has all the worker nodes running", func() {
 			values := &application.ClusterValues{}
-			err := state.GetFramework().MC().GetHelmValues(state.GetCluster().Name, state.GetCluster().GetNamespace(), values)
+			err := env.MC().GetHelmValues(env.Cluster().Name, env.Cluster().GetNamespace(), values)
 			Expect(err).NotTo(HaveOccurred())
 
-			wcClient, err := state.GetFramework().WC(state.GetCluster().Name)
+			wcClient, err := env.WC()
 			Expect(err).NotTo(HaveOccurred())
 
-			Eventually(wait.Consistent(CheckWorkerNodesReady(state.GetContext(), wcClient, values), 12, 5*time.Second)).
-				WithTimeout(15 * time.Minute).
+			Eventually(wait.Consistent(CheckWorkerNodesReady(env.Context(), wcClient, values), 12, 5*time.Second)).
+				WithTimeout(env.Timeout(timeout.WorkerNodesReady)).
 				WithPolling(wait.DefaultInterval).
 				Should(Succeed())
 		})
@@ -103,75 +103,75 @@ func runBasic(cfg *TestConfig) {
 		It("has all its Deployments Ready (means all replicas are running)", func() {
 			Eventually(
 				wait.ConsistentWaitCondition(
-					wait.AreAllDeploymentsReady(state.GetContext(), wcClient),
+					wait.AreAllDeploymentsReady(env.Context(), wcClient),
 					10,
 					time.Second,
 				)).
-				WithTimeout(15*time.Minute).
+				WithTimeout(env.Timeout(timeout.WorkloadsReady)).
 				WithPolling(wait.DefaultInterval).
 				Should(
 					Succeed(),
-					failurehandler.DeploymentsNotReady(state.GetFramework(), state.GetCluster()),
+					failurehandler.DeploymentsNotReady(env.Framework(), env.Cluster()),
 				)
 		})
 
 		It("has all its StatefulSets Ready (means all replicas are running)", func() {
 			Eventually(
 				wait.ConsistentWaitCondition(
-					wait.AreAllStatefulSetsReady(state.GetContext(), wcClient),
+					wait.AreAllStatefulSetsReady(env.Context(), wcClient),
 					10,
 					time.Second,
 				)).
-				WithTimeout(15*time.Minute).
+				WithTimeout(env.Timeout(timeout.WorkloadsReady)).
 				WithPolling(wait.DefaultInterval).
 				Should(
 					Succeed(),
-					failurehandler.StatefulSetsNotReady(state.GetFramework(), state.GetCluster()),
+					failurehandler.StatefulSetsNotReady(env.Framework(), env.Cluster()),
 				)
 		})
 
 		It("has all its DaemonSets Ready (means all daemon pods are running)", func() {
 			Eventually(
 				wait.ConsistentWaitCondition(
-					wait.AreAllDaemonSetsReady(state.GetContext(), wcClient),
+					wait.AreAllDaemonSetsReady(env.Context(), wcClient),
 					10,
 					time.Second,
 				)).
-				WithTimeout(15*time.Minute).
+				WithTimeout(env.Timeout(timeout.WorkloadsReady)).
 				WithPolling(wait.DefaultInterval).
 				Should(
 					Succeed(),
-					failurehandler.DaemonSetsNotReady(state.GetFramework(), state.GetCluster()),
+					failurehandler.DaemonSetsNotReady(env.Framework(), env.Cluster()),
 				)
 		})
 
 		It("has all its Jobs completed successfully", func() {
 			Eventually(
 				wait.ConsistentWaitCondition(
-					wait.AreAllJobsSucceeded(state.GetContext(), wcClient),
+					wait.AreAllJobsSucceeded(env.Context(), wcClient),
 					10,
 					time.Second,
 				)).
-				WithTimeout(15*time.Minute).
+				WithTimeout(env.Timeout(timeout.WorkloadsReady)).
 				WithPolling(wait.DefaultInterval).
 				Should(
 					Succeed(),
-					failurehandler.JobsUnsuccessful(state.GetFramework(), state.GetCluster()),
+					failurehandler.JobsUnsuccessful(env.Framework(), env.Cluster()),
 				)
 		})
 
 		It("has all of its Pods in the Running state", func() {
 			Eventually(
 				wait.ConsistentWaitCondition(
-					AreAllPodsInSuccessfulPhaseWithFilter(state.GetContext(), wcClient, armExcludedPodLabels(cfg)),
+					AreAllPodsInSuccessfulPhaseWithFilter(env.Context(), wcClient, armExcludedPodLabels(cfg)),
 					10,
 					time.Second,
 				)).
-				WithTimeout(15*time.Minute).
+				WithTimeout(env.Timeout(timeout.WorkloadsReady)).
 				WithPolling(wait.DefaultInterval).
 				Should(
 					Succeed(),
-					failurehandler.PodsNotReady(state.GetFramework(), state.GetCluster()),
+					failurehandler.PodsNotReady(env.Framework(), env.Cluster()),
 				)
 		})
 
@@ -187,42 +187,42 @@ func runBasic(cfg *TestConfig) {
 
 			Eventually(
 				wait.ConsistentWaitCondition(
-					wait.AreNoPodsCrashLoopingWithFilter(state.GetContext(), wcClient, 2, filterLabels),
+					wait.AreNoPodsCrashLoopingWithFilter(env.Context(), wcClient, 2, filterLabels),
 					10,
 					5*time.Second,
 				)).
-				WithTimeout(15*time.Minute).
+				WithTimeout(env.Timeout(timeout.WorkloadsReady)).
 				WithPolling(wait.DefaultInterval).
 				Should(
 					Succeed(),
-					failurehandler.PodsNotReady(state.GetFramework(), state.GetCluster()),
+					failurehandler.PodsNotReady(env.Framework(), env.Cluster()),
 				)
 		})
 
 		It("has Cluster Available condition with Status='True'", func() {
 			// Overriding the default timeout, when ClusterReadyTimeout is set
-			timeout := state.GetTestTimeout(timeout.ClusterReadyTimeout, 15*time.Minute)
+			timeout := env.Timeout(timeout.ClusterReadyTimeout)
 
-			mcClient := state.GetFramework().MC()
-			cluster := state.GetCluster()
-			Eventually(wait.IsClusterConditionSet(state.GetContext(), mcClient, cluster.Name, cluster.GetNamespace(), capi.AvailableCondition, metav1.ConditionTrue, "")).
+			mcClient := env.MC()
+			cluster := env.Cluster()
+			Eventually(wait.IsClusterConditionSet(env.Context(), mcClient, cluster.Name, cluster.GetNamespace(), capi.AvailableCondition, metav1.ConditionTrue, "")).
 				WithTimeout(timeout).
 				WithPolling(wait.DefaultInterval).
 				Should(BeTrue())
 		})
 
 		It("has all machine pools ready and running", func() {
-			mcClient := state.GetFramework().MC()
-			cluster := state.GetCluster()
+			mcClient := env.MC()
+			cluster := env.Cluster()
 
-			machinePools, err := state.GetFramework().GetMachinePools(state.GetContext(), cluster.Name, cluster.GetNamespace())
+			machinePools, err := env.Framework().GetMachinePools(env.Context(), cluster.Name, cluster.GetNamespace())
 			Expect(err).NotTo(HaveOccurred())
 			if len(machinePools) == 0 {
 				Skip("Machine pools are not found")
 			}
 
-			Eventually(wait.Consistent(CheckMachinePoolsReadyAndRunning(state.GetContext(), mcClient, cluster.Name, cluster.GetNamespace()), 5, 5*time.Second)).
-				WithTimeout(30 * time.Minute).
+			Eventually(wait.Consistent(CheckMachinePoolsReadyAndRunning(env.Context(), mcClient, cluster.Name, cluster.GetNamespace()), 5, 5*time.Second)).
+				WithTimeout(env.Timeout(timeout.MachinePoolsReady)).
 				WithPolling(wait.DefaultInterval).
 				Should(Succeed())
 		})
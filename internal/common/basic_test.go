@@ -0,0 +1,244 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/giantswarm/clustertest/v5/pkg/application"
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/testenv"
+)
+
+func TestCheckWorkerNodesReady(t *testing.T) {
+	testCases := []struct {
+		name      string
+		nodePools application.NodePools
+		nodes     []cr.Object
+		expectErr bool
+	}{
+		{
+			name:      "machine deployment replicas all ready",
+			nodePools: application.NodePools{"pool0": {Replicas: 2}},
+			nodes: []cr.Object{
+				testenv.ControlPlaneNode("cp-1", true),
+				testenv.Node("worker-1", true, nil),
+				testenv.Node("worker-2", true, nil),
+			},
+		},
+		{
+			name:      "machine deployment replica not ready",
+			nodePools: application.NodePools{"pool0": {Replicas: 2}},
+			nodes: []cr.Object{
+				testenv.Node("worker-1", true, nil),
+				testenv.Node("worker-2", false, nil),
+			},
+			expectErr: true,
+		},
+		{
+			name:      "control plane nodes are not counted as workers",
+			nodePools: application.NodePools{"pool0": {Replicas: 2}},
+			nodes: []cr.Object{
+				testenv.ControlPlaneNode("cp-1", true),
+				testenv.Node("worker-1", true, nil),
+			},
+			expectErr: true,
+		},
+		{
+			name:      "machine pool within min and max size",
+			nodePools: application.NodePools{"pool0": {MinSize: 1, MaxSize: 3}},
+			nodes: []cr.Object{
+				testenv.Node("worker-1", true, nil),
+				testenv.Node("worker-2", true, nil),
+			},
+		},
+		{
+			name:      "machine pool above max size",
+			nodePools: application.NodePools{"pool0": {MinSize: 1, MaxSize: 1}},
+			nodes: []cr.Object{
+				testenv.Node("worker-1", true, nil),
+				testenv.Node("worker-2", true, nil),
+			},
+			expectErr: true,
+		},
+		{
+			name: "karpenter pool does not require nodes",
+			nodePools: application.NodePools{
+				"pool0":     {MinSize: 1, MaxSize: 2},
+				"karpenter": {},
+			},
+			nodes: []cr.Object{
+				testenv.Node("worker-1", true, nil),
+				testenv.Node("karpenter-1", true, nil),
+				testenv.Node("karpenter-2", true, nil),
+				testenv.Node("karpenter-3", true, nil),
+			},
+		},
+		{
+			name:      "no worker nodes",
+			nodePools: application.NodePools{"pool0": {Replicas: 1}},
+			nodes:     []cr.Object{testenv.ControlPlaneNode("cp-1", true)},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wcClient := testenv.NewClient(tc.nodes...)
+			values := &application.ClusterValues{NodePools: tc.nodePools}
+
+			err := CheckWorkerNodesReady(context.Background(), wcClient, values)()
+			if tc.expectErr && err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckMachinePoolsReadyAndRunning(t *testing.T) {
+	const (
+		clusterName = "test-cluster"
+		namespace   = "org-test"
+	)
+
+	testCases := []struct {
+		name         string
+		machinePools []cr.Object
+		expectErr    bool
+	}{
+		{
+			name: "all running with replicas available",
+			machinePools: []cr.Object{
+				testenv.MachinePool(namespace, "pool0", clusterName, capi.MachinePoolPhaseRunning, 3, 3),
+				testenv.MachinePool(namespace, "pool1", clusterName, capi.MachinePoolPhaseRunning, 1, 2),
+			},
+		},
+		{
+			name:         "no machine pools",
+			machinePools: nil,
+		},
+		{
+			name: "machine pool still scaling",
+			machinePools: []cr.Object{
+				testenv.MachinePool(namespace, "pool0", clusterName, capi.MachinePoolPhaseScalingUp, 3, 3),
+			},
+			expectErr: true,
+		},
+		{
+			name: "replicas not yet available",
+			machinePools: []cr.Object{
+				testenv.MachinePool(namespace, "pool0", clusterName, capi.MachinePoolPhaseRunning, 3, 2),
+			},
+			expectErr: true,
+		},
+		{
+			name: "zero desired replicas",
+			machinePools: []cr.Object{
+				testenv.MachinePool(namespace, "pool0", clusterName, capi.MachinePoolPhaseRunning, 0, 0),
+			},
+			expectErr: true,
+		},
+		{
+			name: "machine pools of other clusters are ignored",
+			machinePools: []cr.Object{
+				testenv.MachinePool(namespace, "pool0", clusterName, capi.MachinePoolPhaseRunning, 1, 1),
+				testenv.MachinePool(namespace, "other-pool0", "other-cluster", capi.MachinePoolPhaseFailed, 1, 0),
+				testenv.MachinePool("org-other", "pool0", clusterName, capi.MachinePoolPhaseFailed, 1, 0),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mcClient := testenv.NewClient(tc.machinePools...)
+
+			err := CheckMachinePoolsReadyAndRunning(context.Background(), mcClient, clusterName, namespace)()
+			if tc.expectErr && err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAreAllPodsInSuccessfulPhaseWithFilter(t *testing.T) {
+	testCases := []struct {
+		name         string
+		pods         []cr.Object
+		filterLabels []string
+		expectReady  bool
+	}{
+		{
+			name: "running and succeeded pods",
+			pods: []cr.Object{
+				testenv.Pod("kube-system", "coredns", corev1.PodRunning, nil),
+				testenv.Pod("kube-system", "job-pod", corev1.PodSucceeded, nil),
+			},
+			expectReady: true,
+		},
+		{
+			name:        "no pods",
+			expectReady: true,
+		},
+		{
+			name: "pending pod",
+			pods: []cr.Object{
+				testenv.Pod("kube-system", "coredns", corev1.PodRunning, nil),
+				testenv.Pod("kube-system", "pending", corev1.PodPending, nil),
+			},
+		},
+		{
+			name: "failed pod",
+			pods: []cr.Object{
+				testenv.Pod("kube-system", "failed", corev1.PodFailed, nil),
+			},
+		},
+		{
+			name: "failing pod excluded by filter",
+			pods: []cr.Object{
+				testenv.Pod("kube-system", "coredns", corev1.PodRunning, nil),
+				testenv.Pod("kube-system", "net-exporter", corev1.PodFailed, map[string]string{"app.kubernetes.io/name": "net-exporter"}),
+			},
+			filterLabels: []string{"app.kubernetes.io/name notin (net-exporter, cert-exporter)"},
+			expectReady:  true,
+		},
+		{
+			name: "failing pod not matched by filter",
+			pods: []cr.Object{
+				testenv.Pod("kube-system", "coredns", corev1.PodFailed, map[string]string{"app.kubernetes.io/name": "coredns"}),
+			},
+			filterLabels: []string{"app.kubernetes.io/name notin (net-exporter)"},
+		},
+		{
+			name: "invalid filter is ignored",
+			pods: []cr.Object{
+				testenv.Pod("kube-system", "failed", corev1.PodFailed, nil),
+			},
+			filterLabels: []string{"not a valid selector ("},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wcClient := testenv.NewClient(tc.pods...)
+
+			ready, err := AreAllPodsInSuccessfulPhaseWithFilter(context.Background(), wcClient, tc.filterLabels)()
+			if ready != tc.expectReady {
+				t.Fatalf("expected ready=%t, got ready=%t (err: %v)", tc.expectReady, ready, err)
+			}
+			if tc.expectReady && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if !tc.expectReady && err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+		})
+	}
+}
@@ -2,15 +2,23 @@ package common
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	certmanager "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/giantswarm/clustertest/v5/pkg/client"
 	"github.com/giantswarm/clustertest/v5/pkg/logger"
+	"github.com/giantswarm/clustertest/v5/pkg/wait"
 	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
 	. "github.com/onsi/gomega"    //nolint:staticcheck
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 
 	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
 	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
@@ -24,41 +32,237 @@ import (
 
 var clusterIssuers = []string{"selfsigned-giantswarm", "letsencrypt-giantswarm"}
 
-func runCertManager(certManagerSupported bool) {
+// certificateNamespace returns the namespace holding the Certificate requested from the given
+// ClusterIssuer. Each issuer gets its own, so a spec never reuses the namespace of a previous
+// spec while it is still terminating.
+func certificateNamespace(clusterIssuerName string) string {
+	return fmt.Sprintf("test-cert-manager-%s", clusterIssuerName)
+}
+
+func runCertManager(env *state.Environment, cfg *TestConfig) {
 	Context("cert-manager ClusterIssuers", func() {
 		var wcClient *client.Client
 
 		BeforeEach(func() {
-			if !certManagerSupported {
-				Skip("cert-manager is not supported in this cluster configuration")
+			if !cfg.CertManagerSupported {
+				skipUnsupported(cfg, CapabilityCertManager, "cert-manager is not supported in this cluster configuration")
 			}
 
 			helper.SetResponsibleTeam(helper.TeamShield)
 
 			var err error
 
-			wcClient, err = state.GetFramework().WC(state.GetCluster().Name)
+			wcClient, err = env.WC()
 			if err != nil {
 				Fail(err.Error())
 			}
 		})
 
 		It("cert-manager default ClusterIssuers are present and ready", func() {
-			if !certManagerSupported {
-				Skip("cert-manager is not supported in this cluster configuration")
+			if !cfg.CertManagerSupported {
+				skipUnsupported(cfg, CapabilityCertManager, "cert-manager is not supported in this cluster configuration")
 			}
 
-			certManagerTimeout := state.GetTestTimeout(timeout.CertManager, 5*time.Minute)
+			certManagerTimeout := env.Timeout(timeout.CertManager)
 			for _, clusterIssuerName := range clusterIssuers {
-				Eventually(checkClusterIssuer(state.GetContext(), wcClient, clusterIssuerName)).
+				Eventually(checkClusterIssuer(env.Context(), wcClient, clusterIssuerName)).
 					WithTimeout(certManagerTimeout).
 					WithPolling(5 * time.Second).
 					Should(Succeed())
 			}
 		})
+
+		It("issues a certificate from the self-signed ClusterIssuer", func() {
+			dnsNames := []string{fmt.Sprintf("cert-test.%s.svc", certificateNamespace("selfsigned-giantswarm"))}
+			issueCertificate(env, wcClient, "selfsigned-giantswarm", dnsNames, certificateExpectation{SelfSigned: true})
+		})
+
+		It("issues a certificate from the ACME ClusterIssuer", func() {
+			if !cfg.ExternalDnsSupported {
+				skipUnsupported(cfg, CapabilityExternalDns, "external-dns is not supported in this cluster configuration")
+			}
+
+			dnsNames := []string{fmt.Sprintf("cert-test.%s", getWorkloadClusterDnsZone(env))}
+			issueCertificate(env, wcClient, "letsencrypt-giantswarm", dnsNames, certificateExpectation{IssuerOrganization: "Let's Encrypt"})
+		})
 	})
 }
 
+// issueCertificate requests a Certificate for dnsNames from the given ClusterIssuer, waits
+// for it to be issued and validates the certificate stored in its Secret. The Certificate
+// and its Secret are deleted with their namespace once the spec finishes.
+func issueCertificate(env *state.Environment, wcClient *client.Client, clusterIssuerName string, dnsNames []string, expected certificateExpectation) {
+	ctx := env.Context()
+	namespaceName := certificateNamespace(clusterIssuerName)
+
+	// A namespace left over by an earlier attempt of the spec may still be terminating.
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespaceName}}
+	Eventually(wait.IsResourceDeleted(ctx, wcClient, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespaceName}})).
+		WithTimeout(env.Timeout(timeout.NamespaceDeletion)).
+		WithPolling(wait.DefaultInterval).
+		Should(BeTrue())
+
+	logger.Log("Creating Namespace '%s'", namespaceName)
+	err := wcClient.Create(ctx, namespace)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		Expect(err).NotTo(HaveOccurred())
+	}
+	DeferCleanup(func() {
+		logger.Log("Deleting Namespace '%s'", namespaceName)
+		err := wcClient.Delete(context.Background(), namespace)
+		if err != nil && !errors.IsNotFound(err) {
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Eventually(wait.IsResourceDeleted(context.Background(), wcClient, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespaceName}})).
+			WithTimeout(env.Timeout(timeout.NamespaceDeletion)).
+			WithPolling(wait.DefaultInterval).
+			Should(BeTrue())
+	})
+
+	name := fmt.Sprintf("e2e-%s", clusterIssuerName)
+	certificate := &certmanager.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespaceName,
+		},
+		Spec: certmanager.CertificateSpec{
+			SecretName: name,
+			DNSNames:   dnsNames,
+			IssuerRef: cmmeta.IssuerReference{
+				Name: clusterIssuerName,
+				Kind: certmanager.ClusterIssuerKind,
+			},
+		},
+	}
+	logger.Log("Creating Certificate '%s' from ClusterIssuer '%s' for %v", name, clusterIssuerName, dnsNames)
+	err = wcClient.Create(ctx, certificate)
+	if err != nil && !errors.IsAlreadyExists(err) {
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	Eventually(checkCertificateIssued(ctx, wcClient, certificate, clusterIssuerName)).
+		WithTimeout(env.Timeout(timeout.CertificateIssuance)).
+		WithPolling(5 * time.Second).
+		Should(Succeed())
+
+	secret := &corev1.Secret{}
+	err = wcClient.Get(ctx, cr.ObjectKey{Name: certificate.Spec.SecretName, Namespace: namespaceName}, secret)
+	Expect(err).NotTo(HaveOccurred())
+
+	expected.DNSNames = dnsNames
+	Expect(validateCertificate(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey], expected, time.Now())).To(Succeed())
+}
+
+// checkCertificateIssued returns a function that succeeds once the Certificate is Ready.
+// While it isn't, the ClusterIssuer is checked so a missing or broken issuer is reported
+// with the same diagnostics as checkClusterIssuer.
+func checkCertificateIssued(ctx context.Context, wcClient *client.Client, certificate *certmanager.Certificate, clusterIssuerName string) func() error {
+	return func() error {
+		err := wcClient.Get(ctx, cr.ObjectKeyFromObject(certificate), certificate)
+		if err != nil {
+			return err
+		}
+
+		for _, condition := range certificate.Status.Conditions {
+			if condition.Type != certmanager.CertificateConditionReady {
+				continue
+			}
+			if condition.Status == cmmeta.ConditionTrue {
+				logger.Log("Certificate '%s' is Ready", certificate.Name)
+				return nil
+			}
+			logger.Log("Certificate '%s' is not Ready: Reason='%s', Message='%s'", certificate.Name, condition.Reason, condition.Message)
+		}
+
+		logWarningEvents(ctx, wcClient, certificate)
+		if err := checkClusterIssuer(ctx, wcClient, clusterIssuerName)(); err != nil {
+			return fmt.Errorf("certificate '%s' is not issued: %w", certificate.Name, err)
+		}
+		return fmt.Errorf("certificate '%s' is not Ready", certificate.Name)
+	}
+}
+
+// certificateExpectation describes the certificate expected in the Secret of a Certificate.
+type certificateExpectation struct {
+	DNSNames []string
+	// SelfSigned expects the leaf certificate to be signed by its own key.
+	SelfSigned bool
+	// IssuerOrganization, if set, must be part of one of the issuer organizations of the
+	// leaf certificate, e.g. "Let's Encrypt" also matches "(STAGING) Let's Encrypt".
+	IssuerOrganization string
+}
+
+// validateCertificate parses the PEM encoded certificate chain and key of a TLS Secret and
+// checks that the leaf certificate matches the key, is valid at now and matches expected.
+func validateCertificate(certPEM, keyPEM []byte, expected certificateExpectation, now time.Time) error {
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return fmt.Errorf("certificate doesn't match its private key: %w", err)
+	}
+
+	chain := []*x509.Certificate{}
+	for rest := certPEM; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return fmt.Errorf("no certificate found")
+	}
+	leaf := chain[0]
+
+	for _, dnsName := range expected.DNSNames {
+		if !slices.Contains(leaf.DNSNames, dnsName) {
+			return fmt.Errorf("certificate SANs %v don't include %s", leaf.DNSNames, dnsName)
+		}
+	}
+
+	if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+		return fmt.Errorf("certificate is only valid from %s to %s", leaf.NotBefore, leaf.NotAfter)
+	}
+
+	if expected.SelfSigned {
+		// CheckSignatureFrom would require the leaf to be a CA, which self-signed leaf
+		// certificates issued by cert-manager aren't.
+		if leaf.Issuer.String() != leaf.Subject.String() {
+			return fmt.Errorf("certificate is issued by %q, expected it to be self-signed", leaf.Issuer)
+		}
+		if err := leaf.CheckSignature(leaf.SignatureAlgorithm, leaf.RawTBSCertificate, leaf.Signature); err != nil {
+			return fmt.Errorf("certificate is not self-signed: %w", err)
+		}
+	} else if len(chain) > 1 {
+		if err := leaf.CheckSignatureFrom(chain[1]); err != nil {
+			return fmt.Errorf("certificate is not signed by the next certificate of the chain: %w", err)
+		}
+	}
+
+	if expected.IssuerOrganization != "" {
+		found := false
+		for _, organization := range leaf.Issuer.Organization {
+			if strings.Contains(organization, expected.IssuerOrganization) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("certificate issuer %q is not %s", leaf.Issuer, expected.IssuerOrganization)
+		}
+	}
+
+	logger.Log("Certificate for %v issued by %q is valid until %s", leaf.DNSNames, leaf.Issuer, leaf.NotAfter)
+	return nil
+}
+
 func checkClusterIssuer(ctx context.Context, wcClient *client.Client, clusterIssuerName string) func() error {
 	return func() error {
 		logger.Log("Checking ClusterIssuer '%s'", clusterIssuerName)
@@ -72,32 +276,8 @@ func checkClusterIssuer(ctx context.Context, wcClient *client.Client, clusterIss
 		if err != nil {
 			if errors.IsNotFound(err) {
 				// Cluster Issuer was not found so we'll check the status of the Job that creates it
-				var nestedErr error
 				logger.Log("ClusterIssuer '%s' is not yet found", clusterIssuerName)
-
-				// Get status of cluster issuer post-install Job
-				clusterIssuerJob := &batchv1.Job{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "cert-manager-giantswarm-clusterissuer",
-						Namespace: "kube-system",
-					},
-				}
-				nestedErr = wcClient.Get(ctx, cr.ObjectKeyFromObject(clusterIssuerJob), clusterIssuerJob)
-				if nestedErr != nil {
-					logger.Log("Failed to get cluster issuer Job, it may have already completed: %v", nestedErr)
-				} else {
-					logger.Log("Status of cluster issuer Job '%s': Succeeded:%t", clusterIssuerJob.Name, clusterIssuerJob.Status.Succeeded > 0)
-				}
-
-				// Get events related to the cluster issuer post-install Job
-				events, nestedErr := wcClient.GetWarningEventsForResource(ctx, clusterIssuerJob)
-				if nestedErr != nil {
-					logger.Log("Failed to get events for cluster issuer Job: %v", nestedErr)
-				} else {
-					for _, event := range events.Items {
-						logger.Log("Event: Reason='%s', Message='%s', Last Occurred='%v'", event.Reason, event.Message, event.LastTimestamp)
-					}
-				}
+				logClusterIssuerJobDiagnostics(ctx, wcClient)
 			}
 
 			return err
@@ -115,3 +295,33 @@ func checkClusterIssuer(ctx context.Context, wcClient *client.Client, clusterIss
 		return fmt.Errorf("ClusterIssuer '%s' is not Ready", clusterIssuerName)
 	}
 }
+
+// logClusterIssuerJobDiagnostics logs the status and warning events of the post-install Job
+// creating the default ClusterIssuers.
+func logClusterIssuerJobDiagnostics(ctx context.Context, wcClient *client.Client) {
+	clusterIssuerJob := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cert-manager-giantswarm-clusterissuer",
+			Namespace: "kube-system",
+		},
+	}
+	err := wcClient.Get(ctx, cr.ObjectKeyFromObject(clusterIssuerJob), clusterIssuerJob)
+	if err != nil {
+		logger.Log("Failed to get cluster issuer Job, it may have already completed: %v", err)
+	} else {
+		logger.Log("Status of cluster issuer Job '%s': Succeeded:%t", clusterIssuerJob.Name, clusterIssuerJob.Status.Succeeded > 0)
+	}
+
+	logWarningEvents(ctx, wcClient, clusterIssuerJob)
+}
+
+func logWarningEvents(ctx context.Context, wcClient *client.Client, obj cr.Object) {
+	events, err := wcClient.GetWarningEventsForResource(ctx, obj)
+	if err != nil {
+		logger.Log("Failed to get events for %T '%s': %v", obj, obj.GetName(), err)
+		return
+	}
+	for _, event := range events.Items {
+		logger.Log("Event: Reason='%s', Message='%s', Last Occurred='%v'", event.Reason, event.Message, event.LastTimestamp)
+	}
+}
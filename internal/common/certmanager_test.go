@@ -0,0 +1,189 @@
+package common
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/testenv"
+)
+
+func TestCheckClusterIssuer(t *testing.T) {
+	const issuerName = "letsencrypt-giantswarm"
+
+	testCases := []struct {
+		name           string
+		objects        []cr.Object
+		expectErr      bool
+		expectNotFound bool
+	}{
+		{
+			name:    "issuer ready",
+			objects: []cr.Object{testenv.ClusterIssuer(issuerName, true)},
+		},
+		{
+			name:      "issuer not ready",
+			objects:   []cr.Object{testenv.ClusterIssuer(issuerName, false)},
+			expectErr: true,
+		},
+		{
+			name:           "issuer missing",
+			objects:        []cr.Object{testenv.ClusterIssuer("selfsigned-giantswarm", true)},
+			expectErr:      true,
+			expectNotFound: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			wcClient := testenv.NewClient(tc.objects...)
+
+			err := checkClusterIssuer(context.Background(), wcClient, issuerName)()
+			if tc.expectErr && err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if tc.expectNotFound && !apierrors.IsNotFound(err) {
+				t.Fatalf("expected a NotFound error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateCertificate(t *testing.T) {
+	now := time.Now()
+
+	caKey, caCert, caPEM := newTestCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"(STAGING) Let's Encrypt"}, CommonName: "test CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}, nil, nil)
+
+	leaf := func(dnsNames []string, notAfter time.Time, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) ([]byte, []byte) {
+		key, _, certPEM := newTestCertificate(t, &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: dnsNames[0]},
+			DNSNames:     dnsNames,
+			NotBefore:    now.Add(-time.Hour),
+			NotAfter:     notAfter,
+		}, parent, parentKey)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return certPEM, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	}
+
+	selfSignedCert, selfSignedKey := leaf([]string{"cert-test.example.com"}, now.Add(time.Hour), nil, nil)
+	expiredCert, expiredKey := leaf([]string{"cert-test.example.com"}, now.Add(-time.Minute), nil, nil)
+	issuedCert, issuedKey := leaf([]string{"cert-test.example.com"}, now.Add(time.Hour), caCert, caKey)
+	issuedChain := append(append([]byte{}, issuedCert...), caPEM...)
+
+	testCases := []struct {
+		name      string
+		certPEM   []byte
+		keyPEM    []byte
+		expected  certificateExpectation
+		expectErr bool
+	}{
+		{
+			name:     "self-signed",
+			certPEM:  selfSignedCert,
+			keyPEM:   selfSignedKey,
+			expected: certificateExpectation{DNSNames: []string{"cert-test.example.com"}, SelfSigned: true},
+		},
+		{
+			name:      "missing SAN",
+			certPEM:   selfSignedCert,
+			keyPEM:    selfSignedKey,
+			expected:  certificateExpectation{DNSNames: []string{"other.example.com"}, SelfSigned: true},
+			expectErr: true,
+		},
+		{
+			name:      "expired",
+			certPEM:   expiredCert,
+			keyPEM:    expiredKey,
+			expected:  certificateExpectation{DNSNames: []string{"cert-test.example.com"}, SelfSigned: true},
+			expectErr: true,
+		},
+		{
+			name:      "key of another certificate",
+			certPEM:   selfSignedCert,
+			keyPEM:    issuedKey,
+			expected:  certificateExpectation{DNSNames: []string{"cert-test.example.com"}, SelfSigned: true},
+			expectErr: true,
+		},
+		{
+			name:     "issued by the expected organization",
+			certPEM:  issuedChain,
+			keyPEM:   issuedKey,
+			expected: certificateExpectation{DNSNames: []string{"cert-test.example.com"}, IssuerOrganization: "Let's Encrypt"},
+		},
+		{
+			name:      "expected self-signed but issued by a CA",
+			certPEM:   issuedChain,
+			keyPEM:    issuedKey,
+			expected:  certificateExpectation{DNSNames: []string{"cert-test.example.com"}, SelfSigned: true},
+			expectErr: true,
+		},
+		{
+			name:      "issued by another organization",
+			certPEM:   selfSignedCert,
+			keyPEM:    selfSignedKey,
+			expected:  certificateExpectation{DNSNames: []string{"cert-test.example.com"}, IssuerOrganization: "Let's Encrypt"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateCertificate(tc.certPEM, tc.keyPEM, tc.expected, now)
+			if tc.expectErr && err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+			if !tc.expectErr && err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+// newTestCertificate creates a certificate from template, signed by parent or self-signed
+// if parent is nil, and returns its key, parsed certificate and PEM encoding.
+func newTestCertificate(t *testing.T, template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, *x509.Certificate, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -1,5 +1,32 @@
 package common
 
+import (
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+)
+
+// Capability identifies an optional feature that a suite can enable or disable
+// via its suite manifest (see manifest.go).
+type Capability string
+
+const (
+	CapabilityAutoScaling         Capability = "autoScaling"
+	CapabilityBastion             Capability = "bastion"
+	CapabilityTeleport            Capability = "teleport"
+	CapabilityExternalDns         Capability = "externalDns"
+	CapabilityCertManager         Capability = "certManager"
+	CapabilityControlPlaneMetrics Capability = "controlPlaneMetrics"
+	CapabilityObservabilityBundle Capability = "observabilityBundle"
+	CapabilitySecurityBundle      Capability = "securityBundle"
+	CapabilityGatewayAPI          Capability = "gatewayAPI"
+	CapabilityARMNodePool         Capability = "armNodePool"
+	CapabilityNetworkPolicy       Capability = "networkPolicy"
+	CapabilityConnectivity        Capability = "connectivity"
+	CapabilityStorage             Capability = "storage"
+	CapabilityAPIDns              Capability = "apiDns"
+)
+
 type TestConfig struct {
 	AutoScalingSupported         bool
 	BastionSupported             bool
@@ -11,6 +38,22 @@ type TestConfig struct {
 	SecurityBundleInstalled      bool
 	GatewayAPISupported          bool
 	ARMNodePoolEnabled           bool
+	NetworkPolicySupported       bool
+	ConnectivitySupported        bool
+	StorageSupported             bool
+	APIDnsSupported              bool
+
+	// Storage declares the StorageClasses exercised by the storage matrix.
+	Storage StorageConfig
+	// PodSecurity declares the Pod Security Admission levels expected on the namespaces.
+	PodSecurity PodSecurityConfig
+	// RBAC declares the expected cluster-admin bindings and default ServiceAccounts.
+	RBAC RBACConfig
+
+	// SkipReasons holds the reason a capability is disabled, keyed by capability.
+	// Populated from the suite manifest and used as the Skip message, so the reason
+	// shows up in the Ginkgo report.
+	SkipReasons map[Capability]string
 }
 
 func NewTestConfigWithDefaults() *TestConfig {
@@ -25,17 +68,73 @@ func NewTestConfigWithDefaults() *TestConfig {
 		SecurityBundleInstalled:      true,
 		GatewayAPISupported:          true,
 		ARMNodePoolEnabled:           false,
+		NetworkPolicySupported:       true,
+		ConnectivitySupported:        true,
+		StorageSupported:             true,
+		APIDnsSupported:              true,
+	}
+}
+
+// SetCapability enables or disables the TestConfig field backing the given capability.
+// Returns false if the capability is unknown.
+func (cfg *TestConfig) SetCapability(capability Capability, enabled bool) bool {
+	switch capability {
+	case CapabilityAutoScaling:
+		cfg.AutoScalingSupported = enabled
+	case CapabilityBastion:
+		cfg.BastionSupported = enabled
+	case CapabilityTeleport:
+		cfg.TeleportSupported = enabled
+	case CapabilityExternalDns:
+		cfg.ExternalDnsSupported = enabled
+	case CapabilityCertManager:
+		cfg.CertManagerSupported = enabled
+	case CapabilityControlPlaneMetrics:
+		cfg.ControlPlaneMetricsSupported = enabled
+	case CapabilityObservabilityBundle:
+		cfg.ObservabilityBundleInstalled = enabled
+	case CapabilitySecurityBundle:
+		cfg.SecurityBundleInstalled = enabled
+	case CapabilityGatewayAPI:
+		cfg.GatewayAPISupported = enabled
+	case CapabilityARMNodePool:
+		cfg.ARMNodePoolEnabled = enabled
+	case CapabilityNetworkPolicy:
+		cfg.NetworkPolicySupported = enabled
+	case CapabilityConnectivity:
+		cfg.ConnectivitySupported = enabled
+	case CapabilityStorage:
+		cfg.StorageSupported = enabled
+	case CapabilityAPIDns:
+		cfg.APIDnsSupported = enabled
+	default:
+		return false
+	}
+	return true
+}
+
+// skipUnsupported skips the current spec because the given capability is disabled.
+// The reason declared in the suite manifest is preferred over the fallback message.
+func skipUnsupported(cfg *TestConfig, capability Capability, fallback string) {
+	if reason, ok := cfg.SkipReasons[capability]; ok && reason != "" {
+		Skip(reason)
 	}
+	Skip(fallback)
 }
 
-func Run(cfg *TestConfig) {
-	RunApps(cfg)
-	runBasic(cfg)
-	runCertManager(cfg.CertManagerSupported)
-	runDNS(cfg.BastionSupported)
-	runMetrics(cfg)
-	runTeleport(cfg.TeleportSupported)
-	runHelloWorldGateway(cfg.GatewayAPISupported)
-	runScale(cfg.AutoScalingSupported)
-	runStorage()
+func Run(env *state.Environment, cfg *TestConfig) {
+	RunApps(env, cfg)
+	runBasic(env, cfg)
+	runCertManager(env, cfg)
+	runDNS(env, cfg)
+	runMetrics(env, cfg)
+	runTeleport(env, cfg)
+	runHelloWorldGateway(env, cfg)
+	runScale(env, cfg)
+	runStorageMatrix(env, cfg)
+	runConnectivity(env, cfg)
+	runNetworkPolicy(env, cfg)
+	runPolicyEnforcement(env, cfg)
+	runPodSecurity(env, cfg)
+	runRBAC(env, cfg)
 }
@@ -0,0 +1,309 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+	"github.com/giantswarm/clustertest/v5/pkg/wait"
+	corev1 "k8s.io/api/core/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+	. "github.com/onsi/gomega"    //nolint:staticcheck
+)
+
+const (
+	connectivityNamespace       = "test-connectivity"
+	connectivityClusterIP       = "probe"
+	connectivityNodePort        = "probe-nodeport"
+	connectivityHeadless        = "probe-headless"
+	connectivityMatrixEntryName = "CONNECTIVITY_MATRIX"
+)
+
+// runConnectivity checks the data plane between the nodes of the cluster. A probe pod runs
+// on every node and calls every other probe pod, a ClusterIP, a NodePort and a headless
+// Service. The results are reported as a matrix of source node to target. The in-cluster
+// DNS tests then resolve names from the same probe pods.
+func runConnectivity(env *state.Environment, cfg *TestConfig) {
+	Context("connectivity", Ordered, func() {
+		var (
+			wcClient *client.Client
+			pods     []corev1.Pod
+		)
+
+		BeforeAll(func() {
+			if !cfg.ConnectivitySupported {
+				skipUnsupported(cfg, CapabilityConnectivity, "The data plane can't be probed in this cluster configuration")
+			}
+		})
+
+		BeforeEach(func() {
+			helper.SetResponsibleTeam(helper.TeamPhoenix)
+
+			var err error
+
+			wcClient, err = env.WC()
+			if err != nil {
+				Fail(err.Error())
+			}
+		})
+
+		It("runs a probe pod on every node", func() {
+			Eventually(func() error {
+				if err := ensureProbe(env.Context(), wcClient, connectivityNamespace); err != nil {
+					return err
+				}
+				for _, svc := range []*corev1.Service{
+					probeService(connectivityClusterIP, connectivityNamespace, corev1.ServiceTypeClusterIP, false),
+					probeService(connectivityNodePort, connectivityNamespace, corev1.ServiceTypeNodePort, false),
+					probeService(connectivityHeadless, connectivityNamespace, corev1.ServiceTypeClusterIP, true),
+				} {
+					err := wcClient.Create(env.Context(), svc)
+					if err != nil && !apierror.IsAlreadyExists(err) {
+						logger.Log("Failed to create Service '%s' - %v", svc.Name, err)
+						return err
+					}
+				}
+				return nil
+			}).
+				WithTimeout(env.Timeout(timeout.ResourceApply)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+
+			Eventually(checkProbeReady(env.Context(), wcClient, connectivityNamespace)).
+				WithTimeout(env.Timeout(timeout.ProbeReady)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+
+			var err error
+			pods, err = listProbePods(env.Context(), wcClient, connectivityNamespace)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reaches the probe pods on the other nodes", func() {
+			targets := []connectivityTarget{}
+			for _, pod := range pods {
+				targets = append(targets, connectivityTarget{
+					Name: pod.Spec.NodeName,
+					URL:  probeURL(pod.Status.PodIP, probePort),
+					node: pod.Spec.NodeName,
+				})
+			}
+			expectConnectivityMatrix(env, wcClient, "pod-to-pod", pods, targets)
+		})
+
+		It("reaches the ClusterIP Service", func() {
+			svc := &corev1.Service{}
+			err := wcClient.Get(env.Context(), cr.ObjectKey{Name: connectivityClusterIP, Namespace: connectivityNamespace}, svc)
+			Expect(err).NotTo(HaveOccurred())
+
+			targets := []connectivityTarget{{
+				Name: "ClusterIP",
+				URL:  probeURL(svc.Spec.ClusterIP, probePort),
+			}}
+			expectConnectivityMatrix(env, wcClient, "pod-to-ClusterIP", pods, targets)
+		})
+
+		It("reaches the NodePort Service on every node", func() {
+			svc := &corev1.Service{}
+			err := wcClient.Get(env.Context(), cr.ObjectKey{Name: connectivityNodePort, Namespace: connectivityNamespace}, svc)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(svc.Spec.Ports).NotTo(BeEmpty())
+
+			nodes := &corev1.NodeList{}
+			Expect(wcClient.List(env.Context(), nodes)).To(Succeed())
+
+			targets := []connectivityTarget{}
+			for _, node := range nodes.Items {
+				ip := nodeInternalIP(node)
+				if ip == "" {
+					continue
+				}
+				targets = append(targets, connectivityTarget{
+					Name: node.Name,
+					URL:  probeURL(ip, svc.Spec.Ports[0].NodePort),
+				})
+			}
+			Expect(targets).NotTo(BeEmpty(), "no node has an InternalIP address")
+			sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+
+			expectConnectivityMatrix(env, wcClient, "pod-to-NodePort", pods, targets)
+		})
+
+		It("reaches the headless Service", func() {
+			targets := []connectivityTarget{{
+				Name: "headless",
+				URL:  probeURL(fmt.Sprintf("%s.%s.svc.cluster.local", connectivityHeadless, connectivityNamespace), probePort),
+			}}
+			expectConnectivityMatrix(env, wcClient, "pod-to-headless", pods, targets)
+		})
+
+		runInClusterDNS(env, cfg, func() []corev1.Pod { return pods })
+
+		AfterAll(func() {
+			if !cfg.ConnectivitySupported {
+				return
+			}
+
+			wc, err := env.WC()
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func() error {
+				logger.Log("Deleting Namespace '%s'", connectivityNamespace)
+				err := wc.Delete(env.Context(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: connectivityNamespace}})
+				if err != nil && !apierror.IsNotFound(err) {
+					logger.Log("Failed to delete Namespace '%s'", connectivityNamespace)
+					return err
+				}
+				return nil
+			}).
+				WithTimeout(env.Timeout(timeout.ResourceApply)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+
+			Eventually(wait.IsResourceDeleted(env.Context(), wc, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: connectivityNamespace}})).
+				WithTimeout(env.Timeout(timeout.NamespaceDeletion)).
+				WithPolling(wait.DefaultInterval).
+				Should(BeTrue())
+		})
+	})
+}
+
+// connectivityTarget is a column of a connectivityMatrix.
+type connectivityTarget struct {
+	Name string
+	URL  string
+
+	// node is set when the target runs on a node, so the probe pod on the same node skips it.
+	node string
+}
+
+// connectivityMatrix holds the result of calling each target from each source node.
+// A missing result means the source skipped the target.
+type connectivityMatrix struct {
+	Name    string
+	Sources []string
+	Targets []string
+	Results map[string]map[string]error
+}
+
+func newConnectivityMatrix(name string, sources []string, targets []connectivityTarget) *connectivityMatrix {
+	m := &connectivityMatrix{Name: name, Sources: sources, Results: map[string]map[string]error{}}
+	for _, target := range targets {
+		m.Targets = append(m.Targets, target.Name)
+	}
+	for _, source := range sources {
+		m.Results[source] = map[string]error{}
+	}
+	return m
+}
+
+func (m *connectivityMatrix) set(source, target string, err error) {
+	m.Results[source][target] = err
+}
+
+// Failures lists the failed source to target calls.
+func (m *connectivityMatrix) Failures() []string {
+	failures := []string{}
+	for _, source := range m.Sources {
+		for _, target := range m.Targets {
+			if err, ok := m.Results[source][target]; ok && err != nil {
+				failures = append(failures, fmt.Sprintf("%s -> %s: %v", source, target, err))
+			}
+		}
+	}
+	return failures
+}
+
+// String renders the matrix as a table with a row per source and a column per target.
+func (m *connectivityMatrix) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%s\n", m.Name)
+
+	w := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "source \\ target\t%s\n", strings.Join(m.Targets, "\t"))
+	for _, source := range m.Sources {
+		cells := []string{}
+		for _, target := range m.Targets {
+			err, ok := m.Results[source][target]
+			switch {
+			case !ok:
+				cells = append(cells, "-")
+			case err != nil:
+				cells = append(cells, "FAIL")
+			default:
+				cells = append(cells, "ok")
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\n", source, strings.Join(cells, "\t"))
+	}
+	w.Flush() // nolint:errcheck
+	return b.String()
+}
+
+// expectConnectivityMatrix calls every target from every probe pod until all calls succeed
+// and reports the last matrix.
+func expectConnectivityMatrix(env *state.Environment, wcClient *client.Client, name string, pods []corev1.Pod, targets []connectivityTarget) {
+	Expect(pods).NotTo(BeEmpty(), "no probe pods to test from")
+
+	var matrix *connectivityMatrix
+	Eventually(func() error {
+		matrix = probeConnectivityMatrix(env.Context(), wcClient, name, pods, targets)
+		logger.Log("Connectivity matrix:\n%s", matrix)
+		if failures := matrix.Failures(); len(failures) > 0 {
+			return fmt.Errorf("%d of the %s calls failed:\n%s", len(failures), name, strings.Join(failures, "\n"))
+		}
+		return nil
+	}).
+		WithTimeout(env.Timeout(timeout.Connectivity)).
+		WithPolling(10 * time.Second).
+		Should(Succeed())
+
+	AddReportEntry(connectivityMatrixEntryName, matrix.String())
+}
+
+func probeConnectivityMatrix(ctx context.Context, wcClient *client.Client, name string, pods []corev1.Pod, targets []connectivityTarget) *connectivityMatrix {
+	sources := []string{}
+	for _, pod := range pods {
+		sources = append(sources, pod.Spec.NodeName)
+	}
+
+	matrix := newConnectivityMatrix(name, sources, targets)
+	for _, pod := range pods {
+		for _, target := range targets {
+			if target.node != "" && target.node == pod.Spec.NodeName {
+				continue
+			}
+			matrix.set(pod.Spec.NodeName, target.Name, probeGet(ctx, wcClient, pod, target.URL))
+		}
+	}
+	return matrix
+}
+
+func nodeInternalIP(node corev1.Node) string {
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			return address.Address
+		}
+	}
+	return ""
+}
+
+// probeURL returns the URL of the probe served at host and port, bracketing IPv6 addresses.
+func probeURL(host string, port int32) string {
+	return fmt.Sprintf("http://%s", net.JoinHostPort(host, strconv.Itoa(int(port))))
+}
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestConnectivityMatrix(t *testing.T) {
+	matrix := newConnectivityMatrix("pod-to-pod", []string{"node-a", "node-b"}, []connectivityTarget{
+		{Name: "node-a", node: "node-a"},
+		{Name: "node-b", node: "node-b"},
+	})
+	matrix.set("node-a", "node-b", nil)
+	matrix.set("node-b", "node-a", errors.New("timed out"))
+
+	failures := matrix.Failures()
+	if len(failures) != 1 || failures[0] != "node-b -> node-a: timed out" {
+		t.Errorf("unexpected failures: %v", failures)
+	}
+
+	lines := strings.Split(strings.TrimSpace(matrix.String()), "\n")
+	expected := []string{
+		"pod-to-pod",
+		"source \\ target  node-a  node-b",
+		"node-a           -       ok",
+		"node-b           FAIL    -",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected matrix:\n%s", matrix)
+	}
+}
+
+func TestProbeURL(t *testing.T) {
+	if url := probeURL("10.0.0.1", 8080); url != "http://10.0.0.1:8080" {
+		t.Errorf("unexpected IPv4 URL %s", url)
+	}
+	if url := probeURL("fd00::1", 30080); url != "http://[fd00::1]:30080" {
+		t.Errorf("unexpected IPv6 URL %s", url)
+	}
+}
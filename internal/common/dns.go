@@ -4,19 +4,38 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/giantswarm/clustertest/v5/pkg/application"
+	"github.com/giantswarm/clustertest/v5/pkg/client"
 	"github.com/giantswarm/clustertest/v5/pkg/logger"
 	clustertestnet "github.com/giantswarm/clustertest/v5/pkg/net"
 	"github.com/giantswarm/clustertest/v5/pkg/wait"
 	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
 	. "github.com/onsi/gomega"    //nolint:staticcheck
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
 	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
 )
 
-func runDNS(bastionSuppoted bool) {
+const (
+	dnsExternalName = "gsoci.azurecr.io"
+	// dnsNewService is created by the in-cluster DNS tests to check that the DNS record of a
+	// new Service shows up.
+	dnsNewService = "dns-new-service"
+
+	defaultNodeLocalDNSIP = "169.254.20.10"
+)
+
+func runDNS(env *state.Environment, cfg *TestConfig) {
 	Context("dns", func() {
 		var (
 			resolver *net.Resolver
@@ -34,16 +53,7 @@ func runDNS(bastionSuppoted bool) {
 		}
 
 		BeforeEach(func() {
-			values = &application.ClusterValues{}
-			// Reading the cluster Helm values hits the MC API and can transiently
-			// fail; retry so a blip doesn't fail the spec.
-			Eventually(func() error {
-				return state.GetFramework().MC().GetHelmValues(state.GetCluster().Name, state.GetCluster().GetNamespace(), values)
-			}).
-				WithTimeout(1 * time.Minute).
-				WithPolling(5 * time.Second).
-				Should(Succeed())
-
+			values = getClusterValues(env)
 			resolver = clustertestnet.NewResolver()
 		})
 
@@ -51,7 +61,10 @@ func runDNS(bastionSuppoted bool) {
 		// propagation that is inherently transient, so retry the spec a few
 		// times before failing.
 		It("sets up the api DNS records", FlakeAttempts(3), func() {
-			apiDomain := fmt.Sprintf("api.%s.%s", state.GetCluster().Name, values.BaseDomain)
+			if !cfg.APIDnsSupported {
+				skipUnsupported(cfg, CapabilityAPIDns, "The api DNS records are not published.")
+			}
+			apiDomain := fmt.Sprintf("api.%s.%s", env.Cluster().Name, values.BaseDomain)
 			var records []net.IP
 			Eventually(func() error {
 				var err error
@@ -65,10 +78,10 @@ func runDNS(bastionSuppoted bool) {
 		})
 
 		It("sets up the bastion DNS records", FlakeAttempts(3), func() {
-			if !bastionSuppoted {
-				Skip("Bastion is not supported.")
+			if !cfg.BastionSupported {
+				skipUnsupported(cfg, CapabilityBastion, "Bastion is not supported.")
 			}
-			bastionDomain := fmt.Sprintf("bastion1.%s.%s", state.GetCluster().Name, values.BaseDomain)
+			bastionDomain := fmt.Sprintf("bastion1.%s.%s", env.Cluster().Name, values.BaseDomain)
 			var records []net.IP
 			Eventually(func() error {
 				var err error
@@ -82,3 +95,198 @@ func runDNS(bastionSuppoted bool) {
 		})
 	})
 }
+
+// runInClusterDNS registers the in-cluster DNS tests. They resolve names through CoreDNS from
+// the probe pods of the connectivity tests, so they must be registered in their Ordered
+// container after the probe pods are ready.
+func runInClusterDNS(env *state.Environment, cfg *TestConfig, probePods func() []corev1.Pod) {
+	Context("in-cluster dns", func() {
+		var wcClient *client.Client
+
+		// resolveFromProbes resolves domain through CoreDNS from every probe pod, optionally
+		// querying the given nameserver instead of the one in the pod's resolv.conf.
+		resolveFromProbes := func(domain, nameserver string, expected ...string) {
+			for _, pod := range probePods() {
+				Eventually(checkInClusterResolution(env.Context(), wcClient, pod, domain, nameserver, expected...)).
+					WithTimeout(env.Timeout(timeout.InClusterDNS)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			}
+		}
+
+		BeforeEach(func() {
+			helper.SetResponsibleTeam(helper.TeamCabbage)
+
+			var err error
+
+			wcClient, err = env.WC()
+			if err != nil {
+				Fail(err.Error())
+			}
+		})
+
+		It("resolves the kubernetes Service", func() {
+			svc := &corev1.Service{}
+			err := wcClient.Get(env.Context(), cr.ObjectKey{Name: "kubernetes", Namespace: "default"}, svc)
+			Expect(err).NotTo(HaveOccurred())
+
+			resolveFromProbes("kubernetes.default.svc.cluster.local", "", svc.Spec.ClusterIP)
+		})
+
+		It("resolves a newly created Service", func() {
+			svc := probeService(dnsNewService, connectivityNamespace, corev1.ServiceTypeClusterIP, false)
+			Eventually(func() error {
+				err := wcClient.Create(env.Context(), svc)
+				if err != nil && !apierror.IsAlreadyExists(err) {
+					logger.Log("Failed to create Service '%s' - %v", svc.Name, err)
+					return err
+				}
+				return nil
+			}).
+				WithTimeout(env.Timeout(timeout.ResourceApply)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+
+			wc := wcClient
+			DeferCleanup(func() {
+				logger.Log("Deleting Service '%s'", dnsNewService)
+				err := wc.Delete(env.Context(), &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: dnsNewService, Namespace: connectivityNamespace}})
+				if err != nil && !apierror.IsNotFound(err) {
+					Expect(err).NotTo(HaveOccurred())
+				}
+			})
+
+			Expect(wcClient.Get(env.Context(), cr.ObjectKey{Name: dnsNewService, Namespace: connectivityNamespace}, svc)).To(Succeed())
+			resolveFromProbes(fmt.Sprintf("%s.%s.svc.cluster.local", dnsNewService, connectivityNamespace), "", svc.Spec.ClusterIP)
+		})
+
+		It("resolves external names", func() {
+			resolveFromProbes(dnsExternalName, "")
+		})
+
+		It("resolves the cluster API domain", func() {
+			if !cfg.APIDnsSupported {
+				skipUnsupported(cfg, CapabilityAPIDns, "The api DNS records are not published.")
+			}
+			values := getClusterValues(env)
+			resolveFromProbes(fmt.Sprintf("api.%s.%s", env.Cluster().Name, values.BaseDomain), "")
+		})
+
+		It("answers from the NodeLocal DNS cache on every node", func() {
+			ds, err := findNodeLocalDNS(env.Context(), wcClient)
+			Expect(err).NotTo(HaveOccurred())
+			if ds == nil {
+				Skip("NodeLocal DNS cache is not deployed in this cluster")
+			}
+
+			localIP := nodeLocalDNSIP(ds)
+			logger.Log("NodeLocal DNS cache '%s' listens on %s", ds.Name, localIP)
+			Expect(ds.Status.NumberReady).To(Equal(ds.Status.DesiredNumberScheduled), "not every NodeLocal DNS cache pod is ready")
+
+			resolveFromProbes("kubernetes.default.svc.cluster.local", localIP)
+			resolveFromProbes(dnsExternalName, localIP)
+		})
+	})
+}
+
+// getClusterValues reads the Helm values of the cluster App.
+func getClusterValues(env *state.Environment) *application.ClusterValues {
+	values := &application.ClusterValues{}
+	// Reading the cluster Helm values hits the MC API and can transiently
+	// fail; retry so a blip doesn't fail the spec.
+	Eventually(func() error {
+		return env.MC().GetHelmValues(env.Cluster().Name, env.Cluster().GetNamespace(), values)
+	}).
+		WithTimeout(1 * time.Minute).
+		WithPolling(5 * time.Second).
+		Should(Succeed())
+	return values
+}
+
+// checkInClusterResolution returns a function that resolves domain from the probe pod and
+// succeeds if it resolves to at least one address, including all of the expected ones.
+func checkInClusterResolution(ctx context.Context, wcClient *client.Client, pod corev1.Pod, domain, nameserver string, expected ...string) func() error {
+	return func() error {
+		cmd := []string{"nslookup", domain}
+		if nameserver != "" {
+			cmd = append(cmd, nameserver)
+		}
+		stdout, stderr, err := wcClient.ExecInPod(ctx, pod.Name, pod.Namespace, probeContainer, cmd)
+		if err != nil {
+			logger.Log("domain %s still not resolvable from node %s", domain, pod.Spec.NodeName)
+			return fmt.Errorf("can't resolve %s from pod %s: %s (stderr: %q)", domain, pod.Name, err, stderr)
+		}
+
+		addresses := parseNslookupAddresses(stdout)
+		if len(addresses) == 0 {
+			return fmt.Errorf("%s resolved to no addresses from pod %s (output: %q)", domain, pod.Name, stdout)
+		}
+		for _, address := range expected {
+			if !slices.Contains(addresses, address) {
+				return fmt.Errorf("%s resolved to %v from pod %s, expected %s", domain, addresses, pod.Name, address)
+			}
+		}
+
+		logger.Log("resolved domain %s to %v from node %s", domain, addresses, pod.Spec.NodeName)
+		return nil
+	}
+}
+
+// parseNslookupAddresses returns the addresses of the answers in the output of busybox
+// nslookup, skipping the address of the server that was queried.
+func parseNslookupAddresses(output string) []string {
+	addresses := []string{}
+	inAnswer := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Name:"):
+			inAnswer = true
+		case inAnswer && strings.HasPrefix(line, "Address"):
+			_, address, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
+			}
+			address = strings.TrimSpace(address)
+			// Older busybox versions print "Address 1: <ip> <name>".
+			if fields := strings.Fields(address); len(fields) > 0 {
+				address = fields[0]
+			}
+			if !slices.Contains(addresses, address) {
+				addresses = append(addresses, address)
+			}
+		}
+	}
+	return addresses
+}
+
+// findNodeLocalDNS returns the NodeLocal DNS cache DaemonSet, or nil if it isn't deployed.
+func findNodeLocalDNS(ctx context.Context, wcClient *client.Client) (*appsv1.DaemonSet, error) {
+	daemonSets := &appsv1.DaemonSetList{}
+	err := wcClient.List(ctx, daemonSets, cr.InNamespace("kube-system"), cr.MatchingLabels{"k8s-app": "node-local-dns"})
+	if err != nil {
+		return nil, err
+	}
+	if len(daemonSets.Items) == 0 {
+		return nil, nil
+	}
+	return &daemonSets.Items[0], nil
+}
+
+// nodeLocalDNSIP returns the first address the NodeLocal DNS cache listens on, as passed
+// with its -localip argument.
+func nodeLocalDNSIP(ds *appsv1.DaemonSet) string {
+	for _, container := range ds.Spec.Template.Spec.Containers {
+		args := append(append([]string{}, container.Command...), container.Args...)
+		for i, arg := range args {
+			value, ok := strings.CutPrefix(arg, "-localip=")
+			if !ok && arg == "-localip" && i+1 < len(args) {
+				value, ok = args[i+1], true
+			}
+			if ok {
+				return strings.Split(value, ",")[0]
+			}
+		}
+	}
+	return defaultNodeLocalDNSIP
+}
@@ -0,0 +1,99 @@
+package common
+
+import (
+	"fmt"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseNslookupAddresses(t *testing.T) {
+	testCases := []struct {
+		name     string
+		output   string
+		expected []string
+	}{
+		{
+			name: "service",
+			output: `Server:		172.31.0.10
+Address:	172.31.0.10:53
+
+
+Name:	kubernetes.default.svc.cluster.local
+Address: 172.31.0.1
+`,
+			expected: []string{"172.31.0.1"},
+		},
+		{
+			name: "external name with IPv4 and IPv6 answers",
+			output: `Server:		172.31.0.10
+Address:	172.31.0.10:53
+
+Non-authoritative answer:
+gsoci.azurecr.io	canonical name = gsoci.privatelink.azurecr.io
+Name:	gsoci.privatelink.azurecr.io
+Address: 20.1.2.3
+
+Non-authoritative answer:
+Name:	gsoci.privatelink.azurecr.io
+Address: 2603:1030::1
+`,
+			expected: []string{"20.1.2.3", "2603:1030::1"},
+		},
+		{
+			name: "older busybox",
+			output: `Server:    10.96.0.10
+Address 1: 10.96.0.10 kube-dns.kube-system.svc.cluster.local
+
+Name:      kubernetes.default.svc.cluster.local
+Address 1: 10.96.0.1 kubernetes.default.svc.cluster.local
+`,
+			expected: []string{"10.96.0.1"},
+		},
+		{
+			name: "no answer",
+			output: `Server:		172.31.0.10
+Address:	172.31.0.10:53
+
+** server can't find missing.invalid: NXDOMAIN
+`,
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			addresses := parseNslookupAddresses(tc.output)
+			if fmt.Sprint(addresses) != fmt.Sprint(tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, addresses)
+			}
+		})
+	}
+}
+
+func TestNodeLocalDNSIP(t *testing.T) {
+	daemonSet := func(args ...string) *appsv1.DaemonSet {
+		ds := &appsv1.DaemonSet{}
+		ds.Spec.Template.Spec.Containers = []corev1.Container{{Name: "node-cache", Args: args}}
+		return ds
+	}
+
+	testCases := []struct {
+		name     string
+		ds       *appsv1.DaemonSet
+		expected string
+	}{
+		{name: "separate value", ds: daemonSet("-localip", "169.254.20.11,172.31.0.10", "-conf", "/etc/Corefile"), expected: "169.254.20.11"},
+		{name: "inline value", ds: daemonSet("-localip=169.254.20.12"), expected: "169.254.20.12"},
+		{name: "default", ds: daemonSet("-conf", "/etc/Corefile"), expected: defaultNodeLocalDNSIP},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if ip := nodeLocalDNSIP(tc.ds); ip != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, ip)
+			}
+		})
+	}
+}
@@ -27,7 +27,7 @@ import (
 	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
 )
 
-func runHelloWorldGateway(gatewayAPISupported bool) {
+func runHelloWorldGateway(env *state.Environment, cfg *TestConfig) {
 	Context("hello world via gateway api", Ordered, func() {
 		var (
 			helloHelmRelease      *helmv2.HelmRelease
@@ -43,27 +43,27 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 		const appReadyInterval = 5 * time.Second
 
 		BeforeEach(func() {
-			if !gatewayAPISupported {
-				Skip("Gateway API is not supported")
+			if !cfg.GatewayAPISupported {
+				skipUnsupported(cfg, CapabilityGatewayAPI, "Gateway API is not supported")
 			}
 		})
 
 		It("should have cert-manager and external-dns deployed", func() {
-			org := state.GetCluster().Organization
+			org := env.Cluster().Organization
 
 			// appReadyTimeout bounds the cert-manager/external-dns app readiness
 			// waits. Overridable so slow clusters (app reconcile + LB
 			// provisioning) don't fail on a merely-slow environment. Resolved
-			// here (spec runtime) rather than at tree construction, since the
-			// state context is only set in BeforeSuite.
-			appReadyTimeout := state.GetTestTimeout(timeout.GatewayAppReady, 5*time.Minute)
+			// here (spec runtime) rather than at tree construction, since
+			// suites may still override it in a BeforeEach.
+			appReadyTimeout := env.Timeout(timeout.GatewayAppReady)
 
-			Eventually(helmrelease.IsAppOrHelmReleaseReady(state.GetContext(), state.GetFramework().MC(), fmt.Sprintf("%s-cert-manager", state.GetCluster().Name), org.GetNamespace())).
+			Eventually(helmrelease.IsAppOrHelmReleaseReady(env.Context(), env.MC(), fmt.Sprintf("%s-cert-manager", env.Cluster().Name), org.GetNamespace())).
 				WithTimeout(appReadyTimeout).
 				WithPolling(appReadyInterval).
 				Should(BeTrue())
 
-			Eventually(helmrelease.IsAppOrHelmReleaseReady(state.GetContext(), state.GetFramework().MC(), fmt.Sprintf("%s-external-dns", state.GetCluster().Name), org.GetNamespace())).
+			Eventually(helmrelease.IsAppOrHelmReleaseReady(env.Context(), env.MC(), fmt.Sprintf("%s-external-dns", env.Cluster().Name), org.GetNamespace())).
 				WithTimeout(appReadyTimeout).
 				WithPolling(appReadyInterval).
 				Should(BeTrue())
@@ -76,11 +76,11 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 				Skip("aws-lb-controller-bundle values file not found, skipping")
 			}
 
-			clusterName := state.GetCluster().Name
-			namespace := state.GetCluster().Organization.GetNamespace()
+			clusterName := env.Cluster().Name
+			namespace := env.Cluster().Organization.GetNamespace()
 
 			awsLBOCIRepoName = fmt.Sprintf("%s-aws-lb-controller-bundle", clusterName)
-			err := helmrelease.EnsureOCIRepository(state.GetContext(), state.GetFramework().MC(), awsLBOCIRepoName, namespace, "aws-lb-controller-bundle")
+			err := helmrelease.EnsureOCIRepository(env.Context(), env.MC(), awsLBOCIRepoName, namespace, "aws-lb-controller-bundle")
 			Expect(err).To(BeNil())
 
 			hrBuilder, err := helmrelease.New(
@@ -95,28 +95,28 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 				WithValuesFile(awsLBValuesFile, &helmrelease.TemplateValues{
 					ClusterName: clusterName,
 					ExtraValues: map[string]string{
-						"Installation": state.GetFramework().MC().GetClusterName(),
+						"Installation": env.MC().GetClusterName(),
 					},
 				})
 			Expect(err).To(BeNil())
 			awsLBHelmRelease, err = hrBuilder.Build()
 			Expect(err).To(BeNil())
 
-			err = state.GetFramework().MC().Create(state.GetContext(), awsLBHelmRelease)
+			err = env.MC().Create(env.Context(), awsLBHelmRelease)
 			Expect(err).To(BeNil())
 
-			Eventually(helmrelease.IsHelmReleaseReady(state.GetContext(), state.GetFramework().MC(), awsLBHelmRelease.GetName(), awsLBHelmRelease.GetNamespace())).
-				WithTimeout(15 * time.Minute).
+			Eventually(helmrelease.IsHelmReleaseReady(env.Context(), env.MC(), awsLBHelmRelease.GetName(), awsLBHelmRelease.GetNamespace())).
+				WithTimeout(env.Timeout(timeout.AWSLBControllerBundle)).
 				WithPolling(10 * time.Second).
 				Should(BeTrue())
 		})
 
 		It("should deploy gateway-api-bundle", func() {
-			clusterName := state.GetCluster().Name
-			namespace := state.GetCluster().Organization.GetNamespace()
+			clusterName := env.Cluster().Name
+			namespace := env.Cluster().Organization.GetNamespace()
 
 			gatewayAPIOCIRepoName = fmt.Sprintf("%s-gateway-api-bundle", clusterName)
-			err := helmrelease.EnsureOCIRepository(state.GetContext(), state.GetFramework().MC(), gatewayAPIOCIRepoName, namespace, "gateway-api-bundle")
+			err := helmrelease.EnsureOCIRepository(env.Context(), env.MC(), gatewayAPIOCIRepoName, namespace, "gateway-api-bundle")
 			Expect(err).To(BeNil())
 
 			hrBuilder, err := helmrelease.New(
@@ -135,11 +135,11 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 			gatewayAPIHelmRelease, err = hrBuilder.Build()
 			Expect(err).To(BeNil())
 
-			err = state.GetFramework().MC().Create(state.GetContext(), gatewayAPIHelmRelease)
+			err = env.MC().Create(env.Context(), gatewayAPIHelmRelease)
 			Expect(err).To(BeNil())
 
-			Eventually(helmrelease.IsHelmReleaseReady(state.GetContext(), state.GetFramework().MC(), gatewayAPIHelmRelease.GetName(), gatewayAPIHelmRelease.GetNamespace())).
-				WithTimeout(10 * time.Minute).
+			Eventually(helmrelease.IsHelmReleaseReady(env.Context(), env.MC(), gatewayAPIHelmRelease.GetName(), gatewayAPIHelmRelease.GetNamespace())).
+				WithTimeout(env.Timeout(timeout.GatewayAPIBundle)).
 				WithPolling(10 * time.Second).
 				Should(BeTrue())
 
@@ -148,14 +148,14 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 				{Name: fmt.Sprintf("%s-envoy-gateway", clusterName), Namespace: namespace},
 				{Name: fmt.Sprintf("%s-gateway-api-config", clusterName), Namespace: namespace},
 			}
-			Eventually(wait.IsAllAppDeployed(state.GetContext(), state.GetFramework().MC(), childApps)).
-				WithTimeout(10 * time.Minute).
+			Eventually(wait.IsAllAppDeployed(env.Context(), env.MC(), childApps)).
+				WithTimeout(env.Timeout(timeout.GatewayAPIBundle)).
 				WithPolling(10 * time.Second).
 				Should(BeTrue())
 		})
 
 		It("gateway giantswarm-default should be programmed", func() {
-			wcClient, err := state.GetFramework().WC(state.GetCluster().Name)
+			wcClient, err := env.WC()
 			Expect(err).ShouldNot(HaveOccurred())
 
 			Eventually(func() (bool, error) {
@@ -165,7 +165,7 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 					Version: "v1",
 					Kind:    "Gateway",
 				})
-				err := wcClient.Get(state.GetContext(), types.NamespacedName{Name: "giantswarm-default", Namespace: "envoy-gateway-system"}, gateway)
+				err := wcClient.Get(env.Context(), types.NamespacedName{Name: "giantswarm-default", Namespace: "envoy-gateway-system"}, gateway)
 				if err != nil {
 					logger.Log("Failed to get Gateway: %v", err)
 					return false, err
@@ -192,7 +192,7 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 				logger.Log("Gateway 'giantswarm-default' is not yet Programmed")
 				return false, nil
 			}).
-				WithTimeout(10 * time.Minute).
+				WithTimeout(env.Timeout(timeout.GatewayProgrammed)).
 				WithPolling(10 * time.Second).
 				Should(BeTrue())
 		})
@@ -201,32 +201,32 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 		It("cluster wildcard DNS must be resolvable", FlakeAttempts(3), func() {
 			resolver := net.NewResolver()
 			Eventually(func() (bool, error) {
-				result, err := resolver.LookupIP(context.Background(), "ip", fmt.Sprintf("hello-world.%s", getWorkloadClusterDnsZone()))
+				result, err := resolver.LookupIP(context.Background(), "ip", fmt.Sprintf("hello-world.%s", getWorkloadClusterDnsZone(env)))
 				if err != nil {
 					return false, err
 				}
 				if len(result) == 0 {
-					return false, fmt.Errorf("no IP found for hello-world.%s", getWorkloadClusterDnsZone())
+					return false, fmt.Errorf("no IP found for hello-world.%s", getWorkloadClusterDnsZone(env))
 				}
 				var resultString []string
 				for _, ip := range result {
 					resultString = append(resultString, ip.String())
 				}
-				logger.Log("DNS record 'hello-world.%s' resolved to %s", getWorkloadClusterDnsZone(), resultString)
+				logger.Log("DNS record 'hello-world.%s' resolved to %s", getWorkloadClusterDnsZone(env), resultString)
 				return true, nil
 			}).
-				WithTimeout(10*time.Minute).
+				WithTimeout(env.Timeout(timeout.GatewayDNS)).
 				WithPolling(10*time.Second).
-				Should(BeTrue(), failurehandler.ExternalDNSIssues(state.GetFramework(), state.GetCluster()))
+				Should(BeTrue(), failurehandler.ExternalDNSIssues(env.Framework(), env.Cluster()))
 		})
 
 		It("certificate in envoy-gateway-system should be ready", func() {
-			wcClient, err := state.GetFramework().WC(state.GetCluster().Name)
+			wcClient, err := env.WC()
 			Expect(err).ShouldNot(HaveOccurred())
 
 			Eventually(func() error {
 				certList := &certmanager.CertificateList{}
-				err := wcClient.List(state.GetContext(), certList, ctrl.InNamespace("envoy-gateway-system"))
+				err := wcClient.List(env.Context(), certList, ctrl.InNamespace("envoy-gateway-system"))
 				if err != nil {
 					return err
 				}
@@ -255,23 +255,23 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 
 				return nil
 			}).
-				WithTimeout(15*time.Minute).
+				WithTimeout(env.Timeout(timeout.GatewayCertificate)).
 				WithPolling(wait.DefaultInterval).
 				Should(
 					Succeed(),
-					failurehandler.CertificatesNotReady(state.GetFramework(), state.GetCluster(), "envoy-gateway-system"),
+					failurehandler.CertificatesNotReady(env.Framework(), env.Cluster(), "envoy-gateway-system"),
 				)
 		})
 
 		It("should deploy hello-world app with HTTPRoute", func() {
-			org := state.GetCluster().Organization
-			clusterName := state.GetCluster().Name
+			org := env.Cluster().Organization
+			clusterName := env.Cluster().Name
 			namespace := org.GetNamespace()
-			helloWorldHost = fmt.Sprintf("hello-world.%s", getWorkloadClusterDnsZone())
+			helloWorldHost = fmt.Sprintf("hello-world.%s", getWorkloadClusterDnsZone(env))
 			helloWorldUrl = fmt.Sprintf("https://%s", helloWorldHost)
 
 			ociRepoName = fmt.Sprintf("%s-hello-world-chart", clusterName)
-			err := helmrelease.EnsureOCIRepository(state.GetContext(), state.GetFramework().MC(), ociRepoName, namespace, "hello-world")
+			err := helmrelease.EnsureOCIRepository(env.Context(), env.MC(), ociRepoName, namespace, "hello-world")
 			Expect(err).To(BeNil())
 
 			hrBuilder, err := helmrelease.New(
@@ -291,17 +291,17 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 			helloHelmRelease, err = hrBuilder.Build()
 			Expect(err).To(BeNil())
 
-			err = state.GetFramework().MC().Create(state.GetContext(), helloHelmRelease)
+			err = env.MC().Create(env.Context(), helloHelmRelease)
 			Expect(err).To(BeNil())
 
-			Eventually(helmrelease.IsHelmReleaseReady(state.GetContext(), state.GetFramework().MC(), helloHelmRelease.GetName(), helloHelmRelease.GetNamespace())).
-				WithTimeout(6 * time.Minute).
+			Eventually(helmrelease.IsHelmReleaseReady(env.Context(), env.MC(), helloHelmRelease.GetName(), helloHelmRelease.GetNamespace())).
+				WithTimeout(env.Timeout(timeout.HelloWorldReady)).
 				WithPolling(5 * time.Second).
 				Should(BeTrue())
 		})
 
 		It("HTTPRoute should be accepted by gateway", func() {
-			wcClient, err := state.GetFramework().WC(state.GetCluster().Name)
+			wcClient, err := env.WC()
 			Expect(err).ShouldNot(HaveOccurred())
 
 			Eventually(func() (bool, error) {
@@ -311,7 +311,7 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 					Version: "v1",
 					Kind:    "HTTPRoute",
 				})
-				err := wcClient.Get(state.GetContext(), types.NamespacedName{Name: "hello-world", Namespace: "giantswarm"}, httpRoute)
+				err := wcClient.Get(env.Context(), types.NamespacedName{Name: "hello-world", Namespace: "giantswarm"}, httpRoute)
 				if err != nil {
 					logger.Log("Failed to get HTTPRoute: %v", err)
 					return false, err
@@ -350,7 +350,7 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 
 				return true, nil
 			}).
-				WithTimeout(6 * time.Minute).
+				WithTimeout(env.Timeout(timeout.HelloWorldReady)).
 				WithPolling(5 * time.Second).
 				Should(BeTrue())
 		})
@@ -380,7 +380,7 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 
 				return string(bodyBytes), nil
 			}).
-				WithTimeout(15 * time.Minute).
+				WithTimeout(env.Timeout(timeout.HelloWorldResponse)).
 				WithPolling(5 * time.Second).
 				Should(
 					ContainSubstring("Hello World"),
@@ -389,38 +389,38 @@ func runHelloWorldGateway(gatewayAPISupported bool) {
 
 		It("uninstall apps", func() {
 			if helloHelmRelease != nil {
-				err := state.GetFramework().MC().Delete(state.GetContext(), helloHelmRelease)
+				err := env.MC().Delete(env.Context(), helloHelmRelease)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				err = helmrelease.DeleteOCIRepository(state.GetContext(), state.GetFramework().MC(), ociRepoName, helloHelmRelease.GetNamespace())
+				err = helmrelease.DeleteOCIRepository(env.Context(), env.MC(), ociRepoName, helloHelmRelease.GetNamespace())
 				Expect(err).ShouldNot(HaveOccurred())
 			}
 			if gatewayAPIHelmRelease != nil {
-				err := state.GetFramework().MC().Delete(state.GetContext(), gatewayAPIHelmRelease)
+				err := env.MC().Delete(env.Context(), gatewayAPIHelmRelease)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				err = helmrelease.DeleteOCIRepository(state.GetContext(), state.GetFramework().MC(), gatewayAPIOCIRepoName, gatewayAPIHelmRelease.GetNamespace())
+				err = helmrelease.DeleteOCIRepository(env.Context(), env.MC(), gatewayAPIOCIRepoName, gatewayAPIHelmRelease.GetNamespace())
 				Expect(err).ShouldNot(HaveOccurred())
 			}
 			if awsLBHelmRelease != nil {
-				err := state.GetFramework().MC().Delete(state.GetContext(), awsLBHelmRelease)
+				err := env.MC().Delete(env.Context(), awsLBHelmRelease)
 				Expect(err).ShouldNot(HaveOccurred())
 
-				err = helmrelease.DeleteOCIRepository(state.GetContext(), state.GetFramework().MC(), awsLBOCIRepoName, awsLBHelmRelease.GetNamespace())
+				err = helmrelease.DeleteOCIRepository(env.Context(), env.MC(), awsLBOCIRepoName, awsLBHelmRelease.GetNamespace())
 				Expect(err).ShouldNot(HaveOccurred())
 			}
 		})
 	})
 }
 
-func getWorkloadClusterDnsZone() string {
+func getWorkloadClusterDnsZone(env *state.Environment) string {
 	values := &application.ClusterValues{}
-	err := state.GetFramework().MC().GetHelmValues(state.GetCluster().Name, state.GetCluster().GetNamespace(), values)
+	err := env.MC().GetHelmValues(env.Cluster().Name, env.Cluster().GetNamespace(), values)
 	Expect(err).NotTo(HaveOccurred())
 
 	if values.BaseDomain == "" {
 		Fail("baseDomain field missing from cluster helm values")
 	}
 
-	return fmt.Sprintf("%s.%s", state.GetCluster().Name, values.BaseDomain)
+	return fmt.Sprintf("%s.%s", env.Cluster().Name, values.BaseDomain)
 }
@@ -0,0 +1,170 @@
+package common
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"sigs.k8s.io/yaml"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
+)
+
+// SuiteManifestPath is the location of the suite manifest, relative to the suite directory.
+const SuiteManifestPath = "./test_data/suite.yaml"
+
+//go:embed suite.schema.json
+var suiteManifestSchema []byte
+
+// SuiteManifest declares what a provider test suite exercises: the owning team, which
+// capabilities deviate from the defaults (and why) and per-test timeout overrides.
+type SuiteManifest struct {
+	Team         string                        `json:"team"`
+	Capabilities map[Capability]CapabilitySpec `json:"capabilities,omitempty"`
+	Timeouts     map[timeout.TestKey]string    `json:"timeouts,omitempty"`
+	Storage      StorageConfig                 `json:"storage,omitempty"`
+	PodSecurity  PodSecurityConfig             `json:"podSecurity,omitempty"`
+	RBAC         RBACConfig                    `json:"rbac,omitempty"`
+
+	team     helper.Team
+	timeouts map[timeout.TestKey]time.Duration
+}
+
+// CapabilitySpec enables or disables a single capability. Disabled capabilities must
+// carry a reason and may link to the issue tracking their re-enablement.
+type CapabilitySpec struct {
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason,omitempty"`
+	Issue   string `json:"issue,omitempty"`
+}
+
+// SkipReason returns the message used when skipping specs for a disabled capability.
+func (c CapabilitySpec) SkipReason() string {
+	if c.Issue == "" {
+		return c.Reason
+	}
+	return fmt.Sprintf("%s (%s)", c.Reason, c.Issue)
+}
+
+// LoadSuiteManifest reads the suite manifest at path and validates it against the
+// embedded schema, the known teams and the known timeout keys.
+func LoadSuiteManifest(path string) (*SuiteManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suite manifest: %w", err)
+	}
+
+	return parseSuiteManifest(data)
+}
+
+// MustLoadSuiteManifest is like LoadSuiteManifest but panics on error. It is meant to
+// be called while building the spec tree, where a broken manifest should abort the suite.
+func MustLoadSuiteManifest(path string) *SuiteManifest {
+	manifest, err := LoadSuiteManifest(path)
+	if err != nil {
+		panic(fmt.Sprintf("invalid suite manifest %s: %v", path, err))
+	}
+	return manifest
+}
+
+func parseSuiteManifest(data []byte) (*SuiteManifest, error) {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse suite manifest: %w", err)
+	}
+
+	if err := validateSuiteManifestSchema(jsonData); err != nil {
+		return nil, err
+	}
+
+	manifest := &SuiteManifest{}
+	if err := json.Unmarshal(jsonData, manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode suite manifest: %w", err)
+	}
+
+	team, ok := helper.TeamFromLabel(manifest.Team)
+	if !ok {
+		return nil, fmt.Errorf("unknown team %q", manifest.Team)
+	}
+	manifest.team = team
+
+	manifest.timeouts = make(map[timeout.TestKey]time.Duration, len(manifest.Timeouts))
+	for key, value := range manifest.Timeouts {
+		if !timeout.IsKnown(key) {
+			return nil, fmt.Errorf("unknown timeout key %q", key)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q for timeout %q: %w", value, key, err)
+		}
+		manifest.timeouts[key] = d
+	}
+
+	return manifest, nil
+}
+
+func validateSuiteManifestSchema(jsonData []byte) error {
+	schemaDoc, err := jsonschema.UnmarshalJSON(bytes.NewReader(suiteManifestSchema))
+	if err != nil {
+		return fmt.Errorf("failed to parse suite manifest schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("suite.schema.json", schemaDoc); err != nil {
+		return fmt.Errorf("failed to load suite manifest schema: %w", err)
+	}
+	schema, err := compiler.Compile("suite.schema.json")
+	if err != nil {
+		return fmt.Errorf("failed to compile suite manifest schema: %w", err)
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to parse suite manifest: %w", err)
+	}
+	if err := schema.Validate(doc); err != nil {
+		return fmt.Errorf("suite manifest does not match schema: %w", err)
+	}
+
+	return nil
+}
+
+// TestConfig returns the default TestConfig with the manifest's capabilities, storage, Pod
+// Security Admission and RBAC expectations applied.
+func (m *SuiteManifest) TestConfig() *TestConfig {
+	cfg := NewTestConfigWithDefaults()
+	cfg.Storage = m.Storage
+	cfg.PodSecurity = m.PodSecurity
+	cfg.RBAC = m.RBAC
+	cfg.SkipReasons = map[Capability]string{}
+	for capability, spec := range m.Capabilities {
+		cfg.SetCapability(capability, spec.Enabled)
+		if !spec.Enabled {
+			cfg.SkipReasons[capability] = spec.SkipReason()
+		}
+	}
+	return cfg
+}
+
+// Setup applies the manifest's timeout overrides to env and registers a BeforeEach in
+// the current container that records the team owning the suite in the report.
+// Overrides from E2E_TIMEOUT_OVERRIDES take precedence over the manifest.
+func (m *SuiteManifest) Setup(env *state.Environment) {
+	for key, d := range m.timeouts {
+		if err := env.SetTimeout(key, d); err != nil {
+			panic(fmt.Sprintf("invalid suite manifest timeout: %v", err))
+		}
+	}
+
+	BeforeEach(func() {
+		AddReportEntry("SUITE_TEAM", string(m.team))
+	})
+}
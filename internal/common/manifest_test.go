@@ -0,0 +1,173 @@
+package common
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
+)
+
+func TestParseSuiteManifest(t *testing.T) {
+	testCases := []struct {
+		name        string
+		manifest    string
+		expectedErr string
+	}{
+		{
+			name: "valid",
+			manifest: `
+team: phoenix
+capabilities:
+  autoScaling:
+    enabled: false
+    reason: No autoscaling
+    issue: https://github.com/giantswarm/roadmap/issues/1
+timeouts:
+  deployAppsTimeout: 25m
+`,
+		},
+		{
+			name:        "schema violation",
+			manifest:    "team: phoenix\nprovider: capa\n",
+			expectedErr: "does not match schema",
+		},
+		{
+			name:        "missing team",
+			manifest:    "capabilities: {}\n",
+			expectedErr: "does not match schema",
+		},
+		{
+			name:        "unknown team",
+			manifest:    "team: nobody\n",
+			expectedErr: `unknown team "nobody"`,
+		},
+		{
+			name:        "unknown timeout key",
+			manifest:    "team: phoenix\ntimeouts:\n  fooTimeout: 5m\n",
+			expectedErr: `unknown timeout key "fooTimeout"`,
+		},
+		{
+			name:        "malformed duration",
+			manifest:    "team: phoenix\ntimeouts:\n  deployAppsTimeout: five minutes\n",
+			expectedErr: "does not match schema",
+		},
+		{
+			name:        "invalid duration",
+			manifest:    "team: phoenix\ntimeouts:\n  deployAppsTimeout: 99999999999999999999h\n",
+			expectedErr: "invalid duration",
+		},
+		{
+			name:        "disabled capability without a reason",
+			manifest:    "team: phoenix\ncapabilities:\n  autoScaling:\n    enabled: false\n",
+			expectedErr: "does not match schema",
+		},
+		{
+			name:        "cluster-admin binding without a ServiceAccount",
+			manifest:    "team: phoenix\nrbac:\n  clusterAdminBindings:\n    - chart-operator\n",
+			expectedErr: "does not match schema",
+		},
+		{
+			name:        "unknown capability",
+			manifest:    "team: phoenix\ncapabilities:\n  teleportation:\n    enabled: true\n",
+			expectedErr: "does not match schema",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseSuiteManifest([]byte(tc.manifest))
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected an error containing %q, got nil", tc.expectedErr)
+			}
+			if !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected an error containing %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestSuiteManifestTestConfig(t *testing.T) {
+	manifest, err := parseSuiteManifest([]byte(`
+team: team-rocket
+capabilities:
+  externalDns:
+    enabled: false
+    reason: No DNS zone
+    issue: https://github.com/giantswarm/roadmap/issues/1037
+  armNodePool:
+    enabled: true
+  connectivity:
+    enabled: false
+    reason: No pods can be scheduled
+  storage:
+    enabled: false
+    reason: No volume provisioner
+timeouts:
+  clusterReadyTimeout: 40m
+rbac:
+  clusterAdminBindings: []
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.team != helper.TeamRocket {
+		t.Errorf("expected team %q, got %q", helper.TeamRocket, manifest.team)
+	}
+	if d := manifest.timeouts[timeout.ClusterReadyTimeout]; d != 40*time.Minute {
+		t.Errorf("expected clusterReadyTimeout 40m, got %s", d)
+	}
+
+	cfg := manifest.TestConfig()
+	if cfg.ExternalDnsSupported {
+		t.Error("expected externalDns to be disabled")
+	}
+	if !cfg.ARMNodePoolEnabled {
+		t.Error("expected armNodePool to be enabled")
+	}
+	if cfg.ConnectivitySupported {
+		t.Error("expected connectivity to be disabled")
+	}
+	if cfg.StorageSupported {
+		t.Error("expected storage to be disabled")
+	}
+	if !cfg.CertManagerSupported {
+		t.Error("expected certManager to keep its default")
+	}
+	if cfg.RBAC.ClusterAdminBindings == nil {
+		t.Error("expected an empty cluster-admin allow-list to be kept, not treated as undeclared")
+	}
+	expectedReason := "No DNS zone (https://github.com/giantswarm/roadmap/issues/1037)"
+	if reason := cfg.SkipReasons[CapabilityExternalDns]; reason != expectedReason {
+		t.Errorf("expected skip reason %q, got %q", expectedReason, reason)
+	}
+}
+
+// TestProviderSuiteManifests loads the manifest of every provider suite, so a broken one
+// fails here instead of panicking while the suite builds its spec tree.
+func TestProviderSuiteManifests(t *testing.T) {
+	paths, err := filepath.Glob("../../providers/*/*/test_data/suite.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("expected to find provider suite manifests")
+	}
+
+	for _, path := range paths {
+		t.Run(strings.TrimPrefix(filepath.Dir(filepath.Dir(path)), "../../providers/"), func(t *testing.T) {
+			if _, err := LoadSuiteManifest(path); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -23,7 +23,7 @@ import (
 
 const mimirUrl = "mimir-gateway.mimir.svc:80/prometheus"
 
-func runMetrics(cfg *TestConfig) {
+func runMetrics(env *state.Environment, cfg *TestConfig) {
 	Context("metrics", func() {
 		var mcClient *client.Client
 		var metrics []string
@@ -32,12 +32,12 @@ func runMetrics(cfg *TestConfig) {
 
 		BeforeEach(func() {
 			if !cfg.ObservabilityBundleInstalled {
-				Skip("Observability bundle is not installed in this cluster configuration")
+				skipUnsupported(cfg, CapabilityObservabilityBundle, "Observability bundle is not installed in this cluster configuration")
 			}
 
 			helper.SetResponsibleTeam(helper.TeamAtlas)
 
-			mcClient = state.GetFramework().MC()
+			mcClient = env.MC()
 
 			// List of metrics that must be present.
 			metrics = []string{
@@ -86,11 +86,11 @@ func runMetrics(cfg *TestConfig) {
 
 		It("creates test pod", func() {
 			if !cfg.ObservabilityBundleInstalled {
-				Skip("Observability bundle is not installed in this cluster configuration")
+				skipUnsupported(cfg, CapabilityObservabilityBundle, "Observability bundle is not installed in this cluster configuration")
 			}
 
 			// Run a pod with alpine in the default namespace of the MC.
-			testPodName = fmt.Sprintf("%s-metrics-test", state.GetCluster().Name)
+			testPodName = fmt.Sprintf("%s-metrics-test", env.Cluster().Name)
 			testPodNamespace = "default"
 
 			err := runTestPod(mcClient, testPodName, testPodNamespace)
@@ -104,12 +104,12 @@ func runMetrics(cfg *TestConfig) {
 		// inherently eventually-consistent.
 		It("ensure key metrics are available on mimir", FlakeAttempts(3), func() {
 			if !cfg.ObservabilityBundleInstalled {
-				Skip("Observability bundle is not installed in this cluster configuration")
+				skipUnsupported(cfg, CapabilityObservabilityBundle, "Observability bundle is not installed in this cluster configuration")
 			}
 
-			metricsTimeout := state.GetTestTimeout(timeout.MimirMetrics, 10*time.Minute)
+			metricsTimeout := env.Timeout(timeout.MimirMetrics)
 			for _, metric := range metrics {
-				Eventually(checkMetricPresent(mcClient, metric, mimirUrl, testPodName, testPodNamespace)).
+				Eventually(checkMetricPresent(mcClient, env.Cluster().Name, metric, mimirUrl, testPodName, testPodNamespace)).
 					WithTimeout(metricsTimeout).
 					WithPolling(10 * time.Second).
 					Should(Succeed())
@@ -118,7 +118,7 @@ func runMetrics(cfg *TestConfig) {
 
 		It("clean up test pod", func() {
 			if !cfg.ObservabilityBundleInstalled {
-				Skip("Observability bundle is not installed in this cluster configuration")
+				skipUnsupported(cfg, CapabilityObservabilityBundle, "Observability bundle is not installed in this cluster configuration")
 			}
 
 			err := cleanupTestPod(mcClient, testPodName, testPodNamespace)
@@ -127,9 +127,9 @@ func runMetrics(cfg *TestConfig) {
 	})
 }
 
-func checkMetricPresent(mcClient *client.Client, metric string, mimirUrl string, testPodName string, testPodNamespace string) func() error {
+func checkMetricPresent(mcClient *client.Client, clusterName string, metric string, mimirUrl string, testPodName string, testPodNamespace string) func() error {
 	return func() error {
-		query := fmt.Sprintf("absent(%[1]s{cluster_id=\"%[2]s\"}) or label_replace(vector(0), \"cluster_id\", \"%[2]s\", \"\", \"\")", metric, state.GetCluster().Name)
+		query := fmt.Sprintf("absent(%[1]s{cluster_id=\"%[2]s\"}) or label_replace(vector(0), \"cluster_id\", \"%[2]s\", \"\", \"\")", metric, clusterName)
 
 		cmd := []string{"wget", "-O-", "-Y", "off", "--header", "X-Scope-OrgID: anonymous|giantswarm", fmt.Sprintf("%[1]s/api/v1/query?query=%[2]s", mimirUrl, url.QueryEscape(query))}
 		stdout, stderr, err := mcClient.ExecInPod(context.Background(), testPodName, testPodNamespace, "test", cmd)
@@ -0,0 +1,326 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+	"github.com/giantswarm/clustertest/v5/pkg/wait"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+	. "github.com/onsi/gomega"    //nolint:staticcheck
+)
+
+const (
+	netpolServerNamespace = "test-netpol-server"
+	netpolClientNamespace = "test-netpol-client"
+	netpolServerName      = "netpol-server"
+	netpolServerPort      = 8080
+	netpolAllowedClient   = "netpol-allowed-client"
+	netpolDeniedClient    = "netpol-denied-client"
+	netpolRoleLabel       = "netpol-test/role"
+)
+
+// runNetworkPolicy checks that NetworkPolicies and CiliumNetworkPolicies are enforced.
+// A server runs in its own namespace and is called by two client pods in a second
+// namespace, only one of which is allowed by the policies under test.
+func runNetworkPolicy(env *state.Environment, cfg *TestConfig) {
+	Context("network policy", Ordered, func() {
+		var wcClient *client.Client
+
+		BeforeAll(func() {
+			if !cfg.NetworkPolicySupported {
+				skipUnsupported(cfg, CapabilityNetworkPolicy, "Network policies are not enforced in this cluster configuration")
+			}
+		})
+
+		BeforeEach(func() {
+			helper.SetResponsibleTeam(helper.TeamPhoenix)
+
+			var err error
+
+			wcClient, err = env.WC()
+			if err != nil {
+				Fail(err.Error())
+			}
+		})
+
+		It("creates the server and client pods", func() {
+			for _, obj := range netpolWorkloads() {
+				Eventually(func() error {
+					logger.Log("Creating %T '%s'", obj, obj.GetName())
+					err := wcClient.Create(env.Context(), obj)
+					if err != nil && !apierror.IsAlreadyExists(err) {
+						logger.Log("Failed to create %T '%s' - %v", obj, obj.GetName(), err)
+						return err
+					}
+					return nil
+				}).
+					WithTimeout(env.Timeout(timeout.ResourceApply)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			}
+
+			for _, pod := range []string{netpolServerName, netpolAllowedClient, netpolDeniedClient} {
+				namespace := netpolClientNamespace
+				if pod == netpolServerName {
+					namespace = netpolServerNamespace
+				}
+				Eventually(verifyPodState(env.Context(), wcClient, pod, namespace)).
+					WithTimeout(env.Timeout(timeout.ProbeReady)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			}
+		})
+
+		It("allows all traffic without a network policy", func() {
+			expectConnectivity(env, wcClient, netpolAllowedClient, true)
+			expectConnectivity(env, wcClient, netpolDeniedClient, true)
+		})
+
+		It("blocks all traffic with a default-deny NetworkPolicy", func() {
+			Expect(createOrUpdate(env.Context(), wcClient, netpolDefaultDeny())).To(Succeed())
+
+			expectConnectivity(env, wcClient, netpolAllowedClient, false)
+			expectConnectivity(env, wcClient, netpolDeniedClient, false)
+		})
+
+		It("allows only the allow-listed client with a NetworkPolicy", func() {
+			Expect(createOrUpdate(env.Context(), wcClient, netpolAllowList())).To(Succeed())
+
+			expectConnectivity(env, wcClient, netpolAllowedClient, true)
+			expectConnectivity(env, wcClient, netpolDeniedClient, false)
+		})
+
+		It("allows only the allow-listed client with a CiliumNetworkPolicy", func() {
+			err := wcClient.Delete(env.Context(), netpolAllowList())
+			if err != nil && !apierror.IsNotFound(err) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			expectConnectivity(env, wcClient, netpolAllowedClient, false)
+
+			Expect(createOrUpdate(env.Context(), wcClient, ciliumAllowList())).To(Succeed())
+
+			expectConnectivity(env, wcClient, netpolAllowedClient, true)
+			expectConnectivity(env, wcClient, netpolDeniedClient, false)
+		})
+
+		AfterAll(func() {
+			if !cfg.NetworkPolicySupported {
+				return
+			}
+
+			wc, err := env.WC()
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, ns := range []string{netpolServerNamespace, netpolClientNamespace} {
+				Eventually(func() error {
+					logger.Log("Deleting Namespace '%s'", ns)
+					err := wc.Delete(env.Context(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})
+					if err != nil && !apierror.IsNotFound(err) {
+						logger.Log("Failed to delete Namespace '%s'", ns)
+						return err
+					}
+					return nil
+				}).
+					WithTimeout(env.Timeout(timeout.ResourceApply)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+
+				Eventually(wait.IsResourceDeleted(env.Context(), wc, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})).
+					WithTimeout(env.Timeout(timeout.NamespaceDeletion)).
+					WithPolling(wait.DefaultInterval).
+					Should(BeTrue())
+			}
+		})
+	})
+}
+
+// expectConnectivity waits until the client pod can, or can't, reach the server and then
+// checks that it stays that way.
+func expectConnectivity(env *state.Environment, wcClient *client.Client, clientPod string, allowed bool) {
+	Eventually(wait.Consistent(checkServerConnectivity(env.Context(), wcClient, clientPod, allowed), 3, 5*time.Second)).
+		WithTimeout(env.Timeout(timeout.NetworkPolicy)).
+		WithPolling(wait.DefaultInterval).
+		Should(Succeed())
+}
+
+func checkServerConnectivity(ctx context.Context, wcClient *client.Client, clientPod string, allowed bool) func() error {
+	return func() error {
+		url := fmt.Sprintf("http://%s.%s.svc:%d", netpolServerName, netpolServerNamespace, netpolServerPort)
+		cmd := []string{"wget", "-q", "-O", "/dev/null", "-T", "3", url}
+		_, stderr, err := wcClient.ExecInPod(ctx, clientPod, netpolClientNamespace, "client", cmd)
+		connected := err == nil
+
+		logger.Log("Pod '%s' connecting to '%s': connected=%t, expected=%t (stderr: %q)", clientPod, url, connected, allowed, stderr)
+		if connected != allowed {
+			if allowed {
+				return fmt.Errorf("pod %s can't reach %s: %v", clientPod, url, err)
+			}
+			return fmt.Errorf("pod %s can reach %s although it should be blocked", clientPod, url)
+		}
+		return nil
+	}
+}
+
+func createOrUpdate(ctx context.Context, wcClient *client.Client, obj cr.Object) error {
+	logger.Log("Applying %T '%s'", obj, obj.GetName())
+	err := wcClient.Create(ctx, obj)
+	if !apierror.IsAlreadyExists(err) {
+		return err
+	}
+
+	existing := obj.DeepCopyObject().(cr.Object)
+	if err := wcClient.Get(ctx, cr.ObjectKeyFromObject(obj), existing); err != nil {
+		return err
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return wcClient.Update(ctx, obj)
+}
+
+func netpolWorkloads() []cr.Object {
+	return []cr.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: netpolServerNamespace}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: netpolClientNamespace}},
+		netpolPod(netpolServerName, netpolServerNamespace, "server", "gsoci.azurecr.io/giantswarm/nginx-unprivileged:1.31-alpine", nil),
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: netpolServerName, Namespace: netpolServerNamespace},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{netpolRoleLabel: "server"},
+				Ports: []corev1.ServicePort{
+					{Name: "http", Port: netpolServerPort, TargetPort: intstr.FromInt32(netpolServerPort)},
+				},
+			},
+		},
+		netpolPod(netpolAllowedClient, netpolClientNamespace, "allowed", "gsoci.azurecr.io/giantswarm/alpine:latest", []string{"sleep", "99999999"}),
+		netpolPod(netpolDeniedClient, netpolClientNamespace, "denied", "gsoci.azurecr.io/giantswarm/alpine:latest", []string{"sleep", "99999999"}),
+	}
+}
+
+func netpolPod(name, namespace, role, image string, args []string) *corev1.Pod {
+	t := true
+	f := false
+	user := int64(1001)
+
+	container := "client"
+	if role == "server" {
+		container = "server"
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    map[string]string{netpolRoleLabel: role},
+		},
+		Spec: corev1.PodSpec{
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsUser:    &user,
+				RunAsNonRoot: &t,
+				SeccompProfile: &corev1.SeccompProfile{
+					Type: corev1.SeccompProfileTypeRuntimeDefault,
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:  container,
+					Image: image,
+					Args:  args,
+					SecurityContext: &corev1.SecurityContext{
+						Capabilities: &corev1.Capabilities{
+							Drop: []corev1.Capability{"ALL"},
+						},
+						AllowPrivilegeEscalation: &f,
+					},
+				},
+			},
+		},
+	}
+}
+
+// netpolDefaultDeny blocks all ingress traffic into the server namespace.
+func netpolDefaultDeny() *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "default-deny", Namespace: netpolServerNamespace},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}
+}
+
+// netpolAllowList allows the allowed client, and only that client, to reach the server.
+func netpolAllowList() *networkingv1.NetworkPolicy {
+	port := intstr.FromInt32(netpolServerPort)
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "allow-list", Namespace: netpolServerNamespace},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{netpolRoleLabel: "server"}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					From: []networkingv1.NetworkPolicyPeer{
+						{
+							NamespaceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{"kubernetes.io/metadata.name": netpolClientNamespace},
+							},
+							PodSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{netpolRoleLabel: "allowed"},
+							},
+						},
+					},
+					Ports: []networkingv1.NetworkPolicyPort{{Port: &port}},
+				},
+			},
+		},
+	}
+}
+
+// ciliumAllowList is the CiliumNetworkPolicy equivalent of netpolAllowList.
+func ciliumAllowList() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cilium.io/v2",
+		"kind":       "CiliumNetworkPolicy",
+		"metadata": map[string]interface{}{
+			"name":      "cilium-allow-list",
+			"namespace": netpolServerNamespace,
+		},
+		"spec": map[string]interface{}{
+			"endpointSelector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{netpolRoleLabel: "server"},
+			},
+			"ingress": []interface{}{
+				map[string]interface{}{
+					"fromEndpoints": []interface{}{
+						map[string]interface{}{
+							"matchLabels": map[string]interface{}{
+								"k8s:io.kubernetes.pod.namespace": netpolClientNamespace,
+								netpolRoleLabel:                   "allowed",
+							},
+						},
+					},
+					"toPorts": []interface{}{
+						map[string]interface{}{
+							"ports": []interface{}{
+								map[string]interface{}{"port": fmt.Sprintf("%d", netpolServerPort), "protocol": "TCP"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}}
+}
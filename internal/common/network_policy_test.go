@@ -0,0 +1,164 @@
+package common
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func netpolTestPods(t *testing.T) map[string]*corev1.Pod {
+	t.Helper()
+
+	pods := map[string]*corev1.Pod{}
+	for _, obj := range netpolWorkloads() {
+		if pod, ok := obj.(*corev1.Pod); ok {
+			pods[pod.Name] = pod
+		}
+	}
+	for _, name := range []string{netpolServerName, netpolAllowedClient, netpolDeniedClient} {
+		if pods[name] == nil {
+			t.Fatalf("expected the workloads to include pod %s", name)
+		}
+	}
+	return pods
+}
+
+func selects(t *testing.T, selector *metav1.LabelSelector, set map[string]string) bool {
+	t.Helper()
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s.Matches(labels.Set(set))
+}
+
+func TestNetpolDefaultDeny(t *testing.T) {
+	pods := netpolTestPods(t)
+	policy := netpolDefaultDeny()
+
+	if policy.Namespace != netpolServerNamespace {
+		t.Errorf("expected the policy in %s, got %s", netpolServerNamespace, policy.Namespace)
+	}
+	if !selects(t, &policy.Spec.PodSelector, pods[netpolServerName].Labels) {
+		t.Error("expected the policy to select the server")
+	}
+	if len(policy.Spec.Ingress) != 0 || len(policy.Spec.PolicyTypes) != 1 || policy.Spec.PolicyTypes[0] != networkingv1.PolicyTypeIngress {
+		t.Errorf("expected the policy to deny all ingress, got %+v", policy.Spec)
+	}
+}
+
+func TestNetpolAllowList(t *testing.T) {
+	pods := netpolTestPods(t)
+	policy := netpolAllowList()
+
+	if policy.Namespace != netpolServerNamespace {
+		t.Errorf("expected the policy in %s, got %s", netpolServerNamespace, policy.Namespace)
+	}
+	if !selects(t, &policy.Spec.PodSelector, pods[netpolServerName].Labels) {
+		t.Error("expected the policy to select the server")
+	}
+	if selects(t, &policy.Spec.PodSelector, pods[netpolAllowedClient].Labels) {
+		t.Error("expected the policy not to select the clients")
+	}
+
+	if len(policy.Spec.Ingress) != 1 || len(policy.Spec.Ingress[0].From) != 1 {
+		t.Fatalf("expected a single ingress peer, got %+v", policy.Spec.Ingress)
+	}
+	rule := policy.Spec.Ingress[0]
+	peer := rule.From[0]
+
+	clientNamespace := map[string]string{"kubernetes.io/metadata.name": netpolClientNamespace}
+	if !selects(t, peer.NamespaceSelector, clientNamespace) {
+		t.Error("expected the peer to select the client namespace")
+	}
+	if selects(t, peer.NamespaceSelector, map[string]string{"kubernetes.io/metadata.name": netpolServerNamespace}) {
+		t.Error("expected the peer not to select the server namespace")
+	}
+	if !selects(t, peer.PodSelector, pods[netpolAllowedClient].Labels) {
+		t.Error("expected the peer to select the allowed client")
+	}
+	if selects(t, peer.PodSelector, pods[netpolDeniedClient].Labels) {
+		t.Error("expected the peer not to select the denied client")
+	}
+
+	if len(rule.Ports) != 1 || rule.Ports[0].Port.IntValue() != netpolServerPort {
+		t.Errorf("expected the server port to be allowed, got %+v", rule.Ports)
+	}
+}
+
+func TestCiliumAllowList(t *testing.T) {
+	pods := netpolTestPods(t)
+	policy := ciliumAllowList()
+
+	if policy.GetNamespace() != netpolServerNamespace {
+		t.Errorf("expected the policy in %s, got %s", netpolServerNamespace, policy.GetNamespace())
+	}
+
+	endpointLabels, _, _ := unstructured.NestedStringMap(policy.Object, "spec", "endpointSelector", "matchLabels")
+	if !selects(t, &metav1.LabelSelector{MatchLabels: endpointLabels}, pods[netpolServerName].Labels) {
+		t.Error("expected the policy to select the server")
+	}
+
+	ingress, _, _ := unstructured.NestedSlice(policy.Object, "spec", "ingress")
+	if len(ingress) != 1 {
+		t.Fatalf("expected a single ingress rule, got %v", ingress)
+	}
+	rule := ingress[0].(map[string]interface{})
+
+	fromEndpoints, _, _ := unstructured.NestedSlice(rule, "fromEndpoints")
+	if len(fromEndpoints) != 1 {
+		t.Fatalf("expected a single endpoint selector, got %v", fromEndpoints)
+	}
+	fromLabels, _, _ := unstructured.NestedStringMap(fromEndpoints[0].(map[string]interface{}), "matchLabels")
+	// Cilium exposes the namespace of an endpoint as a label.
+	withNamespace := func(pod *corev1.Pod) map[string]string {
+		set := map[string]string{"k8s:io.kubernetes.pod.namespace": pod.Namespace}
+		for k, v := range pod.Labels {
+			set[k] = v
+		}
+		return set
+	}
+	// The Cilium label keys aren't valid Kubernetes label keys, so don't validate the selector.
+	fromSelector := labels.SelectorFromValidatedSet(fromLabels)
+	if !fromSelector.Matches(labels.Set(withNamespace(pods[netpolAllowedClient]))) {
+		t.Error("expected the policy to allow the allowed client")
+	}
+	if fromSelector.Matches(labels.Set(withNamespace(pods[netpolDeniedClient]))) {
+		t.Error("expected the policy not to allow the denied client")
+	}
+
+	toPorts, _, _ := unstructured.NestedSlice(rule, "toPorts")
+	if len(toPorts) != 1 {
+		t.Fatalf("expected a single port rule, got %v", toPorts)
+	}
+	ports, _, _ := unstructured.NestedSlice(toPorts[0].(map[string]interface{}), "ports")
+	if len(ports) != 1 || ports[0].(map[string]interface{})["port"] != "8080" {
+		t.Errorf("expected the server port to be allowed, got %v", ports)
+	}
+}
+
+func TestNetpolPod(t *testing.T) {
+	pods := netpolTestPods(t)
+
+	if pods[netpolServerName].Namespace != netpolServerNamespace || pods[netpolAllowedClient].Namespace != netpolClientNamespace || pods[netpolDeniedClient].Namespace != netpolClientNamespace {
+		t.Error("expected the server and clients in their own namespaces")
+	}
+	// The client pods are exec'ed into by container name.
+	for _, name := range []string{netpolAllowedClient, netpolDeniedClient} {
+		if container := pods[name].Spec.Containers[0].Name; container != "client" {
+			t.Errorf("expected pod %s to run container client, got %s", name, container)
+		}
+	}
+
+	for name, pod := range pods {
+		securityContext := pod.Spec.Containers[0].SecurityContext
+		if pod.Spec.SecurityContext.RunAsNonRoot == nil || !*pod.Spec.SecurityContext.RunAsNonRoot || *securityContext.AllowPrivilegeEscalation {
+			t.Errorf("expected pod %s to be compliant with the restricted Pod Security Standard", name)
+		}
+	}
+}
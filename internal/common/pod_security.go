@@ -0,0 +1,208 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+	"github.com/giantswarm/clustertest/v5/pkg/wait"
+	corev1 "k8s.io/api/core/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+	. "github.com/onsi/gomega"    //nolint:staticcheck
+)
+
+const (
+	podSecurityTestNamespace = "test-pod-security"
+
+	podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+	podSecurityRestricted  = "restricted"
+)
+
+var podSecurityModes = []string{"enforce", "audit", "warn"}
+
+// PodSecurityConfig declares the Pod Security Admission levels a suite expects on the
+// namespaces of the workload cluster. It is set from the podSecurity section of the suite manifest.
+type PodSecurityConfig struct {
+	// Namespaces whose levels are checked. Namespaces that aren't listed are only reported.
+	Namespaces []NamespacePodSecurity `json:"namespaces,omitempty"`
+}
+
+// NamespacePodSecurity declares the expected levels of a namespace. A mode without a level
+// must not be labelled, so the cluster-wide default applies.
+type NamespacePodSecurity struct {
+	Name    string `json:"name"`
+	Enforce string `json:"enforce,omitempty"`
+	Audit   string `json:"audit,omitempty"`
+	Warn    string `json:"warn,omitempty"`
+}
+
+func (n NamespacePodSecurity) levels() map[string]string {
+	return map[string]string{"enforce": n.Enforce, "audit": n.Audit, "warn": n.Warn}
+}
+
+// runPodSecurity reports the Pod Security Admission levels of the namespaces of the workload
+// cluster, compares them with the ones declared in the suite manifest and checks that a
+// namespace enforcing the restricted level denies a non-compliant Pod.
+func runPodSecurity(env *state.Environment, cfg *TestConfig) {
+	Context("pod security admission", Ordered, func() {
+		var wcClient *client.Client
+
+		BeforeEach(func() {
+			helper.SetResponsibleTeam(helper.TeamShield)
+
+			var err error
+
+			wcClient, err = env.WC()
+			if err != nil {
+				Fail(err.Error())
+			}
+		})
+
+		It("has the expected Pod Security Admission levels on its namespaces", func() {
+			namespaces := &corev1.NamespaceList{}
+			Expect(wcClient.List(env.Context(), namespaces)).To(Succeed())
+
+			report := podSecurityReport(namespaces.Items)
+			logger.Log("Pod Security Admission levels:\n%s", report)
+			AddReportEntry("POD_SECURITY_LEVELS", report)
+
+			if len(cfg.PodSecurity.Namespaces) == 0 {
+				Fail("No Pod Security Admission levels are declared in the podSecurity section of the suite manifest")
+			}
+
+			drift := podSecurityDrift(namespaces.Items, cfg.PodSecurity)
+			Expect(drift).To(BeEmpty(), "Pod Security Admission labels differ from the suite manifest:\n%s", strings.Join(drift, "\n"))
+		})
+
+		It("enforces the restricted level in a user namespace", func() {
+			namespace := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: podSecurityTestNamespace,
+					Labels: map[string]string{
+						podSecurityLabelPrefix + "enforce":         podSecurityRestricted,
+						podSecurityLabelPrefix + "enforce-version": "latest",
+					},
+				},
+			}
+			Eventually(func() error {
+				return createOrUpdate(env.Context(), wcClient, namespace)
+			}).
+				WithTimeout(env.Timeout(timeout.ResourceApply)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+
+			compliant := baselinePolicyPod("compliant")
+			compliant.Namespace = podSecurityTestNamespace
+			Eventually(checkPodAdmitted(env.Context(), wcClient, compliant)).
+				WithTimeout(env.Timeout(timeout.PolicyEnforcement)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+
+			violating := violatingPolicyPod(policyCaseByName("root-user"))
+			violating.Namespace = podSecurityTestNamespace
+			Eventually(checkPodSecurityDenied(env.Context(), wcClient, violating, podSecurityRestricted)).
+				WithTimeout(env.Timeout(timeout.PolicyEnforcement)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+		})
+
+		AfterAll(func() {
+			wc, err := env.WC()
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func() error {
+				logger.Log("Deleting Namespace '%s'", podSecurityTestNamespace)
+				err := wc.Delete(env.Context(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: podSecurityTestNamespace}})
+				if err != nil && !apierror.IsNotFound(err) {
+					logger.Log("Failed to delete Namespace '%s' - %v", podSecurityTestNamespace, err)
+					return err
+				}
+				return nil
+			}).
+				WithTimeout(env.Timeout(timeout.ResourceApply)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+		})
+	})
+}
+
+// checkPodSecurityDenied returns a function that succeeds once a dry-run create of pod is
+// denied by the Pod Security Admission controller for violating level.
+func checkPodSecurityDenied(ctx context.Context, wcClient *client.Client, pod *corev1.Pod, level string) func() error {
+	return func() error {
+		logger.Log("Creating Pod '%s' with a dry run, expecting it to be denied by Pod Security Admission", pod.Name)
+		err := wcClient.Create(ctx, pod.DeepCopy(), cr.DryRunAll)
+		if err == nil {
+			return fmt.Errorf("pod %s was admitted although it violates the %s Pod Security Standard", pod.Name, level)
+		}
+		if !apierror.IsForbidden(err) || !strings.Contains(err.Error(), fmt.Sprintf("violates PodSecurity %q", level+":latest")) {
+			return fmt.Errorf("pod %s was not denied by Pod Security Admission: %w", pod.Name, err)
+		}
+		return nil
+	}
+}
+
+// podSecurityLevel returns the level labelled on namespace for mode, or an empty string.
+func podSecurityLevel(namespace corev1.Namespace, mode string) string {
+	return namespace.Labels[podSecurityLabelPrefix+mode]
+}
+
+// podSecurityDrift lists the differences between the levels of the namespaces and the ones
+// declared in cfg. Declared namespaces that don't exist are reported too.
+func podSecurityDrift(namespaces []corev1.Namespace, cfg PodSecurityConfig) []string {
+	byName := map[string]corev1.Namespace{}
+	for _, namespace := range namespaces {
+		byName[namespace.Name] = namespace
+	}
+
+	drift := []string{}
+	for _, expected := range cfg.Namespaces {
+		namespace, ok := byName[expected.Name]
+		if !ok {
+			drift = append(drift, fmt.Sprintf("%s: namespace not found", expected.Name))
+			continue
+		}
+
+		levels := expected.levels()
+		for _, mode := range podSecurityModes {
+			if actual := podSecurityLevel(namespace, mode); actual != levels[mode] {
+				drift = append(drift, fmt.Sprintf("%s: %s is %s, expected %s", expected.Name, mode, levelOrUnset(actual), levelOrUnset(levels[mode])))
+			}
+		}
+	}
+	return drift
+}
+
+// podSecurityReport lists the levels of every namespace.
+func podSecurityReport(namespaces []corev1.Namespace) string {
+	sorted := append([]corev1.Namespace{}, namespaces...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	b := &strings.Builder{}
+	for _, namespace := range sorted {
+		levels := []string{}
+		for _, mode := range podSecurityModes {
+			levels = append(levels, fmt.Sprintf("%s=%s", mode, levelOrUnset(podSecurityLevel(namespace, mode))))
+		}
+		fmt.Fprintf(b, "%s: %s\n", namespace.Name, strings.Join(levels, " "))
+	}
+	return b.String()
+}
+
+func levelOrUnset(level string) string {
+	if level == "" {
+		return "unset"
+	}
+	return level
+}
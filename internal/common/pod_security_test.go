@@ -0,0 +1,79 @@
+package common
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodSecurityDrift(t *testing.T) {
+	namespace := func(name string, labels map[string]string) corev1.Namespace {
+		return corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	namespaces := []corev1.Namespace{
+		namespace("kube-system", map[string]string{
+			"pod-security.kubernetes.io/enforce": "privileged",
+			"pod-security.kubernetes.io/warn":    "baseline",
+		}),
+		namespace("default", nil),
+		namespace("giantswarm", map[string]string{"pod-security.kubernetes.io/enforce": "baseline"}),
+	}
+
+	drift := podSecurityDrift(namespaces, PodSecurityConfig{Namespaces: []NamespacePodSecurity{
+		{Name: "kube-system", Enforce: "privileged", Warn: "baseline"},
+		{Name: "default"},
+		{Name: "giantswarm", Enforce: "privileged", Audit: "restricted"},
+		{Name: "monitoring"},
+	}})
+
+	expected := []string{
+		"giantswarm: enforce is baseline, expected privileged",
+		"giantswarm: audit is unset, expected restricted",
+		"monitoring: namespace not found",
+	}
+	if !reflect.DeepEqual(drift, expected) {
+		t.Errorf("expected drift %v, got %v", expected, drift)
+	}
+
+	report := podSecurityReport(namespaces)
+	lines := strings.Split(strings.TrimSpace(report), "\n")
+	expectedLines := []string{
+		"default: enforce=unset audit=unset warn=unset",
+		"giantswarm: enforce=baseline audit=unset warn=unset",
+		"kube-system: enforce=privileged audit=unset warn=baseline",
+	}
+	if !reflect.DeepEqual(lines, expectedLines) {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+}
+
+func TestSuiteManifestPodSecurity(t *testing.T) {
+	manifest, err := parseSuiteManifest([]byte(`
+team: phoenix
+podSecurity:
+  namespaces:
+    - name: kube-system
+      enforce: privileged
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := PodSecurityConfig{Namespaces: []NamespacePodSecurity{{Name: "kube-system", Enforce: "privileged"}}}
+	if cfg := manifest.TestConfig(); !reflect.DeepEqual(cfg.PodSecurity, expected) {
+		t.Errorf("expected %+v, got %+v", expected, cfg.PodSecurity)
+	}
+
+	_, err = parseSuiteManifest([]byte(`
+team: phoenix
+podSecurity:
+  namespaces:
+    - name: kube-system
+      enforce: strict
+`))
+	if err == nil {
+		t.Error("expected an unknown level to be rejected")
+	}
+}
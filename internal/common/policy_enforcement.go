@@ -0,0 +1,337 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+	"github.com/giantswarm/clustertest/v5/pkg/wait"
+	corev1 "k8s.io/api/core/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+	. "github.com/onsi/gomega"    //nolint:staticcheck
+)
+
+const (
+	policyTestNamespace = "test-policy-enforcement"
+	// policyExceptionNamespace is the only namespace Kyverno accepts PolicyExceptions from.
+	policyExceptionNamespace = "policy-exceptions"
+	policyExceptionName      = "policy-enforcement-test"
+)
+
+// policyCase is a Kyverno policy rule of the security-bundle and a Pod violating it.
+type policyCase struct {
+	Name   string
+	Policy string
+	Rule   string
+	// Compliant turns the compliant baseline Pod into the counterpart of the violating Pod.
+	// Nil keeps the baseline as is.
+	Compliant func(*corev1.Pod)
+	// Violate turns the compliant baseline Pod into one the rule denies.
+	Violate func(*corev1.Pod)
+}
+
+// policyCases is the catalogue of policy rules the tests expect to be enforced.
+var policyCases = []policyCase{
+	{
+		Name:   "host-path",
+		Policy: "disallow-host-path",
+		Rule:   "host-path",
+		Compliant: func(pod *corev1.Pod) {
+			pod.Spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+		},
+		Violate: func(pod *corev1.Pod) {
+			pod.Spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/var/log"}}}}
+		},
+	},
+	{
+		Name:   "privileged",
+		Policy: "disallow-privileged-containers",
+		Rule:   "privileged-containers",
+		Violate: func(pod *corev1.Pod) {
+			t := true
+			// A privileged container can't disallow privilege escalation.
+			pod.Spec.Containers[0].SecurityContext.Privileged = &t
+			pod.Spec.Containers[0].SecurityContext.AllowPrivilegeEscalation = &t
+		},
+	},
+	{
+		Name:   "missing-seccomp",
+		Policy: "restrict-seccomp-strict",
+		Rule:   "check-seccomp-strict",
+		Violate: func(pod *corev1.Pod) {
+			pod.Spec.SecurityContext.SeccompProfile = nil
+		},
+	},
+	{
+		Name:   "root-user",
+		Policy: "require-run-as-nonroot",
+		Rule:   "run-as-non-root",
+		Violate: func(pod *corev1.Pod) {
+			f := false
+			root := int64(0)
+			pod.Spec.SecurityContext.RunAsNonRoot = &f
+			pod.Spec.SecurityContext.RunAsUser = &root
+		},
+	},
+}
+
+// exceptedPolicyCase is the case whose rule the PolicyException lifts. Its Pod violates no
+// other rule, so it is admitted once the exception is in place.
+const exceptedPolicyCase = "missing-seccomp"
+
+// runPolicyEnforcement checks that the Kyverno policies of the security-bundle deny
+// non-compliant Pods and that a PolicyException lifts only the rules it lists. The Pods are
+// created with a server-side dry run, so nothing is scheduled.
+func runPolicyEnforcement(env *state.Environment, cfg *TestConfig) {
+	Context("policy enforcement", Ordered, func() {
+		var wcClient *client.Client
+
+		BeforeAll(func() {
+			if !cfg.SecurityBundleInstalled {
+				skipUnsupported(cfg, CapabilitySecurityBundle, "security-bundle is not installed")
+			}
+
+			wc, err := env.WC()
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func() error {
+				return createOrUpdate(env.Context(), wc, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: policyTestNamespace}})
+			}).
+				WithTimeout(env.Timeout(timeout.ResourceApply)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+		})
+
+		BeforeEach(func() {
+			helper.SetResponsibleTeam(helper.TeamShield)
+
+			var err error
+
+			wcClient, err = env.WC()
+			if err != nil {
+				Fail(err.Error())
+			}
+		})
+
+		It("admits compliant Pods", func() {
+			for _, c := range policyCases {
+				Eventually(checkPodAdmitted(env.Context(), wcClient, compliantPolicyPod(c))).
+					WithTimeout(env.Timeout(timeout.PolicyEnforcement)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			}
+		})
+
+		It("denies non-compliant Pods naming the violated policy", func() {
+			for _, c := range policyCases {
+				Eventually(checkPodDenied(env.Context(), wcClient, violatingPolicyPod(c), c.Policy, c.Rule)).
+					WithTimeout(env.Timeout(timeout.PolicyEnforcement)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			}
+		})
+
+		It("lifts only the rule listed in a PolicyException", func() {
+			excepted := policyCaseByName(exceptedPolicyCase)
+			Expect(createOrUpdate(env.Context(), wcClient, policyException(excepted))).To(Succeed())
+
+			Eventually(checkPodAdmitted(env.Context(), wcClient, violatingPolicyPod(excepted))).
+				WithTimeout(env.Timeout(timeout.PolicyEnforcement)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+
+			for _, c := range policyCases {
+				if c.Name == excepted.Name {
+					continue
+				}
+				Eventually(checkPodDenied(env.Context(), wcClient, violatingPolicyPod(c), c.Policy, c.Rule)).
+					WithTimeout(env.Timeout(timeout.PolicyEnforcement)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			}
+		})
+
+		AfterAll(func() {
+			if !cfg.SecurityBundleInstalled {
+				return
+			}
+
+			wc, err := env.WC()
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, obj := range []cr.Object{
+				policyException(policyCaseByName(exceptedPolicyCase)),
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: policyTestNamespace}},
+			} {
+				Eventually(func() error {
+					logger.Log("Deleting %T '%s'", obj, obj.GetName())
+					err := wc.Delete(env.Context(), obj)
+					if err != nil && !apierror.IsNotFound(err) {
+						logger.Log("Failed to delete %T '%s' - %v", obj, obj.GetName(), err)
+						return err
+					}
+					return nil
+				}).
+					WithTimeout(env.Timeout(timeout.ResourceApply)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			}
+		})
+	})
+}
+
+// checkPodAdmitted returns a function that succeeds once a dry-run create of pod is admitted.
+func checkPodAdmitted(ctx context.Context, wcClient *client.Client, pod *corev1.Pod) func() error {
+	return func() error {
+		logger.Log("Creating Pod '%s' with a dry run, expecting it to be admitted", pod.Name)
+		err := wcClient.Create(ctx, pod.DeepCopy(), cr.DryRunAll)
+		if err != nil {
+			return fmt.Errorf("pod %s was not admitted: %w", pod.Name, err)
+		}
+		return nil
+	}
+}
+
+// checkPodDenied returns a function that succeeds once a dry-run create of pod is denied
+// by Kyverno, naming the given policy rule.
+func checkPodDenied(ctx context.Context, wcClient *client.Client, pod *corev1.Pod, policy, rule string) func() error {
+	return func() error {
+		logger.Log("Creating Pod '%s' with a dry run, expecting it to be denied by %s/%s", pod.Name, policy, rule)
+		err := wcClient.Create(ctx, pod.DeepCopy(), cr.DryRunAll)
+		if err == nil {
+			return fmt.Errorf("pod %s was admitted although it violates %s/%s", pod.Name, policy, rule)
+		}
+
+		denied := parseKyvernoDenial(err.Error())
+		if !slices.Contains(denied[policy], rule) {
+			return fmt.Errorf("pod %s was not denied by %s/%s: %w", pod.Name, policy, rule, err)
+		}
+		return nil
+	}
+}
+
+// parseKyvernoDenial returns the rules, by policy, listed in the message of a request denied
+// by the Kyverno admission webhook, e.g.:
+//
+//	resource Pod/default/test was blocked due to the following policies
+//
+//	disallow-host-path:
+//	  host-path: 'validation error: HostPath volumes are forbidden. [...]'
+func parseKyvernoDenial(message string) map[string][]string {
+	denied := map[string][]string{}
+	policy := ""
+	for _, line := range strings.Split(message, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+		case !strings.HasPrefix(line, " ") && strings.HasSuffix(trimmed, ":") && !strings.Contains(trimmed, " "):
+			policy = strings.TrimSuffix(trimmed, ":")
+		case policy != "" && strings.HasPrefix(line, "  ") && !strings.HasPrefix(line, "   "):
+			if rule, _, ok := strings.Cut(trimmed, ":"); ok {
+				denied[policy] = append(denied[policy], rule)
+			}
+		}
+	}
+	return denied
+}
+
+func policyCaseByName(name string) policyCase {
+	for _, c := range policyCases {
+		if c.Name == name {
+			return c
+		}
+	}
+	panic(fmt.Sprintf("unknown policy case %q", name))
+}
+
+func compliantPolicyPod(c policyCase) *corev1.Pod {
+	pod := baselinePolicyPod(fmt.Sprintf("compliant-%s", c.Name))
+	if c.Compliant != nil {
+		c.Compliant(pod)
+	}
+	return pod
+}
+
+func violatingPolicyPod(c policyCase) *corev1.Pod {
+	pod := baselinePolicyPod(fmt.Sprintf("violating-%s", c.Name))
+	c.Violate(pod)
+	return pod
+}
+
+// baselinePolicyPod returns a Pod compliant with the restricted Pod Security Standard.
+func baselinePolicyPod(name string) *corev1.Pod {
+	t := true
+	f := false
+	user := int64(1001)
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: policyTestNamespace,
+		},
+		Spec: corev1.PodSpec{
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsUser:    &user,
+				RunAsNonRoot: &t,
+				SeccompProfile: &corev1.SeccompProfile{
+					Type: corev1.SeccompProfileTypeRuntimeDefault,
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:    "test",
+					Image:   "gsoci.azurecr.io/giantswarm/alpine:latest",
+					Command: []string{"sleep", "99999999"},
+					SecurityContext: &corev1.SecurityContext{
+						Capabilities: &corev1.Capabilities{
+							Drop: []corev1.Capability{"ALL"},
+						},
+						AllowPrivilegeEscalation: &f,
+					},
+				},
+			},
+		},
+	}
+}
+
+// policyException lifts the rule of c for the Pods of the test namespace.
+func policyException(c policyCase) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "kyverno.io/v2",
+			"kind":       "PolicyException",
+			"metadata": map[string]interface{}{
+				"name":      policyExceptionName,
+				"namespace": policyExceptionNamespace,
+			},
+			"spec": map[string]interface{}{
+				"exceptions": []interface{}{
+					map[string]interface{}{"policyName": c.Policy, "ruleNames": []interface{}{c.Rule}},
+				},
+				"match": map[string]interface{}{
+					"any": []interface{}{
+						map[string]interface{}{
+							"resources": map[string]interface{}{
+								"kinds":      []interface{}{"Pod"},
+								"namespaces": []interface{}{policyTestNamespace},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
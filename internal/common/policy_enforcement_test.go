@@ -0,0 +1,55 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+func TestParseKyvernoDenial(t *testing.T) {
+	message := `admission webhook "validate.kyverno.svc-fail" denied the request:
+
+resource Pod/test-policy-enforcement/violating-host-path was blocked due to the following policies
+
+disallow-host-path:
+  host-path: 'validation error: HostPath volumes are forbidden. The field spec.volumes[*].hostPath
+    must be unset. rule host-path failed at path /spec/volumes/0/hostPath/'
+restrict-volume-types:
+  restricted-volumes: 'validation error: Only the following types of volumes may
+    be used: configMap, csi, downwardAPI, emptyDir, ephemeral, persistentVolumeClaim,
+    projected, and secret. rule restricted-volumes failed at path /spec/volumes/0/'
+`
+
+	expected := map[string][]string{
+		"disallow-host-path":    {"host-path"},
+		"restrict-volume-types": {"restricted-volumes"},
+	}
+	if denied := parseKyvernoDenial(message); !reflect.DeepEqual(denied, expected) {
+		t.Errorf("expected %v, got %v", expected, denied)
+	}
+
+	if denied := parseKyvernoDenial(`pods "test" is forbidden: exceeded quota`); len(denied) != 0 {
+		t.Errorf("expected no policies for a non-Kyverno error, got %v", denied)
+	}
+}
+
+func TestPolicyCasePods(t *testing.T) {
+	for _, c := range policyCases {
+		compliant := compliantPolicyPod(c)
+		violating := violatingPolicyPod(c)
+		violating.Name = compliant.Name
+		if equality.Semantic.DeepEqual(compliant, violating) {
+			t.Errorf("%s: expected the violating Pod to differ from the compliant one", c.Name)
+		}
+	}
+
+	// The shared baseline must not be changed by the cases.
+	if pod := baselinePolicyPod("test"); pod.Spec.SecurityContext.SeccompProfile == nil || *pod.Spec.SecurityContext.RunAsUser == 0 {
+		t.Errorf("expected the baseline Pod to be compliant, got %+v", pod.Spec.SecurityContext)
+	}
+
+	if c := policyCaseByName(exceptedPolicyCase); c.Name != exceptedPolicyCase {
+		t.Errorf("expected the excepted case to be in the catalogue")
+	}
+}
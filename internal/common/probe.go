@@ -0,0 +1,164 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	probeName      = "probe"
+	probeContainer = "probe"
+	probePort      = 8080
+	probeImage     = "gsoci.azurecr.io/giantswarm/nginx-unprivileged:1.31-alpine"
+)
+
+var probeLabels = map[string]string{"app.kubernetes.io/name": probeName}
+
+// probeDaemonSet runs an unprivileged nginx, serving on probePort, on every node of the
+// cluster including the control plane nodes. Its image ships busybox wget and nslookup,
+// so the probe pods can be used as the client of in-cluster checks with ExecInPod.
+func probeDaemonSet(namespace string) *appsv1.DaemonSet {
+	t := true
+	f := false
+	user := int64(101)
+
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      probeName,
+			Namespace: namespace,
+			Labels:    probeLabels,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: probeLabels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: probeLabels},
+				Spec: corev1.PodSpec{
+					// Tolerate every taint so the control plane nodes get a probe pod too.
+					Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:    &user,
+						RunAsNonRoot: &t,
+						SeccompProfile: &corev1.SeccompProfile{
+							Type: corev1.SeccompProfileTypeRuntimeDefault,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:  probeContainer,
+							Image: probeImage,
+							Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: probePort}},
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									HTTPGet: &corev1.HTTPGetAction{Path: "/", Port: intstr.FromInt32(probePort)},
+								},
+							},
+							SecurityContext: &corev1.SecurityContext{
+								Capabilities: &corev1.Capabilities{
+									Drop: []corev1.Capability{"ALL"},
+								},
+								AllowPrivilegeEscalation: &f,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// probeService returns a Service selecting the probe pods.
+func probeService(name, namespace string, serviceType corev1.ServiceType, headless bool) *corev1.Service {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.ServiceSpec{
+			Type:     serviceType,
+			Selector: probeLabels,
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: probePort, TargetPort: intstr.FromInt32(probePort)},
+			},
+		},
+	}
+	if headless {
+		svc.Spec.ClusterIP = corev1.ClusterIPNone
+	}
+	return svc
+}
+
+// ensureProbe creates the namespace and the probe DaemonSet, leaving existing ones untouched.
+func ensureProbe(ctx context.Context, wcClient *client.Client, namespace string) error {
+	for _, obj := range []cr.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}},
+		probeDaemonSet(namespace),
+	} {
+		logger.Log("Creating %T '%s'", obj, obj.GetName())
+		err := wcClient.Create(ctx, obj)
+		if err != nil && !apierror.IsAlreadyExists(err) {
+			logger.Log("Failed to create %T '%s' - %v", obj, obj.GetName(), err)
+			return err
+		}
+	}
+	return nil
+}
+
+// checkProbeReady returns a function that succeeds once a ready probe pod runs on every node.
+func checkProbeReady(ctx context.Context, wcClient *client.Client, namespace string) func() error {
+	return func() error {
+		nodes := &corev1.NodeList{}
+		if err := wcClient.List(ctx, nodes); err != nil {
+			return err
+		}
+
+		ds := &appsv1.DaemonSet{}
+		if err := wcClient.Get(ctx, cr.ObjectKey{Name: probeName, Namespace: namespace}, ds); err != nil {
+			return err
+		}
+
+		logger.Log("Probe DaemonSet has %d/%d ready pods on %d nodes", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled, len(nodes.Items))
+		if int(ds.Status.NumberReady) != len(nodes.Items) || ds.Status.NumberReady != ds.Status.DesiredNumberScheduled {
+			return fmt.Errorf("probe DaemonSet has %d ready pods, expected one on each of the %d nodes", ds.Status.NumberReady, len(nodes.Items))
+		}
+		return nil
+	}
+}
+
+// listProbePods returns the running probe pods, sorted by node name.
+func listProbePods(ctx context.Context, wcClient *client.Client, namespace string) ([]corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	err := wcClient.List(ctx, pods, cr.InNamespace(namespace), cr.MatchingLabels(probeLabels))
+	if err != nil {
+		return nil, err
+	}
+
+	running := []corev1.Pod{}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.Status.PodIP != "" {
+			running = append(running, pod)
+		}
+	}
+	if len(running) == 0 {
+		return nil, fmt.Errorf("no running probe pods found in namespace %s", namespace)
+	}
+
+	sort.Slice(running, func(i, j int) bool { return running[i].Spec.NodeName < running[j].Spec.NodeName })
+	return running, nil
+}
+
+// probeGet fetches url from the given probe pod.
+func probeGet(ctx context.Context, wcClient *client.Client, pod corev1.Pod, url string) error {
+	cmd := []string{"wget", "-q", "-O", "/dev/null", "-T", "3", url}
+	_, stderr, err := wcClient.ExecInPod(ctx, pod.Name, pod.Namespace, probeContainer, cmd)
+	if err != nil {
+		return fmt.Errorf("%s: %w (stderr: %q)", url, err, stderr)
+	}
+	return nil
+}
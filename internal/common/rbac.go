@@ -0,0 +1,214 @@
+package common
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+	. "github.com/onsi/gomega"    //nolint:staticcheck
+)
+
+const (
+	clusterAdminRole = "cluster-admin"
+
+	// unprivilegedTestUser is a user no binding refers to, so it only has the permissions
+	// granted to every authenticated user.
+	unprivilegedTestUser = "e2e-unprivileged-user"
+)
+
+// broadSubjects are the groups and users that must never be granted cluster-admin, as they
+// match every (authenticated) client of the API server.
+var broadSubjects = []string{"system:authenticated", "system:unauthenticated", "system:anonymous"}
+
+// RBACConfig declares the RBAC a suite expects. It is set from the rbac section of the suite manifest.
+type RBACConfig struct {
+	// ClusterAdminBindings are the ClusterRoleBindings allowed to grant cluster-admin to a
+	// ServiceAccount. When nil, the bindings to ServiceAccounts are only reported. Bindings
+	// granting cluster-admin to every authenticated or anonymous client are never allowed.
+	ClusterAdminBindings []ClusterAdminBinding `json:"clusterAdminBindings,omitempty"`
+	// AutomountDisabledNamespaces are the namespaces whose default ServiceAccount must not
+	// automount its token.
+	AutomountDisabledNamespaces []string `json:"automountDisabledNamespaces,omitempty"`
+}
+
+// ClusterAdminBinding allows a ClusterRoleBinding to grant cluster-admin to a ServiceAccount.
+type ClusterAdminBinding struct {
+	Name string `json:"name"`
+	// ServiceAccount is the subject of the binding, as namespace/name.
+	ServiceAccount string `json:"serviceAccount"`
+}
+
+// runRBAC checks that cluster-admin is only granted by the expected ClusterRoleBindings,
+// that the default ServiceAccounts don't automount their token where the release disables
+// it, and that an unprivileged user can't read Secrets.
+func runRBAC(env *state.Environment, cfg *TestConfig) {
+	Context("rbac", func() {
+		var wcClient *client.Client
+
+		BeforeEach(func() {
+			helper.SetResponsibleTeam(helper.TeamShield)
+
+			var err error
+
+			wcClient, err = env.WC()
+			if err != nil {
+				Fail(err.Error())
+			}
+		})
+
+		It("has no unexpected ClusterRoleBindings granting cluster-admin", func() {
+			bindings := &rbacv1.ClusterRoleBindingList{}
+			Expect(wcClient.List(env.Context(), bindings)).To(Succeed())
+
+			clusterAdmin := clusterAdminBindings(bindings.Items)
+			logger.Log("ClusterRoleBindings granting cluster-admin to ServiceAccounts or broad groups:\n%s", strings.Join(clusterAdmin, "\n"))
+
+			if cfg.RBAC.ClusterAdminBindings == nil {
+				logger.Log("The suite manifest doesn't declare the allowed cluster-admin ClusterRoleBindings, only checking for broad subjects")
+			}
+			unexpected := unexpectedClusterAdminBindings(bindings.Items, cfg.RBAC.ClusterAdminBindings)
+			Expect(unexpected).To(BeEmpty(), "unexpected ClusterRoleBindings grant cluster-admin:\n%s", strings.Join(unexpected, "\n"))
+		})
+
+		It("has default ServiceAccounts that don't automount their token", func() {
+			if len(cfg.RBAC.AutomountDisabledNamespaces) == 0 {
+				Skip("No namespaces with automount disabled are declared in the suite manifest")
+			}
+
+			serviceAccounts := []corev1.ServiceAccount{}
+			for _, namespace := range cfg.RBAC.AutomountDisabledNamespaces {
+				serviceAccount := corev1.ServiceAccount{}
+				Expect(wcClient.Get(env.Context(), cr.ObjectKey{Name: "default", Namespace: namespace}, &serviceAccount)).To(Succeed())
+				serviceAccounts = append(serviceAccounts, serviceAccount)
+			}
+
+			automounting := automountingServiceAccounts(serviceAccounts)
+			Expect(automounting).To(BeEmpty(), "default ServiceAccounts automount their token: %s", strings.Join(automounting, ", "))
+		})
+
+		It("denies an unprivileged user access to Secrets", func() {
+			for _, namespace := range []string{metav1.NamespaceAll, metav1.NamespaceSystem, metav1.NamespaceDefault} {
+				for _, verb := range []string{"get", "list", "watch"} {
+					review := &authorizationv1.SubjectAccessReview{
+						Spec: authorizationv1.SubjectAccessReviewSpec{
+							User:   unprivilegedTestUser,
+							Groups: []string{"system:authenticated"},
+							ResourceAttributes: &authorizationv1.ResourceAttributes{
+								Namespace: namespace,
+								Verb:      verb,
+								Resource:  "secrets",
+							},
+						},
+					}
+					Expect(wcClient.Create(env.Context(), review)).To(Succeed())
+
+					scope := namespace
+					if scope == metav1.NamespaceAll {
+						scope = "all namespaces"
+					}
+					logger.Log("SubjectAccessReview for %s to %s secrets in %s: allowed=%t %s", unprivilegedTestUser, verb, scope, review.Status.Allowed, review.Status.Reason)
+					Expect(review.Status.Allowed).To(BeFalse(), "%s is allowed to %s secrets in %s: %s", unprivilegedTestUser, verb, scope, review.Status.Reason)
+				}
+			}
+		})
+	})
+}
+
+// clusterAdminBindings describes the ClusterRoleBindings granting cluster-admin to
+// ServiceAccounts or broad subjects.
+func clusterAdminBindings(bindings []rbacv1.ClusterRoleBinding) []string {
+	result := []string{}
+	for _, binding := range bindings {
+		if subjects := clusterAdminSubjects(binding); len(subjects) > 0 {
+			result = append(result, fmt.Sprintf("%s: %s", binding.Name, strings.Join(subjects, ", ")))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// unexpectedClusterAdminBindings describes the ClusterRoleBindings granting cluster-admin to
+// ServiceAccounts they aren't allowed to, and all that grant it to broad subjects. When
+// allowed is nil, any ServiceAccount is.
+func unexpectedClusterAdminBindings(bindings []rbacv1.ClusterRoleBinding, allowed []ClusterAdminBinding) []string {
+	result := []string{}
+	for _, binding := range bindings {
+		if len(clusterAdminSubjects(binding)) == 0 {
+			continue
+		}
+
+		unexpected := []string{}
+		for _, subject := range binding.Subjects {
+			switch {
+			case isBroadSubject(subject):
+				unexpected = append(unexpected, subjectString(subject))
+			case subject.Kind == rbacv1.ServiceAccountKind && allowed != nil && !isAllowedClusterAdmin(binding.Name, subject, allowed):
+				unexpected = append(unexpected, subjectString(subject))
+			}
+		}
+		if len(unexpected) > 0 {
+			result = append(result, fmt.Sprintf("%s: %s", binding.Name, strings.Join(unexpected, ", ")))
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func isAllowedClusterAdmin(bindingName string, subject rbacv1.Subject, allowed []ClusterAdminBinding) bool {
+	serviceAccount := fmt.Sprintf("%s/%s", subject.Namespace, subject.Name)
+	return slices.ContainsFunc(allowed, func(a ClusterAdminBinding) bool {
+		return a.Name == bindingName && a.ServiceAccount == serviceAccount
+	})
+}
+
+// clusterAdminSubjects returns the ServiceAccounts and broad subjects binding grants
+// cluster-admin to.
+func clusterAdminSubjects(binding rbacv1.ClusterRoleBinding) []string {
+	if binding.RoleRef.Kind != "ClusterRole" || binding.RoleRef.Name != clusterAdminRole {
+		return nil
+	}
+
+	subjects := []string{}
+	for _, subject := range binding.Subjects {
+		if subject.Kind == rbacv1.ServiceAccountKind || isBroadSubject(subject) {
+			subjects = append(subjects, subjectString(subject))
+		}
+	}
+	return subjects
+}
+
+func isBroadSubject(subject rbacv1.Subject) bool {
+	return subject.Kind != rbacv1.ServiceAccountKind && slices.Contains(broadSubjects, subject.Name)
+}
+
+func subjectString(subject rbacv1.Subject) string {
+	if subject.Namespace != "" {
+		return fmt.Sprintf("%s %s/%s", subject.Kind, subject.Namespace, subject.Name)
+	}
+	return fmt.Sprintf("%s %s", subject.Kind, subject.Name)
+}
+
+// automountingServiceAccounts returns the ServiceAccounts that automount their token.
+// Automounting is enabled unless it is explicitly disabled.
+func automountingServiceAccounts(serviceAccounts []corev1.ServiceAccount) []string {
+	result := []string{}
+	for _, serviceAccount := range serviceAccounts {
+		if serviceAccount.AutomountServiceAccountToken == nil || *serviceAccount.AutomountServiceAccountToken {
+			result = append(result, fmt.Sprintf("%s/%s", serviceAccount.Namespace, serviceAccount.Name))
+		}
+	}
+	return result
+}
@@ -0,0 +1,102 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUnexpectedClusterAdminBindings(t *testing.T) {
+	binding := func(name, role string, subjects ...rbacv1.Subject) rbacv1.ClusterRoleBinding {
+		return rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: role},
+			Subjects:   subjects,
+		}
+	}
+	serviceAccount := func(namespace, name string) rbacv1.Subject {
+		return rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: namespace, Name: name}
+	}
+	group := func(name string) rbacv1.Subject {
+		return rbacv1.Subject{Kind: rbacv1.GroupKind, APIGroup: rbacv1.GroupName, Name: name}
+	}
+
+	bindings := []rbacv1.ClusterRoleBinding{
+		binding("cluster-admin", clusterAdminRole, group("system:masters")),
+		binding("chart-operator", clusterAdminRole, serviceAccount("giantswarm", "chart-operator")),
+		binding("debug", clusterAdminRole, serviceAccount("default", "debug")),
+		binding("oops", clusterAdminRole, group("system:authenticated")),
+		binding("shared", clusterAdminRole, serviceAccount("giantswarm", "chart-operator"), serviceAccount("default", "debug")),
+		binding("viewers", "view", group("system:authenticated")),
+	}
+
+	if reported := clusterAdminBindings(bindings); !reflect.DeepEqual(reported, []string{
+		"chart-operator: ServiceAccount giantswarm/chart-operator",
+		"debug: ServiceAccount default/debug",
+		"oops: Group system:authenticated",
+		"shared: ServiceAccount giantswarm/chart-operator, ServiceAccount default/debug",
+	}) {
+		t.Errorf("unexpected cluster-admin bindings: %v", reported)
+	}
+
+	testCases := []struct {
+		name     string
+		allowed  []ClusterAdminBinding
+		expected []string
+	}{
+		{
+			name:     "no allow-list",
+			expected: []string{"oops: Group system:authenticated"},
+		},
+		{
+			name:     "empty allow-list",
+			allowed:  []ClusterAdminBinding{},
+			expected: []string{"chart-operator: ServiceAccount giantswarm/chart-operator", "debug: ServiceAccount default/debug", "oops: Group system:authenticated", "shared: ServiceAccount giantswarm/chart-operator, ServiceAccount default/debug"},
+		},
+		{
+			name: "allow-list",
+			allowed: []ClusterAdminBinding{
+				{Name: "chart-operator", ServiceAccount: "giantswarm/chart-operator"},
+				{Name: "shared", ServiceAccount: "giantswarm/chart-operator"},
+			},
+			expected: []string{"debug: ServiceAccount default/debug", "oops: Group system:authenticated", "shared: ServiceAccount default/debug"},
+		},
+		{
+			name: "allowed binding with another subject",
+			allowed: []ClusterAdminBinding{
+				{Name: "chart-operator", ServiceAccount: "default/debug"},
+				{Name: "debug", ServiceAccount: "default/debug"},
+				{Name: "shared", ServiceAccount: "default/debug"},
+			},
+			expected: []string{"chart-operator: ServiceAccount giantswarm/chart-operator", "oops: Group system:authenticated", "shared: ServiceAccount giantswarm/chart-operator"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if unexpected := unexpectedClusterAdminBindings(bindings, tc.allowed); !reflect.DeepEqual(unexpected, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, unexpected)
+			}
+		})
+	}
+}
+
+func TestAutomountingServiceAccounts(t *testing.T) {
+	f := false
+	tr := true
+	serviceAccount := func(namespace string, automount *bool) corev1.ServiceAccount {
+		return corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: namespace}, AutomountServiceAccountToken: automount}
+	}
+
+	automounting := automountingServiceAccounts([]corev1.ServiceAccount{
+		serviceAccount("default", &f),
+		serviceAccount("kube-system", nil),
+		serviceAccount("giantswarm", &tr),
+	})
+	if expected := []string{"kube-system/default", "giantswarm/default"}; !reflect.DeepEqual(automounting, expected) {
+		t.Errorf("expected %v, got %v", expected, automounting)
+	}
+}
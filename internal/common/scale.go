@@ -1,7 +1,9 @@
 package common
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	helmv2 "github.com/fluxcd/helm-controller/api/v2"
@@ -12,60 +14,65 @@ import (
 	. "github.com/onsi/gomega"    //nolint:staticcheck
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
 	cr "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
 	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
 )
 
-func runScale(autoScalingSupported bool) {
-	Context("scale", func() {
+func runScale(env *state.Environment, cfg *TestConfig) {
+	Context("scale", Ordered, func() {
 		var (
 			helmRelease  *helmv2.HelmRelease
 			ociRepoName  string
 			wcClient     *client.Client
 			replicaCount int
+			// initialNodes are the worker nodes before the scale up and scaledUpNodes the ones
+			// after it, with the Machine backing each node, if any.
+			initialNodes  map[string]string
+			scaledUpNodes map[string]string
 		)
 
-		BeforeEach(func() {
-			if !autoScalingSupported {
-				Skip("autoscaling is not supported")
+		BeforeAll(func() {
+			if !cfg.AutoScalingSupported {
+				skipUnsupported(cfg, CapabilityAutoScaling, "autoscaling is not supported")
 			}
 
-			helper.SetResponsibleTeam(helper.TeamTenet)
-
 			var err error
 
-			ctx := state.GetContext()
+			ctx := env.Context()
 
 			// Building the WC client can transiently fail; retry so a blip
 			// doesn't fail the spec.
 			Eventually(func() error {
-				wcClient, err = state.GetFramework().WC(state.GetCluster().Name)
+				wcClient, err = env.WC()
 				return err
 			}).
-				WithTimeout(1 * time.Minute).
+				WithTimeout(env.Timeout(timeout.ClientSetup)).
 				WithPolling(5 * time.Second).
 				Should(Succeed())
 
-			org := state.GetCluster().Organization
-			clusterName := state.GetCluster().Name
+			org := env.Cluster().Organization
+			clusterName := env.Cluster().Name
 			namespace := org.GetNamespace()
 
-			// Get the current number of worker nodes and set the replicas to one more to force scale up.
-			// The List call can transiently fail against a busy MC; retry it.
-			nodes := corev1.NodeList{}
+			// Get the current worker nodes and set the replicas to one more to force scale up.
+			// The List calls can transiently fail against a busy MC; retry them.
 			Eventually(func() error {
-				return wcClient.List(ctx, &nodes, client.DoesNotHaveLabels{"node-role.kubernetes.io/control-plane"})
+				initialNodes, err = workerNodeMachines(ctx, wcClient, env.MC(), clusterName, namespace)
+				return err
 			}).
-				WithTimeout(1 * time.Minute).
+				WithTimeout(env.Timeout(timeout.ClientSetup)).
 				WithPolling(5 * time.Second).
 				Should(Succeed())
 
-			replicaCount = len(nodes.Items) + 1
+			replicaCount = len(initialNodes) + 1
 
 			ociRepoName = fmt.Sprintf("%s-hello-world-chart", clusterName)
-			err = helmrelease.EnsureOCIRepository(ctx, state.GetFramework().MC(), ociRepoName, namespace, "hello-world")
+			err = helmrelease.EnsureOCIRepository(ctx, env.MC(), ociRepoName, namespace, "hello-world")
 			Expect(err).To(BeNil())
 
 			hrBuilder, err := helmrelease.New(
@@ -87,21 +94,30 @@ func runScale(autoScalingSupported bool) {
 			helmRelease, err = hrBuilder.Build()
 			Expect(err).To(BeNil())
 
-			err = state.GetFramework().MC().Create(ctx, helmRelease)
+			err = env.MC().Create(ctx, helmRelease)
 			Expect(err).To(BeNil())
 
-			Eventually(helmrelease.IsHelmReleaseReady(ctx, state.GetFramework().MC(), helmRelease.GetName(), helmRelease.GetNamespace())).
-				WithTimeout(5 * time.Minute).
+			Eventually(helmrelease.IsHelmReleaseReady(ctx, env.MC(), helmRelease.GetName(), helmRelease.GetNamespace())).
+				WithTimeout(env.Timeout(timeout.ScaleAppReady)).
 				WithPolling(5 * time.Second).
 				Should(BeTrue())
 		})
 
-		It("scales node by creating anti-affinity pods", func() {
-			if !autoScalingSupported {
-				Skip("autoscaling is not supported")
-			}
+		BeforeEach(func() {
+			helper.SetResponsibleTeam(helper.TeamTenet)
+
+			Eventually(func() error {
+				var err error
+				wcClient, err = env.WC()
+				return err
+			}).
+				WithTimeout(env.Timeout(timeout.ClientSetup)).
+				WithPolling(5 * time.Second).
+				Should(Succeed())
+		})
 
-			ctx := state.GetContext()
+		It("scales node by creating anti-affinity pods", func() {
+			ctx := env.Context()
 
 			expectedReplicas := fmt.Sprintf("%d", replicaCount)
 			Eventually(func() (bool, error) {
@@ -156,22 +172,115 @@ func runScale(autoScalingSupported bool) {
 
 				return false, nil
 			}).
-				WithTimeout(15 * time.Minute).
+				WithTimeout(env.Timeout(timeout.ScaleUp)).
 				WithPolling(10 * time.Second).
 				Should(BeTrue())
+
+			var err error
+			scaledUpNodes, err = workerNodeMachines(ctx, wcClient, env.MC(), env.Cluster().Name, env.Cluster().Organization.GetNamespace())
+			Expect(err).To(BeNil())
+			logger.Log("Scaled up from %d to %d worker nodes", len(initialNodes), len(scaledUpNodes))
+		})
+
+		It("scales down once the anti-affinity pods are removed", func() {
+			ctx := env.Context()
+
+			logger.Log("Deleting HelmRelease '%s' to remove the anti-affinity pods", helmRelease.GetName())
+			err := env.MC().Delete(ctx, helmRelease)
+			if err != nil && !apierror.IsNotFound(err) {
+				Expect(err).ShouldNot(HaveOccurred())
+			}
+
+			// The cluster-autoscaler only removes nodes after they have been unneeded for its
+			// scale-down delay, so this takes a while even when everything works.
+			var currentNodes map[string]string
+			Eventually(func() (int, error) {
+				var err error
+				currentNodes, err = workerNodeMachines(ctx, wcClient, env.MC(), env.Cluster().Name, env.Cluster().Organization.GetNamespace())
+				if err != nil {
+					return 0, err
+				}
+				logger.Log("Checking for decreased worker nodes. Expected: %d, Actual: %d", len(initialNodes), len(currentNodes))
+				return len(currentNodes), nil
+			}).
+				WithTimeout(env.Timeout(timeout.ScaleDown)).
+				WithPolling(30 * time.Second).
+				Should(Equal(len(initialNodes)))
+
+			removedMachines := []string{}
+			for node, machine := range scaledUpNodes {
+				if _, ok := currentNodes[node]; ok {
+					continue
+				}
+				if machine == "" {
+					logger.Log("Node '%s' was removed, it has no Machine", node)
+					continue
+				}
+				logger.Log("Node '%s' was removed, expecting its Machine '%s' to be deleted", node, machine)
+				removedMachines = append(removedMachines, machine)
+			}
+
+			Eventually(func() ([]string, error) {
+				machines := &capi.MachineList{}
+				err := env.MC().List(ctx, machines, cr.InNamespace(env.Cluster().Organization.GetNamespace()), cr.MatchingLabels{capi.ClusterNameLabel: env.Cluster().Name})
+				if err != nil {
+					return nil, err
+				}
+				remaining := []string{}
+				for _, machine := range machines.Items {
+					if slices.Contains(removedMachines, machine.Name) {
+						remaining = append(remaining, machine.Name)
+					}
+				}
+				return remaining, nil
+			}).
+				WithTimeout(env.Timeout(timeout.ScaleDown)).
+				WithPolling(10 * time.Second).
+				Should(BeEmpty())
 		})
 
-		AfterEach(func() {
-			if !autoScalingSupported {
-				Skip("autoscaling is not supported")
+		AfterAll(func() {
+			if !cfg.AutoScalingSupported {
+				return
 			}
 
-			ctx := state.GetContext()
-			err := state.GetFramework().MC().Delete(ctx, helmRelease)
-			Expect(err).ShouldNot(HaveOccurred())
+			ctx := env.Context()
+			err := env.MC().Delete(ctx, helmRelease)
+			if err != nil && !apierror.IsNotFound(err) {
+				Expect(err).ShouldNot(HaveOccurred())
+			}
 
-			err = helmrelease.DeleteOCIRepository(ctx, state.GetFramework().MC(), ociRepoName, helmRelease.GetNamespace())
-			Expect(err).ShouldNot(HaveOccurred())
+			err = helmrelease.DeleteOCIRepository(ctx, env.MC(), ociRepoName, helmRelease.GetNamespace())
+			if err != nil && !apierror.IsNotFound(err) {
+				Expect(err).ShouldNot(HaveOccurred())
+			}
 		})
 	})
 }
+
+// workerNodeMachines returns the worker nodes of the workload cluster by name, with the name
+// of the Machine backing each of them. Nodes without a Machine, e.g. of MachinePools that
+// don't create Machines, have an empty one.
+func workerNodeMachines(ctx context.Context, wcClient *client.Client, mcClient *client.Client, clusterName, namespace string) (map[string]string, error) {
+	nodes := corev1.NodeList{}
+	if err := wcClient.List(ctx, &nodes, client.DoesNotHaveLabels{"node-role.kubernetes.io/control-plane"}); err != nil {
+		return nil, err
+	}
+
+	machines := &capi.MachineList{}
+	if err := mcClient.List(ctx, machines, cr.InNamespace(namespace), cr.MatchingLabels{capi.ClusterNameLabel: clusterName}); err != nil {
+		return nil, err
+	}
+	machinesByNode := map[string]string{}
+	for _, machine := range machines.Items {
+		if name := machine.Status.NodeRef.Name; name != "" {
+			machinesByNode[name] = machine.Name
+		}
+	}
+
+	result := make(map[string]string, len(nodes.Items))
+	for _, node := range nodes.Items {
+		result[node.Name] = machinesByNode[node.Name]
+	}
+	return result, nil
+}
@@ -0,0 +1,517 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+	"github.com/giantswarm/clustertest/v5/pkg/wait"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+	. "github.com/onsi/gomega"    //nolint:staticcheck
+)
+
+const (
+	storageMatrixNamespace = "test-storage-matrix"
+	storageMatrixMountPath = "/data"
+	storageMatrixDataFile  = storageMatrixMountPath + "/payload"
+
+	// noProvisioner is the provisioner of StorageClasses for statically provisioned volumes.
+	noProvisioner = "kubernetes.io/no-provisioner"
+)
+
+var (
+	storageMatrixSize         = resource.MustParse("1Gi")
+	storageMatrixExpandedSize = resource.MustParse("2Gi")
+
+	volumeSnapshotGVK          = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+	volumeSnapshotClassListGVK = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshotClassList"}
+)
+
+// StorageConfig declares the StorageClasses a suite expects. It is set from the storage
+// section of the suite manifest.
+type StorageConfig struct {
+	// StorageClasses must exist in the workload cluster. Classes that exist but aren't
+	// listed are tested with ReadWriteOnce.
+	StorageClasses []StorageClassConfig `json:"storageClasses,omitempty"`
+	// SkipStorageClasses aren't exercised by the storage matrix.
+	SkipStorageClasses []string `json:"skipStorageClasses,omitempty"`
+}
+
+// StorageClassConfig declares an expected StorageClass.
+type StorageClassConfig struct {
+	Name string `json:"name"`
+	// AccessModes the class is tested with. Defaults to ReadWriteOnce.
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+}
+
+// storageCase is a single StorageClass and access mode of the storage matrix.
+type storageCase struct {
+	StorageClass storagev1.StorageClass
+	AccessMode   corev1.PersistentVolumeAccessMode
+	// Name is used for the PVC and pods of the case.
+	Name string
+	// Payload is written to the volume and expected to be read back.
+	Payload string
+}
+
+// storageMatrixCases returns the cases to run for the StorageClasses of the cluster. It
+// fails if a StorageClass required by cfg doesn't exist.
+func storageMatrixCases(classes []storagev1.StorageClass, cfg StorageConfig) ([]storageCase, error) {
+	missing := []string{}
+	for _, expected := range cfg.StorageClasses {
+		if !slices.ContainsFunc(classes, func(sc storagev1.StorageClass) bool { return sc.Name == expected.Name }) {
+			missing = append(missing, expected.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("expected StorageClasses %v not found", missing)
+	}
+
+	cases := []storageCase{}
+	for _, sc := range classes {
+		if slices.Contains(cfg.SkipStorageClasses, sc.Name) || sc.Provisioner == noProvisioner {
+			continue
+		}
+
+		accessModes := []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+		for _, expected := range cfg.StorageClasses {
+			if expected.Name == sc.Name && len(expected.AccessModes) > 0 {
+				accessModes = expected.AccessModes
+			}
+		}
+
+		for _, mode := range accessModes {
+			name := fmt.Sprintf("%s-%s", sc.Name, accessModeShortName(mode))
+			cases = append(cases, storageCase{
+				StorageClass: sc,
+				AccessMode:   mode,
+				Name:         name,
+				Payload:      fmt.Sprintf("%s-%d", name, time.Now().UnixNano()),
+			})
+		}
+	}
+	slices.SortFunc(cases, func(a, b storageCase) int { return strings.Compare(a.Name, b.Name) })
+	return cases, nil
+}
+
+func accessModeShortName(mode corev1.PersistentVolumeAccessMode) string {
+	switch mode {
+	case corev1.ReadWriteOnce:
+		return "rwo"
+	case corev1.ReadWriteMany:
+		return "rwx"
+	case corev1.ReadWriteOncePod:
+		return "rwop"
+	case corev1.ReadOnlyMany:
+		return "rox"
+	default:
+		return strings.ToLower(string(mode))
+	}
+}
+
+// runStorageMatrix provisions a volume for every StorageClass and access mode of the
+// cluster, writes to and reads back from it, expands it if the class allows it and takes
+// and restores a snapshot if a VolumeSnapshotClass exists for its provisioner.
+func runStorageMatrix(env *state.Environment, cfg *TestConfig) {
+	Context("storage matrix", Ordered, func() {
+		var (
+			wcClient *client.Client
+			cases    []storageCase
+		)
+
+		BeforeAll(func() {
+			if !cfg.StorageSupported {
+				skipUnsupported(cfg, CapabilityStorage, "Volumes can't be provisioned in this cluster configuration")
+			}
+		})
+
+		BeforeEach(func() {
+			helper.SetResponsibleTeam(helper.TeamTenet)
+
+			var err error
+
+			wcClient, err = env.WC()
+			if err != nil {
+				Fail(err.Error())
+			}
+		})
+
+		It("has the expected StorageClasses", func() {
+			storageClasses := &storagev1.StorageClassList{}
+			Expect(wcClient.List(env.Context(), storageClasses)).To(Succeed())
+
+			var err error
+			cases, err = storageMatrixCases(storageClasses.Items, cfg.Storage)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cases).NotTo(BeEmpty(), "no StorageClass to test")
+
+			for _, c := range cases {
+				logger.Log("Testing StorageClass '%s' (provisioner '%s') with access mode %s", c.StorageClass.Name, c.StorageClass.Provisioner, c.AccessMode)
+			}
+		})
+
+		It("provisions a volume and reads back its data for every StorageClass", func() {
+			err := wcClient.Create(env.Context(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: storageMatrixNamespace}})
+			if err != nil && !apierror.IsAlreadyExists(err) {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			for _, c := range cases {
+				By(fmt.Sprintf("provisioning %s", c.Name))
+				pvc := storageMatrixPVC(c.Name, c.StorageClass.Name, c.AccessMode, storageMatrixSize, nil)
+				Expect(createIfNotExists(env.Context(), wcClient, pvc)).To(Succeed())
+				Expect(createIfNotExists(env.Context(), wcClient, storageMatrixPod(c.Name, c.Name))).To(Succeed())
+			}
+
+			for _, c := range cases {
+				By(fmt.Sprintf("writing to and reading from %s", c.Name))
+				expectPodRunning(env, wcClient, c.Name)
+				Expect(writeVolumePayload(env.Context(), wcClient, c.Name, c.Payload)).To(Succeed())
+				Eventually(checkVolumePayload(env.Context(), wcClient, c.Name, c.Payload)).
+					WithTimeout(env.Timeout(timeout.VolumeData)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+
+				if c.AccessMode == corev1.ReadWriteMany {
+					By(fmt.Sprintf("reading %s from a second pod", c.Name))
+					reader := c.Name + "-reader"
+					Expect(createIfNotExists(env.Context(), wcClient, storageMatrixPod(reader, c.Name))).To(Succeed())
+					expectPodRunning(env, wcClient, reader)
+					Eventually(checkVolumePayload(env.Context(), wcClient, reader, c.Payload)).
+						WithTimeout(env.Timeout(timeout.VolumeData)).
+						WithPolling(wait.DefaultInterval).
+						Should(Succeed())
+				}
+			}
+		})
+
+		It("expands the volumes of StorageClasses allowing volume expansion", func() {
+			expandable := []storageCase{}
+			for _, c := range cases {
+				if ptr.Deref(c.StorageClass.AllowVolumeExpansion, false) {
+					expandable = append(expandable, c)
+				}
+			}
+			if len(expandable) == 0 {
+				Skip("no StorageClass allows volume expansion")
+			}
+
+			for _, c := range expandable {
+				By(fmt.Sprintf("expanding %s to %s", c.Name, storageMatrixExpandedSize.String()))
+				pvc := &corev1.PersistentVolumeClaim{}
+				Expect(wcClient.Get(env.Context(), cr.ObjectKey{Name: c.Name, Namespace: storageMatrixNamespace}, pvc)).To(Succeed())
+
+				patch := cr.MergeFrom(pvc.DeepCopy())
+				pvc.Spec.Resources.Requests[corev1.ResourceStorage] = storageMatrixExpandedSize
+				Expect(wcClient.Patch(env.Context(), pvc, patch)).To(Succeed())
+			}
+
+			for _, c := range expandable {
+				Eventually(checkVolumeExpanded(env.Context(), wcClient, c.Name, storageMatrixExpandedSize)).
+					WithTimeout(env.Timeout(timeout.VolumeExpansion)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+				Expect(checkVolumePayload(env.Context(), wcClient, c.Name, c.Payload)()).To(Succeed())
+			}
+		})
+
+		It("restores a snapshot of the volumes of StorageClasses with a VolumeSnapshotClass", func() {
+			snapshotClasses, err := volumeSnapshotClassesByDriver(env.Context(), wcClient)
+			Expect(err).NotTo(HaveOccurred())
+
+			snapshotted := 0
+			for _, c := range cases {
+				snapshotClass, ok := snapshotClasses[c.StorageClass.Provisioner]
+				if !ok {
+					logger.Log("No VolumeSnapshotClass for provisioner '%s', not snapshotting %s", c.StorageClass.Provisioner, c.Name)
+					continue
+				}
+				snapshotted++
+
+				By(fmt.Sprintf("snapshotting %s with VolumeSnapshotClass %s", c.Name, snapshotClass))
+				snapshot := volumeSnapshot(c.Name, snapshotClass)
+				Expect(createIfNotExists(env.Context(), wcClient, snapshot)).To(Succeed())
+
+				var restoreSize resource.Quantity
+				Eventually(func() error {
+					var err error
+					restoreSize, err = checkVolumeSnapshotReady(env.Context(), wcClient, snapshot)
+					return err
+				}).
+					WithTimeout(env.Timeout(timeout.VolumeSnapshot)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+
+				By(fmt.Sprintf("restoring the snapshot of %s", c.Name))
+				restored := c.Name + "-restored"
+				dataSource := &corev1.TypedLocalObjectReference{
+					APIGroup: ptr.To(volumeSnapshotGVK.Group),
+					Kind:     volumeSnapshotGVK.Kind,
+					Name:     snapshot.GetName(),
+				}
+				Expect(createIfNotExists(env.Context(), wcClient, storageMatrixPVC(restored, c.StorageClass.Name, c.AccessMode, restoreSize, dataSource))).To(Succeed())
+				Expect(createIfNotExists(env.Context(), wcClient, storageMatrixPod(restored, restored))).To(Succeed())
+
+				expectPodRunning(env, wcClient, restored)
+				Eventually(checkVolumePayload(env.Context(), wcClient, restored, c.Payload)).
+					WithTimeout(env.Timeout(timeout.VolumeData)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			}
+			if snapshotted == 0 {
+				Skip("no VolumeSnapshotClass for any of the tested StorageClasses")
+			}
+		})
+
+		AfterAll(func() {
+			if !cfg.StorageSupported {
+				return
+			}
+
+			wc, err := env.WC()
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func() error {
+				logger.Log("Deleting Namespace '%s'", storageMatrixNamespace)
+				err := wc.Delete(env.Context(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: storageMatrixNamespace}})
+				if err != nil && !apierror.IsNotFound(err) {
+					logger.Log("Failed to delete Namespace '%s'", storageMatrixNamespace)
+					return err
+				}
+				return nil
+			}).
+				WithTimeout(env.Timeout(timeout.ResourceApply)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+
+			// Deleting the namespace deletes the PVCs, and with them the dynamically provisioned PVs.
+			Eventually(wait.IsResourceDeleted(env.Context(), wc, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: storageMatrixNamespace}})).
+				WithTimeout(env.Timeout(timeout.StorageCleanup)).
+				WithPolling(wait.DefaultInterval).
+				Should(BeTrue())
+		})
+	})
+}
+
+func storageMatrixPVC(name, storageClass string, accessMode corev1.PersistentVolumeAccessMode, size resource.Quantity, dataSource *corev1.TypedLocalObjectReference) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: storageMatrixNamespace},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: ptr.To(storageClass),
+			AccessModes:      []corev1.PersistentVolumeAccessMode{accessMode},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+			DataSource: dataSource,
+		},
+	}
+}
+
+// storageMatrixPod mounts the given PVC at storageMatrixMountPath and idles, so data can
+// be written and read with ExecInPod.
+func storageMatrixPod(name, claimName string) *corev1.Pod {
+	user := int64(1001)
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: storageMatrixNamespace},
+		Spec: corev1.PodSpec{
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsUser:    &user,
+				RunAsGroup:   &user,
+				FSGroup:      &user,
+				RunAsNonRoot: ptr.To(true),
+				SeccompProfile: &corev1.SeccompProfile{
+					Type: corev1.SeccompProfileTypeRuntimeDefault,
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:  "storage",
+					Image: "gsoci.azurecr.io/giantswarm/alpine:latest",
+					Args:  []string{"sleep", "99999999"},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "data", MountPath: storageMatrixMountPath},
+					},
+					SecurityContext: &corev1.SecurityContext{
+						Capabilities: &corev1.Capabilities{
+							Drop: []corev1.Capability{"ALL"},
+						},
+						AllowPrivilegeEscalation: ptr.To(false),
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+					},
+				},
+			},
+		},
+	}
+}
+
+func volumeSnapshot(claimName, snapshotClass string) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"volumeSnapshotClassName": snapshotClass,
+			"source": map[string]interface{}{
+				"persistentVolumeClaimName": claimName,
+			},
+		},
+	}}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	snapshot.SetName(claimName)
+	snapshot.SetNamespace(storageMatrixNamespace)
+	return snapshot
+}
+
+func createIfNotExists(ctx context.Context, wcClient *client.Client, obj cr.Object) error {
+	logger.Log("Creating %T '%s'", obj, obj.GetName())
+	err := wcClient.Create(ctx, obj)
+	if err != nil && !apierror.IsAlreadyExists(err) {
+		logger.Log("Failed to create %T '%s' - %v", obj, obj.GetName(), err)
+		return err
+	}
+	return nil
+}
+
+func expectPodRunning(env *state.Environment, wcClient *client.Client, podName string) {
+	Eventually(verifyPodState(env.Context(), wcClient, podName, storageMatrixNamespace)).
+		WithTimeout(env.Timeout(timeout.PVCBinding)).
+		WithPolling(wait.DefaultInterval).
+		Should(Succeed())
+}
+
+func verifyPodState(ctx context.Context, wcClient *client.Client, podName, podNamespace string) func() error {
+	return func() error {
+
+		pod := &corev1.Pod{}
+		logger.Log("Getting pod '%s' in namespace '%s'", podName, podNamespace)
+		err := wcClient.Get(ctx, cr.ObjectKey{Name: podName, Namespace: podNamespace}, pod)
+		if err != nil {
+			logger.Log("Failed to get pod '%s' in namespace '%s' - %v", podName, podNamespace, err)
+			return err
+		}
+
+		if pod.Status.Phase != corev1.PodRunning {
+			logger.Log("Pod '%s' in namespace '%s' is not running", podName, podNamespace)
+			return fmt.Errorf("pod %s in namespace %s is not running", podName, podNamespace)
+		}
+
+		logger.Log("Pod '%s' in namespace '%s' is running successfully", podName, podNamespace)
+
+		return nil
+	}
+}
+
+func writeVolumePayload(ctx context.Context, wcClient *client.Client, podName, payload string) error {
+	cmd := []string{"sh", "-c", fmt.Sprintf("echo %s > %s && sync", payload, storageMatrixDataFile)}
+	_, stderr, err := wcClient.ExecInPod(ctx, podName, storageMatrixNamespace, "storage", cmd)
+	if err != nil {
+		return fmt.Errorf("failed to write to the volume of pod %s: %s (stderr: %q)", podName, err, stderr)
+	}
+	return nil
+}
+
+func checkVolumePayload(ctx context.Context, wcClient *client.Client, podName, payload string) func() error {
+	return func() error {
+		stdout, stderr, err := wcClient.ExecInPod(ctx, podName, storageMatrixNamespace, "storage", []string{"cat", storageMatrixDataFile})
+		if err != nil {
+			return fmt.Errorf("failed to read from the volume of pod %s: %s (stderr: %q)", podName, err, stderr)
+		}
+		if strings.TrimSpace(stdout) != payload {
+			return fmt.Errorf("pod %s read %q from its volume, expected %q", podName, strings.TrimSpace(stdout), payload)
+		}
+		logger.Log("Pod '%s' read back the data written to its volume", podName)
+		return nil
+	}
+}
+
+func checkVolumeExpanded(ctx context.Context, wcClient *client.Client, claimName string, size resource.Quantity) func() error {
+	return func() error {
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := wcClient.Get(ctx, cr.ObjectKey{Name: claimName, Namespace: storageMatrixNamespace}, pvc)
+		if err != nil {
+			return err
+		}
+
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(size) < 0 {
+			logger.Log("PersistentVolumeClaim '%s' has a capacity of %s, waiting for %s", claimName, capacity.String(), size.String())
+			return fmt.Errorf("PVC %s has a capacity of %s, expected %s", claimName, capacity.String(), size.String())
+		}
+		for _, condition := range pvc.Status.Conditions {
+			if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending && condition.Status == corev1.ConditionTrue {
+				return fmt.Errorf("PVC %s is waiting for its file system to be resized", claimName)
+			}
+		}
+		return nil
+	}
+}
+
+// volumeSnapshotClassesByDriver returns the names of the VolumeSnapshotClasses keyed by
+// CSI driver, preferring the default class of each driver.
+func volumeSnapshotClassesByDriver(ctx context.Context, wcClient *client.Client) (map[string]string, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(volumeSnapshotClassListGVK)
+	err := wcClient.List(ctx, list)
+	if meta.IsNoMatchError(err) {
+		logger.Log("VolumeSnapshotClass CRD is not installed")
+		return map[string]string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	classes := map[string]string{}
+	for _, item := range list.Items {
+		driver, _, _ := unstructured.NestedString(item.Object, "driver")
+		_, exists := classes[driver]
+		if !exists || item.GetAnnotations()["snapshot.storage.kubernetes.io/is-default-class"] == "true" {
+			classes[driver] = item.GetName()
+		}
+	}
+	return classes, nil
+}
+
+// checkVolumeSnapshotReady returns the restore size of the snapshot once it is ready to use.
+func checkVolumeSnapshotReady(ctx context.Context, wcClient *client.Client, snapshot *unstructured.Unstructured) (resource.Quantity, error) {
+	err := wcClient.Get(ctx, cr.ObjectKeyFromObject(snapshot), snapshot)
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+
+	ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	if !ready {
+		message, _, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message")
+		logger.Log("VolumeSnapshot '%s' is not ready to use yet (error: %q)", snapshot.GetName(), message)
+		return resource.Quantity{}, fmt.Errorf("VolumeSnapshot %s is not ready to use", snapshot.GetName())
+	}
+
+	restoreSize, _, _ := unstructured.NestedString(snapshot.Object, "status", "restoreSize")
+	if restoreSize == "" {
+		// Large enough for the volume, whether or not it was expanded.
+		return storageMatrixExpandedSize, nil
+	}
+	return resource.ParseQuantity(restoreSize)
+}
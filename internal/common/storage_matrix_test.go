@@ -0,0 +1,76 @@
+package common
+
+import (
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStorageMatrixCases(t *testing.T) {
+	storageClass := func(name, provisioner string) storagev1.StorageClass {
+		return storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}, Provisioner: provisioner}
+	}
+	classes := []storagev1.StorageClass{
+		storageClass("gp3", "ebs.csi.aws.com"),
+		storageClass("efs", "efs.csi.aws.com"),
+		storageClass("local", noProvisioner),
+		storageClass("legacy", "kubernetes.io/aws-ebs"),
+	}
+
+	testCases := []struct {
+		name      string
+		cfg       StorageConfig
+		expected  []string
+		expectErr bool
+	}{
+		{
+			name:     "defaults",
+			expected: []string{"efs-rwo", "gp3-rwo", "legacy-rwo"},
+		},
+		{
+			name: "access modes and skipped classes",
+			cfg: StorageConfig{
+				StorageClasses: []StorageClassConfig{
+					{Name: "gp3"},
+					{Name: "efs", AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadWriteMany}},
+				},
+				SkipStorageClasses: []string{"legacy"},
+			},
+			expected: []string{"efs-rwo", "efs-rwx", "gp3-rwo"},
+		},
+		{
+			name:      "missing class",
+			cfg:       StorageConfig{StorageClasses: []StorageClassConfig{{Name: "managed-premium"}}},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cases, err := storageMatrixCases(classes, tc.cfg)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatal("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			names := []string{}
+			for _, c := range cases {
+				names = append(names, c.Name)
+				if c.Payload == "" {
+					t.Errorf("case %s has no payload", c.Name)
+				}
+			}
+			if fmt.Sprint(names) != fmt.Sprint(tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
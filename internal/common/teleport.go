@@ -17,15 +17,15 @@ import (
 	. "github.com/onsi/gomega"    //nolint:staticcheck
 )
 
-func runTeleport(teleportSupported bool) {
+func runTeleport(env *state.Environment, cfg *TestConfig) {
 	Context("teleport", func() {
 		var teleportClient *tc.Client
 
 		BeforeEach(func() {
 			helper.SetResponsibleTeam(helper.TeamShield)
 
-			if !teleportSupported {
-				Skip("Teleport is not supported.")
+			if !cfg.TeleportSupported {
+				skipUnsupported(cfg, CapabilityTeleport, "Teleport is not supported.")
 			}
 			teleportIdentityFile := strings.TrimSpace(os.Getenv("TELEPORT_IDENTITY_FILE"))
 			if teleportIdentityFile == "" {
@@ -35,7 +35,7 @@ func runTeleport(teleportSupported bool) {
 			// transiently fail; retry so a blip doesn't fail the spec.
 			Eventually(func() error {
 				var err error
-				teleportClient, err = teleport.New(state.GetContext(), teleportIdentityFile)
+				teleportClient, err = teleport.New(env.Context(), teleportIdentityFile)
 				return err
 			}).
 				WithTimeout(1 * time.Minute).
@@ -48,17 +48,17 @@ func runTeleport(teleportSupported bool) {
 		// inherently eventually-consistent.
 		It("cluster is registered", FlakeAttempts(3), func() {
 			Eventually(func() (bool, error) {
-				clusters, err := teleportClient.GetKubernetesServers(state.GetContext())
+				clusters, err := teleportClient.GetKubernetesServers(env.Context())
 				if err != nil {
 					return false, err
 				}
 				for _, cluster := range clusters {
-					if strings.Contains(cluster.GetName(), state.GetCluster().Name) {
+					if strings.Contains(cluster.GetName(), env.Cluster().Name) {
 						logger.Log("cluster registered %v", cluster)
 						return true, nil
 					}
 				}
-				logger.Log("cluster %s still not registered", state.GetCluster().Name)
+				logger.Log("cluster %s still not registered", env.Cluster().Name)
 				return false, nil
 			}).
 				WithTimeout(5 * time.Minute).
@@ -20,7 +20,7 @@ import (
 	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
 )
 
-func Run() {
+func Run(env *state.Environment) {
 
 	/*
 		Note: These tests use a pre-created private ECR repository - 992382781567.dkr.ecr.eu-west-2.amazonaws.com/giantswarm/alpine
@@ -39,7 +39,7 @@ func Run() {
 			helper.SetResponsibleTeam(helper.TeamPhoenix)
 
 			var err error
-			wcClient, err = state.GetFramework().WC(state.GetCluster().Name)
+			wcClient, err = env.WC()
 			if err != nil {
 				Fail(err.Error())
 			}
@@ -52,7 +52,7 @@ func Run() {
 
 			Eventually(func() error {
 				logger.Log("Creating deployment with private ECR image...")
-				err = wcClient.Create(state.GetContext(), deployment)
+				err = wcClient.Create(env.Context(), deployment)
 				if err != nil && !apierror.IsAlreadyExists(err) {
 					return err
 				}
@@ -87,11 +87,11 @@ func Run() {
 				WithTimeout(2*time.Minute).
 				WithPolling(wait.DefaultInterval).
 				Should(Succeed(),
-					failurehandler.DeploymentsNotReady(state.GetFramework(), state.GetCluster()))
+					failurehandler.DeploymentsNotReady(env.Framework(), env.Cluster()))
 
 			Eventually(func() error {
 				logger.Log("Deleting deployment...")
-				return wcClient.Delete(state.GetContext(), deployment)
+				return wcClient.Delete(env.Context(), deployment)
 			}).
 				WithTimeout(1 * time.Minute).
 				WithPolling(wait.DefaultInterval).
@@ -1,6 +1,8 @@
 package helper
 
 import (
+	"fmt"
+
 	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
 )
 
@@ -13,3 +15,58 @@ func RecordNodeRolling(rolled bool) {
 	}
 	AddReportEntry("NODES_ROLLED", value)
 }
+
+// RecordNodeRollReport annotates the current test spec with the JSON report of how the nodes
+// of each pool were rolled during the test.
+func RecordNodeRollReport(report string) {
+	AddReportEntry("NODE_ROLL_REPORT", report)
+}
+
+// RecordWorkloadAvailability annotates the current test spec with the timeline of the
+// workload availability probes run during the upgrade.
+func RecordWorkloadAvailability(timeline string) {
+	AddReportEntry("WORKLOAD_AVAILABILITY", timeline)
+}
+
+// RecordAPIServerAvailability annotates the current test spec with the error windows and
+// request latencies of the API server measured during the upgrade.
+func RecordAPIServerAvailability(result string) {
+	AddReportEntry("API_SERVER_AVAILABILITY", result)
+}
+
+// RecordUpgradePreflight annotates the current test spec with the findings of the checks run
+// before a release is applied to the cluster.
+func RecordUpgradePreflight(report string) {
+	AddReportEntry("UPGRADE_PREFLIGHT", report)
+}
+
+// FailingResourceEntry is the name of the report entries recorded by RecordFailingResource.
+const FailingResourceEntry = "FAILING_RESOURCE"
+
+// FailingResource is an App or HelmRelease that wasn't ready when a spec failed.
+type FailingResource struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Team      Team   `json:"team,omitempty"`
+	// Reason and Message describe the last error condition of the resource.
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+func (r FailingResource) String() string {
+	s := fmt.Sprintf("%s %s/%s", r.Kind, r.Namespace, r.Name)
+	if r.Reason != "" {
+		s += fmt.Sprintf(" (%s)", r.Reason)
+	}
+	if r.Message != "" {
+		s += ": " + r.Message
+	}
+	return s
+}
+
+// RecordFailingResource annotates the current test spec with a resource that wasn't ready,
+// so the failure triage report can list it under its owning team.
+func RecordFailingResource(resource FailingResource) {
+	AddReportEntry(FailingResourceEntry, resource)
+}
@@ -1,13 +1,20 @@
 package helper
 
 import (
+	_ "embed"
+	"fmt"
+	"os"
 	"strings"
+	"sync"
 
 	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+	"sigs.k8s.io/yaml"
 )
 
 type Team string
 
+// The teams referenced from the shared tests. The full list of known teams is loaded
+// from the team registry, see LoadTeams.
 const (
 	TeamAtlas       = "Atlas"
 	TeamCabbage     = "Cabbage"
@@ -18,51 +25,114 @@ const (
 	TeamTenet       = "Tenet"
 )
 
-var TEAM_ID = map[Team]string{
-	TeamAtlas:       "S013DF1G0TU",
-	TeamCabbage:     "S02FMKBLZD5",
-	TeamHoneybadger: "S02G77D7GUA",
-	TeamPhoenix:     "S02H54GV65R",
-	TeamRocket:      "S01DAK3RRBP",
-	TeamShield:      "S0419AZLVU5",
-	TeamTenet:       "S07KQ7PCUSW",
+// EnvTeamsFile replaces the embedded team registry with the given file.
+const EnvTeamsFile = "E2E_TEAMS_FILE"
+
+//go:embed teams.yaml
+var defaultTeams []byte
+
+// TeamInfo is an entry of the team registry.
+type TeamInfo struct {
+	Name    Team   `json:"name"`
+	SlackID string `json:"slackId"`
+}
+
+// TeamRegistry holds the known teams.
+type TeamRegistry struct {
+	Teams []TeamInfo `json:"teams"`
+}
+
+// Lookup returns the team with the given name, ignoring case.
+func (r *TeamRegistry) Lookup(name string) (TeamInfo, bool) {
+	for _, team := range r.Teams {
+		if strings.EqualFold(string(team.Name), name) {
+			return team, true
+		}
+	}
+	return TeamInfo{}, false
+}
+
+// ParseTeams parses a team registry file.
+func ParseTeams(data []byte) (*TeamRegistry, error) {
+	registry := &TeamRegistry{}
+	if err := yaml.UnmarshalStrict(data, registry); err != nil {
+		return nil, fmt.Errorf("failed to parse team registry: %w", err)
+	}
+	for _, team := range registry.Teams {
+		if team.Name == "" {
+			return nil, fmt.Errorf("team registry contains a team without a name")
+		}
+	}
+	return registry, nil
+}
+
+var (
+	teamsOnce sync.Once
+	teams     *TeamRegistry
+)
+
+// LoadTeams returns the team registry, read from E2E_TEAMS_FILE if set or the embedded
+// teams.yaml otherwise. It panics if the registry is invalid, as nothing can be attributed
+// to a team without it.
+func LoadTeams() *TeamRegistry {
+	teamsOnce.Do(func() {
+		data := defaultTeams
+		source := "embedded teams.yaml"
+		if path := os.Getenv(EnvTeamsFile); path != "" {
+			var err error
+			data, err = os.ReadFile(path)
+			if err != nil {
+				panic(fmt.Sprintf("failed to read %s: %v", EnvTeamsFile, err))
+			}
+			source = path
+		}
+
+		var err error
+		teams, err = ParseTeams(data)
+		if err != nil {
+			panic(fmt.Sprintf("invalid team registry %s: %v", source, err))
+		}
+	})
+	return teams
+}
+
+// TeamSlackID returns the Slack ID of the team, or "" if the team is unknown.
+func TeamSlackID(t Team) string {
+	info, _ := LoadTeams().Lookup(string(t))
+	return info.SlackID
 }
 
 // SetResponsibleTeam annotates the current test spec with the team that is responsible for it passing
 func SetResponsibleTeam(t Team) {
 	AddReportEntry("TEAM", string(t))
-	AddReportEntry("TEAM_ID", TEAM_ID[t])
+	AddReportEntry("TEAM_ID", TeamSlackID(t))
 }
 
 // SetResponsibleTeamFromLabel processes a team label and sets the responsible team
 // Returns true if the team was found and set, false otherwise
 func SetResponsibleTeamFromLabel(teamLabel string) bool {
-	if teamLabel == "" {
+	team, ok := TeamFromLabel(teamLabel)
+	if !ok {
 		return false
 	}
 
-	// Process the team label: remove "team-" prefix and convert to lowercase
-	team := strings.TrimPrefix(teamLabel, "team-")
-	team = strings.ToLower(team)
-
-	switch team {
-	case "atlas":
-		SetResponsibleTeam(TeamAtlas)
-	case "cabbage":
-		SetResponsibleTeam(TeamCabbage)
-	case "honeybadger":
-		SetResponsibleTeam(TeamHoneybadger)
-	case "phoenix":
-		SetResponsibleTeam(TeamPhoenix)
-	case "rocket":
-		SetResponsibleTeam(TeamRocket)
-	case "shield":
-		SetResponsibleTeam(TeamShield)
-	case "tenet":
-		SetResponsibleTeam(TeamTenet)
-	default:
-		return false
+	SetResponsibleTeam(team)
+	return true
+}
+
+// TeamFromLabel resolves a team label (e.g. "team-tenet" or "Tenet") to a known Team
+// Returns false if the label doesn't match any known team
+func TeamFromLabel(teamLabel string) (Team, bool) {
+	if teamLabel == "" {
+		return "", false
 	}
 
-	return true
+	// Process the team label: remove "team-" prefix
+	team := strings.TrimPrefix(strings.ToLower(teamLabel), "team-")
+
+	info, ok := LoadTeams().Lookup(team)
+	if !ok {
+		return "", false
+	}
+	return info.Name, true
 }
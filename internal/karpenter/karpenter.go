@@ -0,0 +1,297 @@
+package karpenter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+	. "github.com/onsi/gomega"    //nolint:staticcheck
+
+	helmv2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/helmrelease"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+	"github.com/giantswarm/clustertest/v5/pkg/wait"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
+)
+
+const (
+	// NodePoolLabel is set by Karpenter on the nodes it provisions.
+	NodePoolLabel = "karpenter.sh/nodepool"
+
+	deploymentName      = "karpenter-hello-world"
+	deploymentNamespace = "giantswarm"
+	// initialReplicas is the number of replicas first deployed. Each replica needs its own
+	// Karpenter node because of the pod anti-affinity in the values.
+	initialReplicas = 2
+)
+
+var (
+	nodePoolListGVK  = schema.GroupVersionKind{Group: "karpenter.sh", Version: "v1", Kind: "NodePoolList"}
+	nodeClaimListGVK = schema.GroupVersionKind{Group: "karpenter.sh", Version: "v1", Kind: "NodeClaimList"}
+)
+
+// Run registers the Karpenter tests. They need a Karpenter node pool in the cluster
+// values and ./test_data/karpenter_helloworld_values.yaml in the suite.
+func Run(env *state.Environment) {
+	Context("karpenter", func() {
+		var wcClient *client.Client
+
+		BeforeEach(func() {
+			helper.SetResponsibleTeam(helper.TeamPhoenix)
+
+			Eventually(func() (err error) {
+				wcClient, err = env.WC()
+				return err
+			}).
+				WithTimeout(env.Timeout(timeout.ClientSetup)).
+				WithPolling(5 * time.Second).
+				Should(Succeed())
+		})
+
+		It("has ready Karpenter NodePools", func() {
+			Eventually(func() error {
+				return checkAllReady(env.Context(), wcClient, nodePoolListGVK)
+			}).
+				WithTimeout(env.Timeout(timeout.KarpenterNodePoolsReady)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+		})
+
+		Context("node provisioning", Ordered, func() {
+			var (
+				helmRelease   *helmv2.HelmRelease
+				ociRepoName   string
+				existingNodes int
+			)
+
+			BeforeAll(func() {
+				ctx := env.Context()
+				clusterName := env.Cluster().Name
+				namespace := env.Cluster().Organization.GetNamespace()
+
+				// BeforeAll runs before the BeforeEach above, so get a client of our own.
+				wc, err := env.WC()
+				Expect(err).NotTo(HaveOccurred())
+
+				existingNodes, err = countKarpenterNodes(ctx, wc)
+				Expect(err).NotTo(HaveOccurred())
+				logger.Log("There are '%d' Karpenter nodes before deploying the workload", existingNodes)
+
+				ociRepoName = fmt.Sprintf("%s-karpenter-hello-world-chart", clusterName)
+				err = helmrelease.EnsureOCIRepository(ctx, env.MC(), ociRepoName, namespace, "hello-world")
+				Expect(err).NotTo(HaveOccurred())
+
+				helmRelease, err = helloWorldHelmRelease(clusterName, namespace, ociRepoName, initialReplicas)
+				Expect(err).NotTo(HaveOccurred())
+
+				err = env.MC().Create(ctx, helmRelease)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(helmrelease.IsHelmReleaseReady(ctx, env.MC(), helmRelease.GetName(), helmRelease.GetNamespace())).
+					WithTimeout(env.Timeout(timeout.ScaleAppReady)).
+					WithPolling(5 * time.Second).
+					Should(BeTrue())
+			})
+
+			It("provisions ready NodeClaims for the pending pods", func() {
+				Eventually(func() error {
+					if err := checkAllReady(env.Context(), wcClient, nodeClaimListGVK); err != nil {
+						return err
+					}
+					return checkKarpenterNodes(env.Context(), wcClient, func(count int) bool { return count >= existingNodes+initialReplicas })
+				}).
+					WithTimeout(env.Timeout(timeout.KarpenterScaleUp)).
+					WithPolling(10 * time.Second).
+					Should(Succeed())
+			})
+
+			It("runs all replicas on Karpenter nodes", func() {
+				Eventually(checkReplicasOnKarpenterNodes(env.Context(), wcClient, initialReplicas)).
+					WithTimeout(env.Timeout(timeout.KarpenterScaleUp)).
+					WithPolling(10 * time.Second).
+					Should(Succeed())
+			})
+
+			It("consolidates nodes when the workload is scaled down", func() {
+				// The Deployment is managed by Helm, so scale it through the values of its HelmRelease.
+				scaledDown, err := helloWorldHelmRelease(env.Cluster().Name, helmRelease.GetNamespace(), ociRepoName, 1)
+				Expect(err).NotTo(HaveOccurred())
+
+				current := &helmv2.HelmRelease{}
+				Expect(env.MC().Get(env.Context(), cr.ObjectKeyFromObject(helmRelease), current)).To(Succeed())
+				patch := cr.MergeFrom(current.DeepCopy())
+				current.Spec.Values = scaledDown.Spec.Values
+				logger.Log("Scaling down HelmRelease '%s' to 1 replica", current.GetName())
+				Expect(env.MC().Patch(env.Context(), current, patch)).To(Succeed())
+
+				Eventually(checkReplicasOnKarpenterNodes(env.Context(), wcClient, 1)).
+					WithTimeout(env.Timeout(timeout.ScaleAppReady)).
+					WithPolling(10 * time.Second).
+					Should(Succeed())
+
+				Eventually(func() error {
+					return checkKarpenterNodes(env.Context(), wcClient, func(count int) bool { return count <= existingNodes+1 })
+				}).
+					WithTimeout(env.Timeout(timeout.KarpenterConsolidation)).
+					WithPolling(15 * time.Second).
+					Should(Succeed())
+			})
+
+			It("removes the Karpenter nodes after the workload is deleted", func() {
+				Expect(env.MC().Delete(env.Context(), helmRelease)).To(Succeed())
+
+				Eventually(func() error {
+					return checkKarpenterNodes(env.Context(), wcClient, func(count int) bool { return count <= existingNodes })
+				}).
+					WithTimeout(env.Timeout(timeout.KarpenterConsolidation)).
+					WithPolling(15 * time.Second).
+					Should(Succeed())
+			})
+
+			AfterAll(func() {
+				ctx := env.Context()
+				if helmRelease != nil {
+					err := env.MC().Delete(ctx, helmRelease)
+					if err != nil && !apierror.IsNotFound(err) {
+						Expect(err).NotTo(HaveOccurred())
+					}
+				}
+
+				err := helmrelease.DeleteOCIRepository(ctx, env.MC(), ociRepoName, env.Cluster().Organization.GetNamespace())
+				if err != nil && !apierror.IsNotFound(err) {
+					Expect(err).NotTo(HaveOccurred())
+				}
+			})
+		})
+	})
+}
+
+// helloWorldHelmRelease returns the HelmRelease deploying the hello-world chart with the
+// given number of replicas, each needing its own Karpenter node.
+func helloWorldHelmRelease(clusterName, namespace, ociRepoName string, replicas int) (*helmv2.HelmRelease, error) {
+	hrBuilder, err := helmrelease.New(
+		fmt.Sprintf("%s-%s", clusterName, deploymentName),
+		"hello-world",
+	).
+		WithNamespace(namespace).
+		WithReleaseName(deploymentName).
+		WithTargetNamespace(deploymentNamespace).
+		WithOCIRepoName(ociRepoName).
+		WithClusterName(clusterName).
+		WithValuesFile("./test_data/karpenter_helloworld_values.yaml", &helmrelease.TemplateValues{
+			ClusterName: clusterName,
+			ExtraValues: map[string]string{
+				"ReplicaCount": fmt.Sprintf("%d", replicas),
+			},
+		})
+	if err != nil {
+		return nil, err
+	}
+	return hrBuilder.Build()
+}
+
+// checkAllReady returns an error unless there is at least one object of the given list kind
+// and all of them have a Ready condition with Status='True'.
+func checkAllReady(ctx context.Context, wcClient *client.Client, listGVK schema.GroupVersionKind) error {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(listGVK)
+	if err := wcClient.List(ctx, list); err != nil {
+		return err
+	}
+
+	kind := listGVK.Kind[:len(listGVK.Kind)-len("List")]
+	if len(list.Items) == 0 {
+		logger.Log("No %s found", kind)
+		return fmt.Errorf("no %s found", kind)
+	}
+
+	notReady := []string{}
+	for _, item := range list.Items {
+		status, reason := readyCondition(item)
+		if status != string(corev1.ConditionTrue) {
+			logger.Log("%s %s is not ready: Status='%s', Reason='%s'", kind, item.GetName(), status, reason)
+			notReady = append(notReady, item.GetName())
+		}
+	}
+	if len(notReady) > 0 {
+		return fmt.Errorf("%s not ready: %v", kind, notReady)
+	}
+	return nil
+}
+
+func readyCondition(obj unstructured.Unstructured) (string, string) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != "Ready" {
+			continue
+		}
+		status, _ := condition["status"].(string)
+		reason, _ := condition["reason"].(string)
+		return status, reason
+	}
+	return "Unknown", "NoReadyCondition"
+}
+
+func countKarpenterNodes(ctx context.Context, wcClient *client.Client) (int, error) {
+	nodes := &corev1.NodeList{}
+	if err := wcClient.List(ctx, nodes, cr.HasLabels{NodePoolLabel}); err != nil {
+		return 0, err
+	}
+	return len(nodes.Items), nil
+}
+
+func checkKarpenterNodes(ctx context.Context, wcClient *client.Client, expected func(count int) bool) error {
+	count, err := countKarpenterNodes(ctx, wcClient)
+	if err != nil {
+		return err
+	}
+	logger.Log("There are currently '%d' Karpenter nodes", count)
+	if !expected(count) {
+		return fmt.Errorf("unexpected number of Karpenter nodes: %d", count)
+	}
+	return nil
+}
+
+// checkReplicasOnKarpenterNodes returns a function that succeeds once the hello-world
+// deployment has the given number of ready replicas, all running on Karpenter nodes.
+func checkReplicasOnKarpenterNodes(ctx context.Context, wcClient *client.Client, replicas int) func() error {
+	return func() error {
+		deployment := &appsv1.Deployment{}
+		err := wcClient.Get(ctx, cr.ObjectKey{Name: deploymentName, Namespace: deploymentNamespace}, deployment)
+		if err != nil {
+			return err
+		}
+		if int(deployment.Status.ReadyReplicas) != replicas {
+			logger.Log("Checking for ready replicas. Expected: %d, Actual: %d", replicas, deployment.Status.ReadyReplicas)
+			return fmt.Errorf("deployment %s has %d/%d ready replicas", deploymentName, deployment.Status.ReadyReplicas, replicas)
+		}
+
+		pods := &corev1.PodList{}
+		err = wcClient.List(ctx, pods, cr.InNamespace(deploymentNamespace), cr.MatchingLabels{"app.kubernetes.io/instance": deploymentName})
+		if err != nil {
+			return err
+		}
+		for _, pod := range pods.Items {
+			node := &corev1.Node{}
+			if err := wcClient.Get(ctx, cr.ObjectKey{Name: pod.Spec.NodeName}, node); err != nil {
+				return err
+			}
+			if _, ok := node.Labels[NodePoolLabel]; !ok {
+				return fmt.Errorf("pod %s is running on node %s which wasn't provisioned by Karpenter", pod.Name, node.Name)
+			}
+		}
+		return nil
+	}
+}
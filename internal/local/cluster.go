@@ -0,0 +1,221 @@
+// Package local provides a "dry" provider that runs the suite plumbing against a local
+// API server instead of a real management cluster.
+//
+// The API server is either started with envtest (KUBEBUILDER_ASSETS) or is an existing
+// cluster, such as kind, selected with E2E_LOCAL_KUBECONFIG. It is pre-populated with a
+// CAPI Cluster, MachinePools, Apps and HelmReleases that already report ready statuses,
+// and the workload cluster kubeconfig points back at the same API server. This makes it
+// possible to exercise suite.SetupWithOptions, the suite manifest and the check functions
+// of common.Run without any cloud credentials.
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	cb "github.com/giantswarm/cluster-standup-teardown/v6/pkg/clusterbuilder"
+	"github.com/giantswarm/clustertest/v5"
+	"github.com/giantswarm/clustertest/v5/pkg/application"
+	"github.com/giantswarm/clustertest/v5/pkg/env"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+	"github.com/giantswarm/clustertest/v5/pkg/organization"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+const (
+	// EnvKubeconfig points at the kubeconfig of an existing cluster (e.g. kind) to use
+	// instead of starting an envtest API server.
+	EnvKubeconfig = "E2E_LOCAL_KUBECONFIG"
+	// EnvKubeContext selects the context within EnvKubeconfig. Defaults to the current context.
+	EnvKubeContext = "E2E_LOCAL_KUBE_CONTEXT"
+	// EnvKubebuilderAssets is read by envtest to find the kube-apiserver and etcd binaries.
+	EnvKubebuilderAssets = "KUBEBUILDER_ASSETS"
+
+	// KubeContext is the name of the context written to the generated kubeconfig.
+	KubeContext = "cts-local"
+
+	DefaultClusterName  = "t-local"
+	DefaultOrganization = "local"
+	// DefaultFixturesPath is the location of the fixtures, relative to the suite directory.
+	DefaultFixturesPath = "./test_data/fixtures.yaml"
+)
+
+// ErrNotConfigured is returned by Start when neither an envtest installation nor an
+// existing cluster is available.
+var ErrNotConfigured = fmt.Errorf("neither %s nor %s is set", EnvKubeconfig, EnvKubebuilderAssets)
+
+// Config controls how the local cluster is started and seeded.
+type Config struct {
+	// ClusterName is the name of the seeded workload cluster. Defaults to DefaultClusterName.
+	ClusterName string
+	// Organization is the organization the cluster belongs to. Defaults to DefaultOrganization.
+	Organization string
+	// FixturesPath is a multi-document YAML template of the objects to seed. Defaults to
+	// DefaultFixturesPath.
+	FixturesPath string
+}
+
+// Cluster is a running local API server, seeded with the objects of a ready cluster.
+type Cluster struct {
+	config         Config
+	testEnv        *envtest.Environment
+	restConfig     *rest.Config
+	client         cr.Client
+	kubeconfig     []byte
+	kubeconfigPath string
+	seeded         []cr.Object
+}
+
+// Start brings up the local API server, installs the CRDs the suites rely on and seeds the
+// fixtures. It also points E2E_KUBECONFIG, E2E_WC_NAME and E2E_WC_NAMESPACE at the seeded
+// cluster so that suite.Setup loads it instead of creating a new one.
+//
+// ErrNotConfigured is returned when no API server is available, so callers can skip.
+func Start(cfg Config) (*Cluster, error) {
+	if cfg.ClusterName == "" {
+		cfg.ClusterName = DefaultClusterName
+	}
+	if cfg.Organization == "" {
+		cfg.Organization = DefaultOrganization
+	}
+	if cfg.FixturesPath == "" {
+		cfg.FixturesPath = DefaultFixturesPath
+	}
+
+	c := &Cluster{config: cfg}
+
+	var err error
+	switch {
+	case os.Getenv(EnvKubeconfig) != "":
+		logger.Log("Using existing cluster from %s", os.Getenv(EnvKubeconfig))
+		c.restConfig, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: os.Getenv(EnvKubeconfig)},
+			&clientcmd.ConfigOverrides{CurrentContext: os.Getenv(EnvKubeContext)},
+		).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", EnvKubeconfig, err)
+		}
+		// The kubeconfig is re-written with embedded credentials, so file references must be resolved.
+		if err := rest.LoadTLSFiles(c.restConfig); err != nil {
+			return nil, fmt.Errorf("failed to load TLS files: %w", err)
+		}
+	case os.Getenv(EnvKubebuilderAssets) != "":
+		logger.Log("Starting envtest API server")
+		c.testEnv = &envtest.Environment{}
+		c.restConfig, err = c.testEnv.Start()
+		if err != nil {
+			return nil, fmt.Errorf("failed to start envtest: %w", err)
+		}
+	default:
+		return nil, ErrNotConfigured
+	}
+
+	if err := c.setup(); err != nil {
+		return nil, errors.Join(err, c.Stop())
+	}
+
+	return c, nil
+}
+
+func (c *Cluster) setup() error {
+	crds, err := loadCRDs()
+	if err != nil {
+		return err
+	}
+	_, err = envtest.InstallCRDs(c.restConfig, envtest.CRDInstallOptions{CRDs: crds})
+	if err != nil {
+		return fmt.Errorf("failed to install CRDs: %w", err)
+	}
+
+	c.client, err = cr.New(c.restConfig, cr.Options{})
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	c.kubeconfig, err = clientcmd.Write(kubeconfigFromRESTConfig(c.restConfig, KubeContext))
+	if err != nil {
+		return fmt.Errorf("failed to generate kubeconfig: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "cts-local-")
+	if err != nil {
+		return err
+	}
+	c.kubeconfigPath = filepath.Join(dir, "kubeconfig.yaml")
+	if err := os.WriteFile(c.kubeconfigPath, c.kubeconfig, 0o600); err != nil {
+		return fmt.Errorf("failed to write kubeconfig: %w", err)
+	}
+
+	if err := c.seed(context.Background()); err != nil {
+		return err
+	}
+
+	os.Setenv(env.KubeconfigPath, c.kubeconfigPath)          // nolint:errcheck
+	os.Setenv(env.WorkloadClusterName, c.config.ClusterName) // nolint:errcheck
+	os.Setenv(env.WorkloadClusterNamespace, c.Namespace())   // nolint:errcheck
+
+	return nil
+}
+
+// Namespace returns the organization namespace the cluster is seeded in.
+func (c *Cluster) Namespace() string {
+	return organization.New(c.config.Organization).GetNamespace()
+}
+
+// KubeconfigPath returns the path of the generated kubeconfig, which has a single
+// context named KubeContext.
+func (c *Cluster) KubeconfigPath() string {
+	return c.kubeconfigPath
+}
+
+// Stop shuts down the envtest API server, if one was started, and removes the generated kubeconfig.
+func (c *Cluster) Stop() error {
+	var errs []error
+	if c.kubeconfigPath != "" {
+		errs = append(errs, os.RemoveAll(filepath.Dir(c.kubeconfigPath)))
+	}
+	if c.testEnv != nil {
+		errs = append(errs, c.testEnv.Stop())
+	}
+	return errors.Join(errs...)
+}
+
+// Standup replaces the cluster-standup-teardown standup. The seeded cluster is already
+// ready, so the loaded cluster is returned as-is.
+func (c *Cluster) Standup(_ *clustertest.Framework, cluster *application.Cluster) (*application.Cluster, error) {
+	logger.Log("Using pre-populated local cluster %s/%s", cluster.Name, cluster.GetNamespace())
+	return cluster, nil
+}
+
+// Teardown replaces the deletion of the cluster. It removes the seeded objects, which is
+// only meaningful when running against a long-lived cluster such as kind.
+func (c *Cluster) Teardown(ctx context.Context, _ *clustertest.Framework, _ *application.Cluster) error {
+	return c.unseed(ctx)
+}
+
+// ClusterBuilder returns a cluster builder that targets the local API server.
+func (c *Cluster) ClusterBuilder() cb.ClusterBuilder {
+	return &ClusterBuilder{}
+}
+
+// ClusterBuilder is the local stand-in for the provider cluster builders of
+// cluster-standup-teardown. Suites normally load the seeded cluster through
+// E2E_WC_NAME, so NewClusterApp is only used when that has been unset.
+type ClusterBuilder struct{}
+
+// NewClusterApp returns a cluster-aws shaped cluster, matching the seeded fixtures.
+func (c *ClusterBuilder) NewClusterApp(clusterName string, orgName string, _ []string) *application.Cluster {
+	return application.NewClusterApp(clusterName, application.ProviderAWS).
+		WithOrg(organization.New(orgName))
+}
+
+// KubeContext returns the context of the kubeconfig generated by Start.
+func (c *ClusterBuilder) KubeContext() string {
+	return KubeContext
+}
@@ -0,0 +1,37 @@
+package local
+
+import (
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// kubeconfigFromRESTConfig builds a kubeconfig with a single context, embedding the
+// credentials of cfg. It is used both for E2E_KUBECONFIG and the workload cluster
+// kubeconfig Secret, so the MC and WC clients talk to the same API server.
+func kubeconfigFromRESTConfig(cfg *rest.Config, contextName string) clientcmdapi.Config {
+	return clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			contextName: {
+				Server:                   cfg.Host,
+				CertificateAuthorityData: cfg.CAData,
+				InsecureSkipTLSVerify:    cfg.Insecure,
+			},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			contextName: {
+				ClientCertificateData: cfg.CertData,
+				ClientKeyData:         cfg.KeyData,
+				Token:                 cfg.BearerToken,
+				Username:              cfg.Username,
+				Password:              cfg.Password,
+			},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			contextName: {
+				Cluster:  contextName,
+				AuthInfo: contextName,
+			},
+		},
+		CurrentContext: contextName,
+	}
+}
@@ -0,0 +1,182 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/template"
+
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+//go:embed crds/*.yaml
+var crdFiles embed.FS
+
+// FixtureValues are the values available to the fixtures template.
+type FixtureValues struct {
+	ClusterName  string
+	Organization string
+	Namespace    string
+	// Envtest is true when running against an envtest API server, which has no nodes or
+	// controllers of its own. Fixtures use it to only seed Nodes where none exist.
+	Envtest bool
+}
+
+func loadCRDs() ([]*apiextensionsv1.CustomResourceDefinition, error) {
+	entries, err := crdFiles.ReadDir("crds")
+	if err != nil {
+		return nil, err
+	}
+
+	crds := make([]*apiextensionsv1.CustomResourceDefinition, 0, len(entries))
+	for _, entry := range entries {
+		data, err := crdFiles.ReadFile("crds/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := yaml.Unmarshal(data, crd); err != nil {
+			return nil, fmt.Errorf("failed to parse CRD %s: %w", entry.Name(), err)
+		}
+		crds = append(crds, crd)
+	}
+	return crds, nil
+}
+
+// renderFixtures executes the fixtures template at path and decodes the resulting YAML
+// documents into unstructured objects.
+func renderFixtures(path string, values FixtureValues) ([]*unstructured.Unstructured, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+
+	tmpl, err := template.New(path).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fixtures: %w", err)
+	}
+	rendered := &bytes.Buffer{}
+	if err := tmpl.Execute(rendered, values); err != nil {
+		return nil, fmt.Errorf("failed to render fixtures: %w", err)
+	}
+
+	objs := []*unstructured.Unstructured{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(rendered, 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		err := decoder.Decode(&obj.Object)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode fixtures: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// seed creates the organization namespace, the workload cluster kubeconfig Secret and the
+// fixtures. Objects are created with their status; for kinds with a status subresource
+// (e.g. Nodes) the status is written in a second request.
+func (c *Cluster) seed(ctx context.Context) error {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: c.Namespace()}}
+	if err := c.create(ctx, namespace); err != nil {
+		return err
+	}
+
+	// Read by clustertest when building the WC client. Pointing it at the same API
+	// server means the MC and the WC are the same cluster.
+	kubeconfigSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-kubeconfig", c.config.ClusterName),
+			Namespace: c.Namespace(),
+			Labels: map[string]string{
+				"cluster.x-k8s.io/cluster-name": c.config.ClusterName,
+			},
+		},
+		Data: map[string][]byte{
+			"value": c.kubeconfig,
+		},
+	}
+	if err := c.create(ctx, kubeconfigSecret); err != nil {
+		return err
+	}
+
+	fixtures, err := renderFixtures(c.config.FixturesPath, FixtureValues{
+		ClusterName:  c.config.ClusterName,
+		Organization: c.config.Organization,
+		Namespace:    c.Namespace(),
+		Envtest:      c.testEnv != nil,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range fixtures {
+		status, hasStatus := obj.Object["status"]
+		if err := c.create(ctx, obj); err != nil {
+			return err
+		}
+		if !hasStatus {
+			continue
+		}
+
+		obj.Object["status"] = status
+		err := c.client.Status().Update(ctx, obj)
+		// Kinds without a status subresource already stored the status on create.
+		if err != nil && !apierror.IsNotFound(err) {
+			return fmt.Errorf("failed to update status of %s %s: %w", obj.GetKind(), cr.ObjectKeyFromObject(obj), err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Cluster) create(ctx context.Context, obj cr.Object) error {
+	err := c.client.Create(ctx, obj)
+	if apierror.IsAlreadyExists(err) {
+		// Left over from a previous run against the same kind cluster.
+		logger.Log("%T %s already exists", obj, cr.ObjectKeyFromObject(obj))
+		existing := obj.DeepCopyObject().(cr.Object)
+		if err := c.client.Get(ctx, cr.ObjectKeyFromObject(obj), existing); err != nil {
+			return err
+		}
+		obj.SetResourceVersion(existing.GetResourceVersion())
+		err = c.client.Update(ctx, obj)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create %T %s: %w", obj, cr.ObjectKeyFromObject(obj), err)
+	}
+
+	c.seeded = append(c.seeded, obj)
+	return nil
+}
+
+// unseed deletes the seeded objects in reverse order of creation, ignoring those that are already gone.
+func (c *Cluster) unseed(ctx context.Context) error {
+	var errs []error
+	for i := len(c.seeded) - 1; i >= 0; i-- {
+		err := c.client.Delete(ctx, c.seeded[i])
+		if err != nil && !apierror.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	c.seeded = nil
+	return errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+package runner
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Suite is a single test suite found under the providers directory, either as a
+// precompiled Ginkgo test binary (as shipped in the container image) or as a Go
+// package directory (when running from a source checkout).
+type Suite struct {
+	// Provider is the directory directly below providers/, e.g. "capa".
+	Provider string `json:"provider"`
+	// Name is the suite directory below the provider, e.g. "standard" or "upgrade".
+	Name string `json:"name"`
+	// Path is what gets passed to ginkgo: the .test binary or the package directory.
+	Path string `json:"path"`
+}
+
+// Slug returns the "<provider>-<name>" identifier of the suite, e.g. "capa-standard".
+// It matches the suite slug used in crust-gather snapshot tags.
+func (s Suite) Slug() string {
+	return strings.ToLower(s.Provider + "-" + s.Name)
+}
+
+// Filter selects which discovered suites to run. Empty fields match everything.
+type Filter struct {
+	Providers  []string
+	Suites     []string
+	SkipSuites []string
+}
+
+// Matches reports whether the suite is selected by the filter.
+func (f Filter) Matches(s Suite) bool {
+	if len(f.Providers) > 0 && !containsFold(f.Providers, s.Provider) {
+		return false
+	}
+	if len(f.Suites) > 0 && !containsFold(f.Suites, s.Name) {
+		return false
+	}
+	if containsFold(f.SkipSuites, s.Name) {
+		return false
+	}
+	return true
+}
+
+// Discover walks each root looking for test suites below a providers/ directory.
+// A directory containing a *.test binary is run from that binary, otherwise a
+// directory containing a *_suite_test.go file is run from source.
+func Discover(roots []string) ([]Suite, error) {
+	seen := map[string]bool{}
+	suites := []Suite{}
+
+	for _, root := range roots {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() || seen[path] {
+				return nil
+			}
+
+			suite, ok, err := suiteInDir(path)
+			if err != nil {
+				return err
+			}
+			if ok {
+				seen[path] = true
+				suites = append(suites, suite)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover suites in %s: %w", root, err)
+		}
+	}
+
+	sort.Slice(suites, func(i, j int) bool {
+		return suites[i].Slug() < suites[j].Slug()
+	})
+
+	return suites, nil
+}
+
+func suiteInDir(dir string) (Suite, bool, error) {
+	provider, name, ok := providerAndName(dir)
+	if !ok {
+		return Suite{}, false, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Suite{}, false, err
+	}
+
+	sourceSuite := false
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(entry.Name(), ".test") {
+			return Suite{Provider: provider, Name: name, Path: filepath.Join(dir, entry.Name())}, true, nil
+		}
+		if strings.HasSuffix(entry.Name(), "_suite_test.go") {
+			sourceSuite = true
+		}
+	}
+
+	if sourceSuite {
+		return Suite{Provider: provider, Name: name, Path: dir}, true, nil
+	}
+
+	return Suite{}, false, nil
+}
+
+// providerAndName extracts the two path segments following "providers/",
+// e.g. "./providers/capa/standard" -> ("capa", "standard").
+func providerAndName(dir string) (string, string, bool) {
+	parts := strings.Split(filepath.ToSlash(filepath.Clean(dir)), "/")
+	for i, part := range parts {
+		if part == "providers" && len(parts) == i+3 {
+			return parts[i+1], parts[i+2], true
+		}
+	}
+	return "", "", false
+}
+
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProviderAndName(t *testing.T) {
+	testCases := []struct {
+		dir              string
+		expectedProvider string
+		expectedName     string
+		expectedOK       bool
+	}{
+		{dir: "./providers/capa/standard", expectedProvider: "capa", expectedName: "standard", expectedOK: true},
+		{dir: "/app/providers/capz/upgrade/", expectedProvider: "capz", expectedName: "upgrade", expectedOK: true},
+		{dir: "providers/capa/standard/../private", expectedProvider: "capa", expectedName: "private", expectedOK: true},
+		{dir: "./providers/capa"},
+		{dir: "./providers/capa/standard/test_data"},
+		{dir: "./internal/common"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.dir, func(t *testing.T) {
+			provider, name, ok := providerAndName(tc.dir)
+			if provider != tc.expectedProvider || name != tc.expectedName || ok != tc.expectedOK {
+				t.Errorf("expected (%q, %q, %t), got (%q, %q, %t)", tc.expectedProvider, tc.expectedName, tc.expectedOK, provider, name, ok)
+			}
+		})
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{
+		// Precompiled suites take precedence over the sources next to them.
+		"providers/capa/standard/standard.test",
+		"providers/capa/standard/standard_suite_test.go",
+		"providers/capa/upgrade/upgrade_suite_test.go",
+		"providers/capz/standard/standard.test",
+		// Not suites.
+		"providers/capa/standard/test_data/suite.yaml",
+		"providers/capv/standard/README.md",
+		"internal/common/common_suite_test.go",
+	} {
+		path := filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Discovering the same tree twice must not duplicate the suites.
+	suites, err := Discover([]string{root, filepath.Join(root, "providers", "capa")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Suite{
+		{Provider: "capa", Name: "standard", Path: filepath.Join(root, "providers/capa/standard/standard.test")},
+		{Provider: "capa", Name: "upgrade", Path: filepath.Join(root, "providers/capa/upgrade")},
+		{Provider: "capz", Name: "standard", Path: filepath.Join(root, "providers/capz/standard/standard.test")},
+	}
+	if !reflect.DeepEqual(suites, expected) {
+		t.Errorf("expected %+v, got %+v", expected, suites)
+	}
+
+	if _, err := Discover([]string{filepath.Join(root, "missing")}); err == nil {
+		t.Error("expected an error for a missing root")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	suites := []Suite{
+		{Provider: "capa", Name: "standard"},
+		{Provider: "capa", Name: "upgrade"},
+		{Provider: "capz", Name: "standard"},
+		{Provider: "capv", Name: "on-capa"},
+	}
+
+	testCases := []struct {
+		name     string
+		filter   Filter
+		expected []string
+	}{
+		{
+			name:     "empty",
+			expected: []string{"capa-standard", "capa-upgrade", "capz-standard", "capv-on-capa"},
+		},
+		{
+			name:     "provider",
+			filter:   Filter{Providers: []string{"CAPA"}},
+			expected: []string{"capa-standard", "capa-upgrade"},
+		},
+		{
+			name:     "suite type",
+			filter:   Filter{Suites: []string{" standard "}},
+			expected: []string{"capa-standard", "capz-standard"},
+		},
+		{
+			name:     "provider and suite type",
+			filter:   Filter{Providers: []string{"capz", "capv"}, Suites: []string{"standard"}},
+			expected: []string{"capz-standard"},
+		},
+		{
+			name:     "skipped suite type",
+			filter:   Filter{Providers: []string{"capa"}, SkipSuites: []string{"upgrade"}},
+			expected: []string{"capa-standard"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matched := []string{}
+			for _, suite := range suites {
+				if tc.filter.Matches(suite) {
+					matched = append(matched, suite.Slug())
+				}
+			}
+			if !reflect.DeepEqual(matched, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, matched)
+			}
+		})
+	}
+}
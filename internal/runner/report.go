@@ -0,0 +1,155 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/onsi/ginkgo/v2/reporters"
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// Summary is the structured outcome of a run, written to summary.json in the report directory.
+type Summary struct {
+	Passed bool           `json:"passed"`
+	Suites []SuiteSummary `json:"suites"`
+}
+
+// SuiteSummary is the outcome of a single suite within a Summary.
+type SuiteSummary struct {
+	Suite
+	Passed       bool     `json:"passed"`
+	ExitCode     int      `json:"exitCode"`
+	Duration     string   `json:"duration"`
+	Error        string   `json:"error,omitempty"`
+	SpecsPassed  int      `json:"specsPassed"`
+	SpecsFailed  int      `json:"specsFailed"`
+	SpecsSkipped int      `json:"specsSkipped"`
+	FailedSpecs  []string `json:"failedSpecs,omitempty"`
+}
+
+// MergeReports combines the JSON and JUnit reports written by each suite into a
+// single test-results.json and test-results.xml in reportDir and builds the run summary.
+// Suites that did not produce a report (e.g. because ginkgo could not start) are still
+// included in the summary.
+func MergeReports(reportDir string, results []Result) (Summary, error) {
+	summary := Summary{Passed: true}
+	merged := []types.Report{}
+	junitFiles := []string{}
+	var errs []error
+
+	for _, result := range results {
+		suiteSummary := SuiteSummary{
+			Suite:    result.Suite,
+			Passed:   result.Passed(),
+			ExitCode: result.ExitCode,
+			Duration: result.Duration.Round(time.Second).String(),
+		}
+		if result.Err != nil {
+			suiteSummary.Error = result.Err.Error()
+		}
+
+		reports, err := readJSONReport(filepath.Join(result.ReportDir, JSONReportName))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Suite.Slug(), err))
+		}
+
+		for _, report := range reports {
+			countSpecs(&suiteSummary, report)
+			merged = append(merged, report)
+
+			junitFile := filepath.Join(result.ReportDir, fmt.Sprintf("merge-%d-%s", len(junitFiles), JUnitReportName))
+			if err := reporters.GenerateJUnitReport(report, junitFile); err != nil {
+				errs = append(errs, fmt.Errorf("%s: failed to generate JUnit report: %w", result.Suite.Slug(), err))
+				continue
+			}
+			junitFiles = append(junitFiles, junitFile)
+		}
+
+		summary.Passed = summary.Passed && suiteSummary.Passed
+		summary.Suites = append(summary.Suites, suiteSummary)
+	}
+
+	if err := writeJSON(filepath.Join(reportDir, JSONReportName), merged); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := reporters.MergeAndCleanupJUnitReports(junitFiles, filepath.Join(reportDir, JUnitReportName)); err != nil {
+		errs = append(errs, fmt.Errorf("failed to merge JUnit reports: %w", err))
+	}
+
+	if err := writeJSON(filepath.Join(reportDir, SummaryName), summary); err != nil {
+		errs = append(errs, err)
+	}
+
+	return summary, errors.Join(errs...)
+}
+
+// PrintSummary writes a human readable table of the summary to w.
+func PrintSummary(w io.Writer, summary Summary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SUITE\tRESULT\tPASSED\tFAILED\tSKIPPED\tDURATION") // nolint:errcheck
+	for _, s := range summary.Suites {
+		status := "PASS"
+		if !s.Passed {
+			status = "FAIL"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\n", s.Slug(), status, s.SpecsPassed, s.SpecsFailed, s.SpecsSkipped, s.Duration) // nolint:errcheck
+	}
+	tw.Flush() // nolint:errcheck
+
+	for _, s := range summary.Suites {
+		if s.Error != "" {
+			fmt.Fprintf(w, "\n%s: %s\n", s.Slug(), s.Error) // nolint:errcheck
+		}
+		for _, spec := range s.FailedSpecs {
+			fmt.Fprintf(w, "  [FAIL] %s: %s\n", s.Slug(), spec) // nolint:errcheck
+		}
+	}
+}
+
+func countSpecs(s *SuiteSummary, report types.Report) {
+	for _, spec := range report.SpecReports {
+		if spec.LeafNodeType != types.NodeTypeIt {
+			continue
+		}
+		switch {
+		case spec.State.Is(types.SpecStatePassed):
+			s.SpecsPassed++
+		case spec.State.Is(types.SpecStateFailureStates):
+			s.SpecsFailed++
+			s.FailedSpecs = append(s.FailedSpecs, spec.FullText())
+		case spec.State.Is(types.SpecStateSkipped | types.SpecStatePending):
+			s.SpecsSkipped++
+		}
+	}
+}
+
+func readJSONReport(path string) ([]types.Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+
+	reports := []types.Report{}
+	if err := json.Unmarshal(data, &reports); err != nil {
+		return nil, fmt.Errorf("failed to decode report %s: %w", path, err)
+	}
+	return reports, nil
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", filepath.Base(path), err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
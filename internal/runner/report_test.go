@@ -0,0 +1,125 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func spec(nodeType types.NodeType, state types.SpecState, texts ...string) types.SpecReport {
+	return types.SpecReport{
+		ContainerHierarchyTexts: texts[:len(texts)-1],
+		LeafNodeText:            texts[len(texts)-1],
+		LeafNodeType:            nodeType,
+		State:                   state,
+	}
+}
+
+func TestCountSpecs(t *testing.T) {
+	report := types.Report{
+		SuiteDescription: "standard",
+		SpecReports: types.SpecReports{
+			spec(types.NodeTypeBeforeSuite, types.SpecStatePassed, "setup"),
+			spec(types.NodeTypeIt, types.SpecStatePassed, "basic", "has all nodes ready"),
+			spec(types.NodeTypeIt, types.SpecStateFailed, "basic", "has all pods running"),
+			spec(types.NodeTypeIt, types.SpecStateTimedout, "apps", "deploys the apps"),
+			spec(types.NodeTypeIt, types.SpecStateSkipped, "scale", "scales up"),
+			spec(types.NodeTypeIt, types.SpecStatePending, "scale", "scales down"),
+			spec(types.NodeTypeAfterSuite, types.SpecStateFailed, "teardown"),
+		},
+	}
+
+	summary := SuiteSummary{}
+	countSpecs(&summary, report)
+
+	expected := SuiteSummary{
+		SpecsPassed:  1,
+		SpecsFailed:  2,
+		SpecsSkipped: 2,
+		FailedSpecs:  []string{"basic has all pods running", "apps deploys the apps"},
+	}
+	if !reflect.DeepEqual(summary, expected) {
+		t.Errorf("expected %+v, got %+v", expected, summary)
+	}
+}
+
+func TestMergeReports(t *testing.T) {
+	reportDir := t.TempDir()
+
+	writeReport := func(suite Suite, reports ...types.Report) Result {
+		result := Result{Suite: suite, ReportDir: filepath.Join(reportDir, suite.Slug())}
+		if err := os.MkdirAll(result.ReportDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if reports == nil {
+			return result
+		}
+		data, err := json.Marshal(reports)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(result.ReportDir, JSONReportName), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	passed := writeReport(Suite{Provider: "capa", Name: "standard"}, types.Report{
+		SuiteDescription: "capa standard",
+		SuiteSucceeded:   true,
+		SpecReports: types.SpecReports{
+			spec(types.NodeTypeIt, types.SpecStatePassed, "basic", "has all nodes ready"),
+			spec(types.NodeTypeIt, types.SpecStateSkipped, "scale", "scales up"),
+		},
+	})
+	failed := writeReport(Suite{Provider: "capz", Name: "standard"}, types.Report{
+		SuiteDescription: "capz standard",
+		SpecReports: types.SpecReports{
+			spec(types.NodeTypeIt, types.SpecStateFailed, "basic", "has all pods running"),
+		},
+	})
+	failed.ExitCode = 1
+	// ginkgo couldn't start, so there is no report.
+	broken := writeReport(Suite{Provider: "capv", Name: "standard"})
+	broken.Err = errors.New("failed to run ginkgo: not found")
+
+	summary, err := MergeReports(reportDir, []Result{passed, failed, broken})
+	if err == nil {
+		t.Error("expected an error for the missing report")
+	}
+
+	if summary.Passed {
+		t.Error("expected the run to fail")
+	}
+	if len(summary.Suites) != 3 {
+		t.Fatalf("expected 3 suites in the summary, got %d", len(summary.Suites))
+	}
+	if s := summary.Suites[0]; !s.Passed || s.SpecsPassed != 1 || s.SpecsSkipped != 1 {
+		t.Errorf("unexpected summary for capa-standard: %+v", s)
+	}
+	if s := summary.Suites[1]; s.Passed || s.ExitCode != 1 || !reflect.DeepEqual(s.FailedSpecs, []string{"basic has all pods running"}) {
+		t.Errorf("unexpected summary for capz-standard: %+v", s)
+	}
+	if s := summary.Suites[2]; s.Passed || s.Error != "failed to run ginkgo: not found" {
+		t.Errorf("unexpected summary for capv-standard: %+v", s)
+	}
+
+	merged, err := readJSONReport(filepath.Join(reportDir, JSONReportName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 2 || merged[0].SuiteDescription != "capa standard" || merged[1].SuiteDescription != "capz standard" {
+		t.Errorf("unexpected merged report: %+v", merged)
+	}
+
+	for _, name := range []string{JUnitReportName, SummaryName} {
+		if _, err := os.Stat(filepath.Join(reportDir, name)); err != nil {
+			t.Errorf("expected %s to be written: %v", name, err)
+		}
+	}
+}
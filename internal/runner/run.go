@@ -0,0 +1,148 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/snapshot"
+)
+
+const (
+	// JSONReportName is the Ginkgo JSON report written for each suite and the merged report.
+	JSONReportName = "test-results.json"
+	// JUnitReportName is the Ginkgo JUnit report written for each suite and the merged report.
+	JUnitReportName = "test-results.xml"
+	// SummaryName is the structured run summary written to the report directory.
+	SummaryName = "summary.json"
+
+	suiteLogName = "output.log"
+)
+
+// Options configures a run of one or more suites.
+type Options struct {
+	// Ginkgo is the ginkgo binary used to run each suite.
+	Ginkgo string
+	// ReportDir is the directory the per-suite and merged reports are written to.
+	ReportDir string
+	// Timeout is passed to ginkgo as the timeout of each suite.
+	Timeout time.Duration
+	// Parallel is the number of suites run at the same time.
+	Parallel int
+	// LabelFilter is passed to ginkgo as --label-filter when set.
+	LabelFilter string
+	// GinkgoArgs are appended to the ginkgo command line of every suite.
+	GinkgoArgs []string
+	// Env holds extra "KEY=value" environment variables set for every suite.
+	Env []string
+	// Output receives the output of the suites when they are run one at a time.
+	Output io.Writer
+}
+
+// Result is the outcome of running a single suite.
+type Result struct {
+	Suite     Suite
+	ExitCode  int
+	Duration  time.Duration
+	ReportDir string
+	Err       error
+}
+
+// Passed reports whether ginkgo exited successfully for the suite.
+func (r Result) Passed() bool {
+	return r.Err == nil && r.ExitCode == 0
+}
+
+// Run runs the suites with up to opts.Parallel suites at a time and returns one
+// result per suite, in the same order as suites. Every suite is run regardless of
+// earlier failures.
+func Run(ctx context.Context, suites []Suite, opts Options) []Result {
+	parallel := opts.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	results := make([]Result, len(suites))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, suite := range suites {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = runSuite(ctx, suite, opts, parallel == 1)
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+func runSuite(ctx context.Context, suite Suite, opts Options, stream bool) Result {
+	result := Result{
+		Suite:     suite,
+		ReportDir: filepath.Join(opts.ReportDir, suite.Slug()),
+	}
+
+	if err := os.MkdirAll(result.ReportDir, 0o755); err != nil {
+		result.Err = fmt.Errorf("failed to create report directory: %w", err)
+		return result
+	}
+
+	logFile, err := os.Create(filepath.Join(result.ReportDir, suiteLogName))
+	if err != nil {
+		result.Err = fmt.Errorf("failed to create suite log: %w", err)
+		return result
+	}
+	defer logFile.Close() // nolint:errcheck
+
+	var out io.Writer = logFile
+	if stream && opts.Output != nil {
+		out = io.MultiWriter(logFile, opts.Output)
+	}
+
+	cmd := exec.CommandContext(ctx, opts.Ginkgo, ginkgoArgs(suite, result.ReportDir, opts)...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.Env = append(os.Environ(), opts.Env...)
+	// Suites write their own artifacts (e.g. the crust-gather snapshot index) to REPORT_DIR.
+	// Ginkgo runs each suite from its own directory, so the path must be absolute.
+	if reportDir, err := filepath.Abs(result.ReportDir); err == nil {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", snapshot.EnvReportDir, reportDir))
+	}
+
+	start := time.Now()
+	err = cmd.Run()
+	result.Duration = time.Since(start)
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		result.ExitCode = exitErr.ExitCode()
+	} else if err != nil {
+		result.Err = fmt.Errorf("failed to run ginkgo: %w", err)
+	}
+
+	return result
+}
+
+func ginkgoArgs(suite Suite, reportDir string, opts Options) []string {
+	args := []string{
+		"--output-dir=" + reportDir,
+		"--junit-report=" + JUnitReportName,
+		"--json-report=" + JSONReportName,
+		"--timeout=" + opts.Timeout.String(),
+		"-v",
+	}
+	if opts.LabelFilter != "" {
+		args = append(args, "--label-filter="+opts.LabelFilter)
+	}
+	args = append(args, opts.GinkgoArgs...)
+	return append(args, suite.Path)
+}
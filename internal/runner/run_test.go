@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGinkgoArgs(t *testing.T) {
+	suite := Suite{Provider: "capa", Name: "standard", Path: "./providers/capa/standard"}
+
+	testCases := []struct {
+		name     string
+		opts     Options
+		expected []string
+	}{
+		{
+			name: "defaults",
+			opts: Options{Timeout: 4 * time.Hour},
+			expected: []string{
+				"--output-dir=reports/capa-standard",
+				"--junit-report=test-results.xml",
+				"--json-report=test-results.json",
+				"--timeout=4h0m0s",
+				"-v",
+				"./providers/capa/standard",
+			},
+		},
+		{
+			name: "label filter and extra args",
+			opts: Options{
+				Timeout:     time.Hour,
+				LabelFilter: "!upgrade",
+				GinkgoArgs:  []string{"--flake-attempts=2", "--poll-progress-after=5m"},
+			},
+			expected: []string{
+				"--output-dir=reports/capa-standard",
+				"--junit-report=test-results.xml",
+				"--json-report=test-results.json",
+				"--timeout=1h0m0s",
+				"-v",
+				"--label-filter=!upgrade",
+				"--flake-attempts=2",
+				"--poll-progress-after=5m",
+				"./providers/capa/standard",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if args := ginkgoArgs(suite, "reports/capa-standard", tc.opts); !reflect.DeepEqual(args, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, args)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+// Load reads the snapshot indexes found at path, which is either an index file, a Ginkgo
+// JSON report or a report directory. Report directories are searched recursively for index
+// files and, if there are none, for the report entries of Ginkgo JSON reports. Indexes found
+// more than once (e.g. in a suite report and the merged report) are only returned once.
+func Load(path string) ([]Index, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return loadFile(path)
+	}
+
+	indexFiles := []string{}
+	reportFiles := []string{}
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch {
+		case d.Name() == IndexFileName:
+			indexFiles = append(indexFiles, p)
+		case strings.HasSuffix(d.Name(), ".json"):
+			reportFiles = append(reportFiles, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	files := indexFiles
+	if len(files) == 0 {
+		files = reportFiles
+	}
+
+	indexes := []Index{}
+	for _, file := range files {
+		found, err := loadFile(file)
+		if err != nil {
+			// Not every JSON file in a report directory is a Ginkgo report (e.g. summary.json).
+			if len(indexFiles) == 0 {
+				continue
+			}
+			return nil, err
+		}
+		indexes = append(indexes, found...)
+	}
+
+	return dedupe(indexes), nil
+}
+
+func loadFile(path string) ([]Index, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if filepath.Base(path) == IndexFileName {
+		index := Index{}
+		if err := json.Unmarshal(data, &index); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		return []Index{index}, nil
+	}
+
+	reports := []types.Report{}
+	if err := json.Unmarshal(data, &reports); err != nil {
+		return nil, fmt.Errorf("failed to parse Ginkgo report %s: %w", path, err)
+	}
+
+	indexes := []Index{}
+	for _, report := range reports {
+		for _, spec := range report.SpecReports {
+			for _, entry := range spec.ReportEntries {
+				if entry.Name != ReportEntryName {
+					continue
+				}
+				index := Index{}
+				if err := json.Unmarshal([]byte(entry.Value.AsJSON), &index); err != nil {
+					return nil, fmt.Errorf("failed to parse %s report entry in %s: %w", ReportEntryName, path, err)
+				}
+				indexes = append(indexes, index)
+			}
+		}
+	}
+	return indexes, nil
+}
+
+func dedupe(indexes []Index) []Index {
+	seen := map[string]bool{}
+	unique := []Index{}
+	for _, index := range indexes {
+		key := index.Suite + "/" + index.Namespace + "/" + index.Cluster
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, index)
+	}
+
+	sort.SliceStable(unique, func(i, j int) bool {
+		return unique[i].Suite < unique[j].Suite
+	})
+	return unique
+}
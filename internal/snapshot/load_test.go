@@ -0,0 +1,162 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2/types"
+)
+
+func testIndex(suite, cluster string) Index {
+	return Index{
+		Suite:     suite,
+		Cluster:   cluster,
+		Namespace: "org-giantswarm",
+		Snapshots: []Snapshot{{Target: TargetWC, Cluster: cluster, Backend: BackendOCI, Reference: "registry/snapshots:" + cluster, Result: ResultOK}},
+	}
+}
+
+func writeReport(t *testing.T, path string, indexes ...Index) {
+	t.Helper()
+
+	entries := []types.ReportEntry{}
+	for _, index := range indexes {
+		entries = append(entries, types.ReportEntry{Name: ReportEntryName, Value: types.WrapEntryValue(index)})
+	}
+	reports := []types.Report{{
+		SuiteDescription: "test",
+		SpecReports: types.SpecReports{
+			{LeafNodeType: types.NodeTypeIt, LeafNodeText: "is ready"},
+			{LeafNodeType: types.NodeTypeReportAfterSuite, ReportEntries: entries},
+		},
+	}}
+
+	data, err := json.Marshal(reports)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, data)
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func suites(indexes []Index) []string {
+	result := []string{}
+	for _, index := range indexes {
+		result = append(result, index.Suite)
+	}
+	return result
+}
+
+func TestLoad(t *testing.T) {
+	capa := testIndex("capa-standard", "t-capa")
+	capz := testIndex("capz-standard", "t-capz")
+
+	t.Run("index file", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := WriteIndex(dir, capa); err != nil {
+			t.Fatal(err)
+		}
+
+		indexes, err := Load(filepath.Join(dir, IndexFileName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(indexes, []Index{capa}) {
+			t.Errorf("expected %+v, got %+v", []Index{capa}, indexes)
+		}
+	})
+
+	t.Run("Ginkgo report", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test-results.json")
+		writeReport(t, path, capz, capa)
+
+		indexes, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(indexes, []Index{capz, capa}) {
+			t.Errorf("expected %+v, got %+v", []Index{capz, capa}, indexes)
+		}
+	})
+
+	t.Run("report directory with index files", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, index := range []Index{capz, capa} {
+			if err := WriteIndex(filepath.Join(dir, index.Suite), index); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// The reports are ignored when there are index files.
+		writeReport(t, filepath.Join(dir, "test-results.json"), testIndex("capv-standard", "t-capv"))
+
+		indexes, err := Load(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := suites(indexes); !reflect.DeepEqual(s, []string{"capa-standard", "capz-standard"}) {
+			t.Errorf("expected the indexes of capa-standard and capz-standard, got %v", s)
+		}
+	})
+
+	t.Run("report directory with reports only", func(t *testing.T) {
+		dir := t.TempDir()
+		// The merged report repeats the entries of the suite reports.
+		writeReport(t, filepath.Join(dir, "capz-standard", "test-results.json"), capz)
+		writeReport(t, filepath.Join(dir, "capa-standard", "test-results.json"), capa)
+		writeReport(t, filepath.Join(dir, "test-results.json"), capa, capz)
+		writeFile(t, filepath.Join(dir, "summary.json"), []byte(`{"passed": false, "suites": []}`))
+
+		indexes, err := Load(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := suites(indexes); !reflect.DeepEqual(s, []string{"capa-standard", "capz-standard"}) {
+			t.Errorf("expected each index once, got %v", s)
+		}
+	})
+
+	t.Run("broken index file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "capa-standard", IndexFileName), []byte("{"))
+
+		if _, err := Load(dir); err == nil {
+			t.Error("expected an error for a broken index file")
+		}
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+			t.Error("expected an error for a missing path")
+		}
+	})
+}
+
+func TestDedupe(t *testing.T) {
+	first := testIndex("capz-standard", "t-capz")
+	// Same suite and cluster, so only the first one is kept.
+	duplicate := testIndex("capz-standard", "t-capz")
+	duplicate.Snapshots = nil
+	// Same suite, but another cluster, e.g. the upgrade suite of the same provider.
+	other := testIndex("capz-standard", "t-capz-2")
+	capa := testIndex("capa-standard", "t-capa")
+
+	indexes := dedupe([]Index{first, duplicate, other, capa})
+
+	expected := []Index{capa, first, other}
+	if !reflect.DeepEqual(indexes, expected) {
+		t.Errorf("expected %+v, got %+v", expected, indexes)
+	}
+}
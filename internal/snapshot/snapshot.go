@@ -0,0 +1,120 @@
+// Package snapshot records the crust-gather snapshots collected when a suite fails, so
+// they can be found again from the reports of the run instead of from the logs.
+//
+// Each suite writes an Index to IndexFileName in its REPORT_DIR and attaches the same
+// Index to the Ginkgo report as the ReportEntryName report entry. Load reads either back
+// and ServeCommand turns a snapshot into the command replaying it locally.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// IndexFileName is the name of the index written to REPORT_DIR.
+	IndexFileName = "snapshots.json"
+	// ReportEntryName is the name of the Ginkgo report entry holding the index.
+	ReportEntryName = "CRUST_GATHER_SNAPSHOTS"
+	// EnvReportDir is the directory the index is written to. Nothing is written when unset.
+	EnvReportDir = "REPORT_DIR"
+)
+
+// Result is the outcome of collecting a single snapshot.
+type Result string
+
+const (
+	// ResultOK means the full snapshot was pushed.
+	ResultOK Result = "ok"
+	// ResultDegraded means the snapshot was pushed without pod logs.
+	ResultDegraded Result = "degraded"
+	// ResultFailed means no snapshot was pushed.
+	ResultFailed Result = "failed"
+)
+
+// Backend is where a snapshot is stored.
+type Backend string
+
+const (
+	// BackendOCI snapshots are pushed to an OCI registry; the reference is the image reference.
+	BackendOCI Backend = "oci"
+	// BackendDir snapshots are written to a local directory; the reference is its path.
+	BackendDir Backend = "dir"
+	// BackendTarball snapshots are written to a local gzipped tarball; the reference is its path.
+	BackendTarball Backend = "tarball"
+	// BackendNone disables snapshot collection.
+	BackendNone Backend = "none"
+)
+
+// Target is the cluster a snapshot was taken of.
+type Target string
+
+const (
+	TargetWC Target = "wc"
+	TargetMC Target = "mc"
+)
+
+// Snapshot is a single crust-gather snapshot.
+type Snapshot struct {
+	Target    Target    `json:"target"`
+	Cluster   string    `json:"cluster"`
+	Backend   Backend   `json:"backend,omitempty"`
+	Reference string    `json:"reference"`
+	Result    Result    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+	Duration  string    `json:"duration"`
+}
+
+// Available returns true if the snapshot was pushed and can be served.
+func (s Snapshot) Available() bool {
+	return s.Result == ResultOK || s.Result == ResultDegraded
+}
+
+// ServeCommand returns the command serving the snapshot locally. Snapshots recorded
+// without a backend predate local backends and were pushed to an OCI registry.
+func (s Snapshot) ServeCommand() string {
+	switch s.Backend {
+	case BackendDir, BackendTarball:
+		return fmt.Sprintf("kubectl crust-gather serve --archive %s", s.Reference)
+	default:
+		return fmt.Sprintf("kubectl crust-gather serve --reference %s", s.Reference)
+	}
+}
+
+// Index lists the snapshots collected for the workload cluster of a suite.
+type Index struct {
+	Suite     string     `json:"suite,omitempty"`
+	Cluster   string     `json:"cluster"`
+	Namespace string     `json:"namespace"`
+	Snapshots []Snapshot `json:"snapshots"`
+}
+
+// String is used as the representation of the report entry in the Ginkgo output.
+func (i Index) String() string {
+	lines := []string{}
+	for _, s := range i.Snapshots {
+		line := fmt.Sprintf("%s: %s %s (%s)", strings.ToUpper(string(s.Target)), s.Result, s.Reference, s.Duration)
+		if s.Error != "" {
+			line += " - " + s.Error
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WriteIndex writes index to IndexFileName in dir.
+func WriteIndex(dir string, index Index) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, IndexFileName), data, 0o644)
+}
@@ -0,0 +1,48 @@
+package snapshot
+
+import "testing"
+
+func TestServeCommand(t *testing.T) {
+	testCases := []struct {
+		name     string
+		snapshot Snapshot
+		expected string
+	}{
+		{
+			name:     "oci",
+			snapshot: Snapshot{Backend: BackendOCI, Reference: "gsoci.azurecr.io/crust-gather/snapshots:test-capa-standard-wc"},
+			expected: "kubectl crust-gather serve --reference gsoci.azurecr.io/crust-gather/snapshots:test-capa-standard-wc",
+		},
+		{
+			name:     "no backend",
+			snapshot: Snapshot{Reference: "gsoci.azurecr.io/crust-gather/snapshots:test-capa-standard-wc"},
+			expected: "kubectl crust-gather serve --reference gsoci.azurecr.io/crust-gather/snapshots:test-capa-standard-wc",
+		},
+		{
+			name:     "dir",
+			snapshot: Snapshot{Backend: BackendDir, Reference: "/reports/snapshots/test-capa-standard-wc"},
+			expected: "kubectl crust-gather serve --archive /reports/snapshots/test-capa-standard-wc",
+		},
+		{
+			name:     "tarball",
+			snapshot: Snapshot{Backend: BackendTarball, Reference: "/reports/snapshots/test-capa-standard-wc.tar.gz"},
+			expected: "kubectl crust-gather serve --archive /reports/snapshots/test-capa-standard-wc.tar.gz",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if command := tc.snapshot.ServeCommand(); command != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, command)
+			}
+		})
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	for result, expected := range map[Result]bool{ResultOK: true, ResultDegraded: true, ResultFailed: false} {
+		if available := (Snapshot{Result: result}).Available(); available != expected {
+			t.Errorf("expected %s to be available=%t", result, expected)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/giantswarm/clustertest/v5"
+	"github.com/giantswarm/clustertest/v5/pkg/application"
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
+)
+
+// Environment holds everything the specs of a single test suite need: the clustertest
+// framework, the workload cluster under test, the base context, the timeout registry and
+// a logger.
+//
+// An Environment is created by suite.SetupWithOptions while the spec tree is built and
+// populated in BeforeSuite, so accessors must only be called from within spec nodes.
+type Environment struct {
+	mu        sync.RWMutex
+	framework *clustertest.Framework
+	cluster   *application.Cluster
+	ctx       context.Context
+	timeouts  *timeout.Registry
+	logf      func(format string, args ...interface{})
+}
+
+// NewEnvironment returns an empty Environment using context.Background and the clustertest logger.
+func NewEnvironment() *Environment {
+	return &Environment{
+		ctx:      context.Background(),
+		timeouts: timeout.NewRegistry(),
+		logf:     logger.Log,
+	}
+}
+
+// SetContext sets the base context used by specs.
+func (e *Environment) SetContext(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ctx = ctx
+}
+
+// Context returns the base context used by specs.
+func (e *Environment) Context() context.Context {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.ctx
+}
+
+// SetFramework sets the clustertest framework used to access the clusters.
+func (e *Environment) SetFramework(framework *clustertest.Framework) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.framework = framework
+}
+
+// Framework returns the clustertest framework, or nil if BeforeSuite has not set it up.
+func (e *Environment) Framework() *clustertest.Framework {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.framework
+}
+
+// SetCluster sets the workload cluster under test.
+func (e *Environment) SetCluster(cluster *application.Cluster) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cluster = cluster
+}
+
+// Cluster returns the workload cluster under test, or nil if BeforeSuite has not set it up.
+func (e *Environment) Cluster() *application.Cluster {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.cluster
+}
+
+// MC returns the client for the management cluster.
+func (e *Environment) MC() *client.Client {
+	return e.Framework().MC()
+}
+
+// WC returns the client for the workload cluster under test.
+func (e *Environment) WC() (*client.Client, error) {
+	cluster := e.Cluster()
+	if cluster == nil {
+		return nil, fmt.Errorf("workload cluster has not been set up")
+	}
+	return e.Framework().WC(cluster.Name)
+}
+
+// Timeouts returns the registry resolving the timeout of every TestKey.
+func (e *Environment) Timeouts() *timeout.Registry {
+	return e.timeouts
+}
+
+// SetTimeout overrides the timeout for the given TestKey on behalf of the suite.
+// Overrides from E2E_TIMEOUT_OVERRIDES take precedence and are kept.
+func (e *Environment) SetTimeout(testKey timeout.TestKey, d time.Duration) error {
+	return e.timeouts.Set(testKey, d, timeout.SourceSuite)
+}
+
+// Timeout returns the effective timeout for the given TestKey.
+func (e *Environment) Timeout(testKey timeout.TestKey) time.Duration {
+	return e.timeouts.Get(testKey)
+}
+
+// SetLogger replaces the function used by Logf.
+func (e *Environment) SetLogger(logf func(format string, args ...interface{})) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.logf = logf
+}
+
+// Logf logs a formatted message using the Environment's logger.
+func (e *Environment) Logf(format string, args ...interface{}) {
+	e.mu.RLock()
+	logf := e.logf
+	e.mu.RUnlock()
+	logf(format, args...)
+}
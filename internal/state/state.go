@@ -1,3 +1,8 @@
+// Package state holds the Environment shared by the specs of a test suite.
+//
+// The package level functions are a compatibility shim operating on the default
+// Environment, which suite.SetupWithOptions replaces with the Environment it creates.
+// New code should use the Environment passed into common.Run and upgrade.Run instead.
 package state
 
 import (
@@ -11,69 +16,71 @@ import (
 	"github.com/giantswarm/clustertest/v5/pkg/application"
 )
 
-var lock = &sync.Mutex{}
+var (
+	lock       = &sync.RWMutex{}
+	defaultEnv = NewEnvironment()
+)
 
-type state struct {
-	framework *clustertest.Framework
-	cluster   *application.Cluster
-	ctx       context.Context
+// Default returns the Environment used by the package level functions.
+func Default() *Environment {
+	lock.RLock()
+	defer lock.RUnlock()
+	return defaultEnv
 }
 
-var singleInstance *state
-
-func get() *state {
-	if singleInstance == nil {
-		lock.Lock()
-		defer lock.Unlock()
-		if singleInstance == nil {
-			singleInstance = &state{}
-		}
-	}
-
-	return singleInstance
+// SetDefault replaces the Environment used by the package level functions.
+func SetDefault(e *Environment) {
+	lock.Lock()
+	defer lock.Unlock()
+	defaultEnv = e
 }
 
+// Deprecated: use Environment.SetContext.
 func SetContext(ctx context.Context) {
-	s := get()
-	s.ctx = ctx
+	Default().SetContext(ctx)
 }
 
+// Deprecated: use Environment.Context.
 func GetContext() context.Context {
-	return get().ctx
+	return Default().Context()
 }
 
+// Deprecated: use Environment.SetFramework.
 func SetFramework(framework *clustertest.Framework) {
-	s := get()
-	s.framework = framework
+	Default().SetFramework(framework)
 }
 
+// Deprecated: use Environment.Framework.
 func GetFramework() *clustertest.Framework {
-	return get().framework
+	return Default().Framework()
 }
 
-func SetCluster(framework *application.Cluster) {
-	s := get()
-	s.cluster = framework
+// Deprecated: use Environment.SetCluster.
+func SetCluster(cluster *application.Cluster) {
+	Default().SetCluster(cluster)
 }
 
+// Deprecated: use Environment.Cluster.
 func GetCluster() *application.Cluster {
-	return get().cluster
+	return Default().Cluster()
 }
 
-// SetTestTImeout sets the provided timeout against the given TestKey in the current state context to be used by tests
+// SetTestTimeout sets the provided timeout against the given TestKey to be used by tests.
+//
+// Deprecated: use Environment.SetTimeout.
 func SetTestTimeout(testKey timeout.TestKey, timeout time.Duration) {
-	s := get()
-	ctx := context.WithValue(s.ctx, testKey, timeout)
-	SetContext(ctx)
+	if err := Default().SetTimeout(testKey, timeout); err != nil {
+		Default().Logf("Ignoring timeout override - %v", err)
+	}
 }
 
-// GetTestTimeout returns the timeout from the context for the given TestKey or the defaultTimeout if not found
+// GetTestTimeout returns the timeout for the given TestKey, or defaultTimeout for keys
+// the timeout registry doesn't know about.
+//
+// Deprecated: use Environment.Timeout.
 func GetTestTimeout(testKey timeout.TestKey, defaultTimeout time.Duration) time.Duration {
-	s := get()
-	val, ok := s.ctx.Value(testKey).(time.Duration)
-	if ok {
-		return val
+	if !timeout.IsKnown(testKey) {
+		return defaultTimeout
 	}
-
-	return defaultTimeout
+	return Default().Timeout(testKey)
 }
@@ -31,7 +31,10 @@ import (
 	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
 	cr "sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/giantswarm/cluster-test-suites/v7/internal/snapshot"
 	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/triage"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/upgrade"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/clientcmd"
@@ -49,6 +52,18 @@ type Options struct {
 	// different providers and test configurations are easy to identify in the registry.
 	// Use WithSuiteIdentifier to set this. When empty the tag has no suite suffix.
 	SuiteSlug string
+
+	// StandupFn, if set, replaces the cluster-standup-teardown standup of the cluster in
+	// BeforeSuite. Used by the local provider, whose cluster is pre-populated with ready
+	// resources and has no controllers to wait for. clusterReadyFns are not run.
+	StandupFn func(framework *clustertest.Framework, cluster *application.Cluster) (*application.Cluster, error)
+
+	// TeardownFn, if set, replaces the deletion of the cluster in AfterSuite.
+	TeardownFn func(ctx context.Context, framework *clustertest.Framework, cluster *application.Cluster) error
+
+	// SnapshotCollector stores the crust-gather snapshots taken when the suite fails.
+	// Use WithSnapshotCollector to set this. When nil it is selected with E2E_SNAPSHOT_BACKEND.
+	SnapshotCollector SnapshotCollector
 }
 
 // Option mutates Options.
@@ -60,7 +75,17 @@ func WithExtraClusterValues(fn func() (string, error)) Option {
 	return func(o *Options) { o.ExtraClusterValuesFn = fn }
 }
 
+// WithStandup replaces the standup of the cluster in BeforeSuite with fn.
+func WithStandup(fn func(framework *clustertest.Framework, cluster *application.Cluster) (*application.Cluster, error)) Option {
+	return func(o *Options) { o.StandupFn = fn }
+}
+
+// WithTeardown replaces the deletion of the cluster in AfterSuite with fn.
+func WithTeardown(fn func(ctx context.Context, framework *clustertest.Framework, cluster *application.Cluster) error) Option {
+	return func(o *Options) { o.TeardownFn = fn }
+}
 
+// Defaults of the OCI snapshot collector.
 const (
 	CrustGatherRegistry   = "crustgatherci.azurecr.io"
 	CrustGatherRepository = "snapshots"
@@ -79,8 +104,9 @@ var beforeSuiteFailed bool
 // Setup handles the creation of the BeforeSuite and AfterSuite handlers. This covers the creations and cleanup of the test cluster.
 // `clusterReadyFns` can be provided if the cluster requires custom checks for cluster-ready status. If not provided the cluster will
 // be checked for at least a single control plane node being marked as ready.
-func Setup(isUpgrade bool, clusterBuilder cb.ClusterBuilder, clusterReadyFns ...func(client *client.Client)) {
-	SetupWithOptions(isUpgrade, clusterBuilder, nil, clusterReadyFns...)
+// Returns the Environment of the suite, which is populated once BeforeSuite has run.
+func Setup(isUpgrade bool, clusterBuilder cb.ClusterBuilder, clusterReadyFns ...func(client *client.Client)) *state.Environment {
+	return SetupWithOptions(isUpgrade, clusterBuilder, nil, clusterReadyFns...)
 }
 
 // detectSuiteSlug derives a provider/suite slug from the path of the running test
@@ -113,7 +139,10 @@ func detectSuiteSlug() string {
 }
 
 // SetupWithOptions is like Setup but accepts functional options.
-func SetupWithOptions(isUpgrade bool, clusterBuilder cb.ClusterBuilder, opts []Option, clusterReadyFns ...func(client *client.Client)) {
+//
+// The returned Environment is populated in BeforeSuite and should be passed to common.Run
+// and upgrade.Run. It also becomes the default Environment used by the state package functions.
+func SetupWithOptions(isUpgrade bool, clusterBuilder cb.ClusterBuilder, opts []Option, clusterReadyFns ...func(client *client.Client)) *state.Environment {
 	o := &Options{}
 	for _, opt := range opts {
 		opt(o)
@@ -121,19 +150,55 @@ func SetupWithOptions(isUpgrade bool, clusterBuilder cb.ClusterBuilder, opts []O
 	if o.SuiteSlug == "" {
 		o.SuiteSlug = detectSuiteSlug()
 	}
+
+	suiteEnv := state.NewEnvironment()
+	state.SetDefault(suiteEnv)
+	// Applied before any suite manifest so E2E_TIMEOUT_OVERRIDES always takes precedence.
+	// An invalid value fails BeforeSuite rather than the spec tree construction.
+	timeoutOverridesErr := suiteEnv.Timeouts().ApplyEnv()
+
+	var snapshotCollectorErr error
+	if o.SnapshotCollector == nil {
+		o.SnapshotCollector, snapshotCollectorErr = NewSnapshotCollectorFromEnv()
+	}
+
 	ReportAfterEach(func(report SpecReport) {
 		if report.Failed() {
 			hasFailures = true
 		}
 	})
 
+	// Group the failures by owning team so on-call doesn't have to go through the whole report.
+	ReportAfterSuite("failure triage", func(report Report) {
+		reportDir := os.Getenv(snapshot.EnvReportDir)
+		if reportDir == "" || report.SuiteSucceeded {
+			return
+		}
+		if err := triage.Write(reportDir, triage.Build(report)); err != nil {
+			logger.Log("Failed to write failure triage report - %v", err)
+		}
+	})
+
 	BeforeSuite(func() {
 		logger.LogWriter = GinkgoWriter
-		state.SetContext(context.Background())
+		suiteEnv.SetContext(context.Background())
+
+		Expect(timeoutOverridesErr).NotTo(HaveOccurred())
+		Expect(snapshotCollectorErr).NotTo(HaveOccurred())
+		logger.Log("Effective test timeouts:\n%s", suiteEnv.Timeouts())
+		AddReportEntry("EFFECTIVE_TIMEOUTS", suiteEnv.Timeouts().String())
 
 		if isUpgrade {
+			upgradePath, err := upgrade.Path()
+			Expect(err).NotTo(HaveOccurred())
+
 			overrideVersions := strings.TrimSpace(os.Getenv(env.OverrideVersions))
-			if overrideVersions == "" {
+			if len(upgradePath) > 0 {
+				// The upgrade path declares both ends of the upgrade, upgrade.Run applies the
+				// releases in between.
+				os.Setenv(env.ReleasePreUpgradeVersion, upgradePath[0])
+				os.Setenv(env.ReleaseVersion, upgradePath[len(upgradePath)-1])
+			} else if overrideVersions == "" {
 				// We're not using override versions, so we must be using release versions.
 				// We call GetUpgradeReleasesToTest to resolve the 'from' and 'to' versions.
 				// This function also handles the "previous_major" magic value.
@@ -175,10 +240,10 @@ func SetupWithOptions(isUpgrade bool, clusterBuilder cb.ClusterBuilder, opts []O
 
 		framework, err := clustertest.New(clusterBuilder.KubeContext())
 		Expect(err).NotTo(HaveOccurred())
-		state.SetFramework(framework)
+		suiteEnv.SetFramework(framework)
 
 		cluster := loadOrBuildCluster(framework, clusterBuilder, o)
-		state.SetCluster(cluster)
+		suiteEnv.SetCluster(cluster)
 
 		// We'll use this to track if the BeforeSuite failed and if we should do extra debug logging
 		setupComplete := false
@@ -190,7 +255,7 @@ func SetupWithOptions(isUpgrade bool, clusterBuilder cb.ClusterBuilder, opts []O
 				ctx, cancel := context.WithTimeout(ctx, 1*time.Minute)
 				defer cancel()
 
-				cluster := state.GetCluster()
+				cluster := suiteEnv.Cluster()
 
 				logger.Log("Attempting to get debug info for Cluster App")
 
@@ -257,9 +322,13 @@ func SetupWithOptions(isUpgrade bool, clusterBuilder cb.ClusterBuilder, opts []O
 			}
 		})()
 
-		cluster, err = standup.New(framework, isUpgrade, clusterReadyFns...).Standup(cluster)
+		if o.StandupFn != nil {
+			cluster, err = o.StandupFn(framework, cluster)
+		} else {
+			cluster, err = standup.New(framework, isUpgrade, clusterReadyFns...).Standup(cluster)
+		}
 		Expect(err).NotTo(HaveOccurred())
-		state.SetCluster(cluster)
+		suiteEnv.SetCluster(cluster)
 
 		// Make sure this comes last
 		setupComplete = true
@@ -268,7 +337,7 @@ func SetupWithOptions(isUpgrade bool, clusterBuilder cb.ClusterBuilder, opts []O
 	AfterSuite(func() {
 		// Only run cleanup if framework and cluster were actually initialized
 		// This prevents panics when BeforeSuite skips for any reason (PRs, first major releases, etc.)
-		if state.GetFramework() == nil || state.GetCluster() == nil {
+		if suiteEnv.Framework() == nil || suiteEnv.Cluster() == nil {
 			logger.Log("Skipping cleanup as cluster/framework were not initialized")
 			return
 		}
@@ -277,21 +346,27 @@ func SetupWithOptions(isUpgrade bool, clusterBuilder cb.ClusterBuilder, opts []O
 		// failed or BeforeSuite failed (e.g. cluster standup or app install timed out).
 		// Snapshots are large and expensive to push, so we skip them on green runs.
 		if hasFailures || beforeSuiteFailed {
-			collectCrustGatherSnapshots(o.SuiteSlug)
+			collectCrustGatherSnapshots(suiteEnv, o.SuiteSlug, o.SnapshotCollector)
 		}
 
-		// Ensure we reset the context timeout to make sure we allow plenty of time to clean up
-		ctx := state.GetContext()
-		ctx, _ = context.WithTimeout(ctx, 1*time.Hour) //nolint:govet
-		state.SetContext(ctx)
+		// Use a fresh timeout to make sure we allow plenty of time to clean up
+		ctx, cancel := context.WithTimeout(suiteEnv.Context(), 1*time.Hour)
+		defer cancel()
 
-		err := cleanupPVs(ctx)
+		err := cleanupPVs(ctx, suiteEnv)
 		if err != nil {
 			logger.Log("Failed to cleanup PVs before delete - %v", err)
 		}
 
-		Expect(state.GetFramework().DeleteCluster(state.GetContext(), state.GetCluster())).To(Succeed())
+		if o.TeardownFn != nil {
+			Expect(o.TeardownFn(ctx, suiteEnv.Framework(), suiteEnv.Cluster())).To(Succeed())
+			return
+		}
+
+		Expect(suiteEnv.Framework().DeleteCluster(ctx, suiteEnv.Cluster())).To(Succeed())
 	})
+
+	return suiteEnv
 }
 
 // loadOrBuildCluster mirrors cb.LoadOrBuildCluster but allows appending an extra YAML
@@ -389,19 +464,21 @@ func getProviderFromBuilderLogic(pkgPath, structName string) (string, error) {
 }
 
 // collectCrustGatherSnapshots collects cluster state from both the workload cluster
-// and the management cluster using crust-gather, and pushes the snapshots to an OCI registry.
+// and the management cluster using crust-gather, and stores the snapshots with collector.
 // This is best-effort: failures are logged but do not block cluster cleanup.
-func collectCrustGatherSnapshots(suiteSlug string) {
+func collectCrustGatherSnapshots(suiteEnv *state.Environment, suiteSlug string, collector SnapshotCollector) {
+	if collector == nil || collector.Backend() == snapshot.BackendNone {
+		logger.Log("crust-gather: snapshot collection is disabled")
+		return
+	}
 	if _, err := exec.LookPath("crust-gather"); err != nil {
 		logger.Log("crust-gather binary not found, skipping snapshot collection")
 		return
 	}
 
-	cluster := state.GetCluster()
+	cluster := suiteEnv.Cluster()
 	clusterName := cluster.Name
 	clusterNamespace := cluster.GetNamespace()
-	username := os.Getenv("CRUST_GATHER_REGISTRY_USERNAME")
-	password := os.Getenv("CRUST_GATHER_REGISTRY_PASSWORD")
 
 	// Build the tag suffix: "<clusterName>[-<suiteSlug>]-{wc,mc}".
 	// suiteSlug is set via WithSuiteIdentifier; empty means no suite suffix (backwards-compatible).
@@ -410,60 +487,113 @@ func collectCrustGatherSnapshots(suiteSlug string) {
 		tagPrefix = clusterName + "-" + suiteSlug
 	}
 
-	// wcResult and mcResult stay "failed" if writeCAPIKubeconfig fails below and
-	// runCrustGather is never called for that side.
-	wcResult, mcResult := "failed", "failed"
+	index := snapshot.Index{
+		Suite:     suiteSlug,
+		Cluster:   clusterName,
+		Namespace: clusterNamespace,
+	}
 
 	// Collect workload cluster snapshot (full cluster).
 	// Read the CAPI kubeconfig secret directly (not Teleport) to avoid proxy/auth
 	// issues that crust-gather can't handle.
 	// Each cluster gets its own context for the kubeconfig read, since the MC client
 	// may be rate-limited after the test suite and a shared context could starve the second read.
-	wcReference := fmt.Sprintf("%s/%s:%s-wc", CrustGatherRegistry, CrustGatherRepository, tagPrefix)
+	wc := newSnapshot(collector, snapshot.TargetWC, clusterName, tagPrefix+"-wc")
 	wcCtx, wcCancel := context.WithTimeout(context.Background(), 3*time.Minute)
 	defer wcCancel()
-	if wcKubeconfigPath, wcPrivate, err := writeCAPIKubeconfig(wcCtx, clusterName, clusterNamespace); err != nil {
+	if wcKubeconfigPath, wcPrivate, err := writeCAPIKubeconfig(wcCtx, suiteEnv.MC(), clusterName, clusterNamespace); err != nil {
 		logger.Log("crust-gather: failed to get WC kubeconfig: %v", err)
+		wc.Error = fmt.Sprintf("failed to get WC kubeconfig: %v", err)
 	} else {
 		defer os.Remove(wcKubeconfigPath)
-		applyCrustGatherPolicyException(wcCtx, clusterName)
-		wcResult = runCrustGather("WC", wcKubeconfigPath, wcReference, username, password, !wcPrivate,
-			"--exclude-kind", "Lease",
-			"--exclude-kind", "EndpointSlice",
-			"--exclude-kind", "ControllerRevision")
+		applyCrustGatherPolicyException(wcCtx, suiteEnv)
+		wc.Result = runCrustGather(collector, SnapshotRequest{
+			Label:      "WC",
+			Kubeconfig: wcKubeconfigPath,
+			Tag:        tagPrefix + "-wc",
+			StripProxy: !wcPrivate,
+			Args: []string{
+				"--exclude-kind", "Lease",
+				"--exclude-kind", "EndpointSlice",
+				"--exclude-kind", "ControllerRevision",
+			},
+		})
 	}
+	index.Snapshots = append(index.Snapshots, finishSnapshot(wc))
 
 	// Collect management cluster snapshot (scoped to the test cluster's namespace).
 	// The MC manages itself, so its CAPI kubeconfig secret is available on the MC too.
 	// GetClusterName() may return the Teleport context name (e.g., "teleport.giantswarm.io-grizzly"),
 	// so we strip the prefix to get the actual cluster name for the CAPI secret lookup.
-	mcName := state.GetFramework().MC().GetClusterName()
+	mcName := suiteEnv.MC().GetClusterName()
 	mcName = strings.TrimPrefix(mcName, "teleport.giantswarm.io-")
-	mcReference := fmt.Sprintf("%s/%s:%s-mc", CrustGatherRegistry, CrustGatherRepository, tagPrefix)
+	mc := newSnapshot(collector, snapshot.TargetMC, mcName, tagPrefix+"-mc")
 	mcCtx, mcCancel := context.WithTimeout(context.Background(), 3*time.Minute)
 	defer mcCancel()
-	if mcKubeconfigPath, mcPrivate, err := writeCAPIKubeconfig(mcCtx, mcName, "org-giantswarm"); err != nil {
+	if mcKubeconfigPath, mcPrivate, err := writeCAPIKubeconfig(mcCtx, suiteEnv.MC(), mcName, "org-giantswarm"); err != nil {
 		logger.Log("crust-gather: failed to get MC kubeconfig: %v", err)
+		mc.Error = fmt.Sprintf("failed to get MC kubeconfig: %v", err)
 	} else {
 		defer os.Remove(mcKubeconfigPath)
 		// For the MC, we exclude Node resources because --include-namespace doesn't
 		// filter out cluster-scoped resources. Without this, crust-gather would try
 		// to create debug pods on the MC (blocked by Kyverno), and we don't want to
 		// maintain a permanent PolicyException on the long-lived MC.
-		mcResult = runCrustGather("MC", mcKubeconfigPath, mcReference, username, password, !mcPrivate,
-			"--include-namespace", clusterNamespace,
-			"--exclude-kind", "Node")
+		mc.Result = runCrustGather(collector, SnapshotRequest{
+			Label:      "MC",
+			Kubeconfig: mcKubeconfigPath,
+			Tag:        tagPrefix + "-mc",
+			StripProxy: !mcPrivate,
+			Args: []string{
+				"--include-namespace", clusterNamespace,
+				"--exclude-kind", "Node",
+			},
+		})
+	}
+	index.Snapshots = append(index.Snapshots, finishSnapshot(mc))
+
+	logger.Log("crust-gather: SUMMARY wc=%s mc=%s", wc.Result, mc.Result)
+	recordSnapshots(index)
+}
+
+// newSnapshot starts timing the collection of a snapshot. The result stays failed
+// unless crust-gather is run and succeeds.
+func newSnapshot(collector SnapshotCollector, target snapshot.Target, clusterName, tag string) snapshot.Snapshot {
+	return snapshot.Snapshot{
+		Target:    target,
+		Cluster:   clusterName,
+		Backend:   collector.Backend(),
+		Reference: collector.Reference(tag),
+		Result:    snapshot.ResultFailed,
+		StartedAt: time.Now().UTC(),
 	}
+}
 
-	logger.Log("crust-gather: SUMMARY wc=%s mc=%s", wcResult, mcResult)
+func finishSnapshot(s snapshot.Snapshot) snapshot.Snapshot {
+	s.Duration = time.Since(s.StartedAt).Round(time.Second).String()
+	return s
+}
+
+// recordSnapshots attaches the snapshot index to the Ginkgo report and writes it to
+// REPORT_DIR, so the snapshots of a failure can be found without searching the logs.
+func recordSnapshots(index snapshot.Index) {
+	AddReportEntry(snapshot.ReportEntryName, index)
+
+	reportDir := os.Getenv(snapshot.EnvReportDir)
+	if reportDir == "" {
+		return
+	}
+	if err := snapshot.WriteIndex(reportDir, index); err != nil {
+		logger.Log("crust-gather: failed to write %s: %v", snapshot.IndexFileName, err)
+	}
 }
 
 // writeCAPIKubeconfig reads the CAPI kubeconfig secret for the given cluster from the MC
 // and writes it to a temp file. Returns the file path, whether the API server endpoint is
 // private (RFC1918 or private DNS), and any error. Caller is responsible for cleanup.
-func writeCAPIKubeconfig(ctx context.Context, clusterName, namespace string) (string, bool, error) {
+func writeCAPIKubeconfig(ctx context.Context, mcClient *client.Client, clusterName, namespace string) (string, bool, error) {
 	var secret corev1.Secret
-	err := state.GetFramework().MC().Get(ctx, cr.ObjectKeyFromObject(&corev1.Secret{
+	err := mcClient.Get(ctx, cr.ObjectKeyFromObject(&corev1.Secret{
 		ObjectMeta: v1.ObjectMeta{
 			Name:      fmt.Sprintf("%s-kubeconfig", clusterName),
 			Namespace: namespace,
@@ -497,8 +627,8 @@ func writeCAPIKubeconfig(ctx context.Context, clusterName, namespace string) (st
 // despite the cluster's pod security policies. This is best-effort: if Kyverno isn't
 // installed or the apply fails, we log and continue. Without the exception, debug pod
 // creation fails but the rest of the resource collection still works.
-func applyCrustGatherPolicyException(ctx context.Context, wcClusterName string) {
-	wcClient, err := state.GetFramework().WC(wcClusterName)
+func applyCrustGatherPolicyException(ctx context.Context, suiteEnv *state.Environment) {
+	wcClient, err := suiteEnv.WC()
 	if err != nil {
 		logger.Log("crust-gather: failed to get WC client for policy exception: %v", err)
 		return
@@ -555,88 +685,56 @@ func applyCrustGatherPolicyException(ctx context.Context, wcClusterName string)
 // that resource.
 const crustGatherFullAttempts = 3
 
-// runCrustGather executes crust-gather collect and pushes the snapshot to the OCI registry,
+// runCrustGather executes crust-gather collect and stores the snapshot with collector,
 // retrying up to crustGatherFullAttempts times. If every full-fidelity attempt fails, it makes
 // one last attempt without pod logs so a persistently unreachable node yields a resource/events
 // archive instead of nothing. Errors are logged but do not cause the test suite to fail.
-// Returns "ok" (full archive), "degraded" (archive without pod logs), or "failed" (no archive).
-func runCrustGather(label, kubeconfig, reference, username, password string, stripProxy bool, extraArgs ...string) string {
-	logger.Log("crust-gather: collecting %s snapshot -> %s", label, reference)
+// Returns ResultOK (full archive), ResultDegraded (archive without pod logs), or ResultFailed (no archive).
+func runCrustGather(collector SnapshotCollector, req SnapshotRequest) snapshot.Result {
+	reference := collector.Reference(req.Tag)
+	logger.Log("crust-gather: collecting %s snapshot -> %s", req.Label, reference)
 
 	var lastErr error
 	for attempt := 1; attempt <= crustGatherFullAttempts; attempt++ {
-		logger.Log("crust-gather: %s attempt %d/%d", label, attempt, crustGatherFullAttempts)
-		if err := attemptCrustGather(label, attempt, kubeconfig, reference, username, password, stripProxy, extraArgs); err != nil {
+		logger.Log("crust-gather: %s attempt %d/%d", req.Label, attempt, crustGatherFullAttempts)
+		if err := attemptCrustGather(collector, attempt, req); err != nil {
 			lastErr = err
-			logger.Log("crust-gather: %s attempt %d/%d failed: %v", label, attempt, crustGatherFullAttempts, err)
+			logger.Log("crust-gather: %s attempt %d/%d failed: %v", req.Label, attempt, crustGatherFullAttempts, err)
 			continue
 		}
-		logger.Log("crust-gather: %s snapshot pushed to %s (attempt %d/%d)", label, reference, attempt, crustGatherFullAttempts)
-		return "ok"
+		logger.Log("crust-gather: %s snapshot stored at %s (attempt %d/%d)", req.Label, reference, attempt, crustGatherFullAttempts)
+		return snapshot.ResultOK
 	}
 
-	logger.Log("crust-gather: %s all %d attempts failed (%v), retrying once without pod logs", label, crustGatherFullAttempts, lastErr)
-	fallbackArgs := append(append([]string{}, extraArgs...), "--skip-logs-collection")
-	if err := attemptCrustGather(label, crustGatherFullAttempts+1, kubeconfig, reference, username, password, stripProxy, fallbackArgs); err != nil {
-		logger.Log("crust-gather: %s logs-skip fallback also failed, giving up: %v", label, err)
-		return "failed"
+	logger.Log("crust-gather: %s all %d attempts failed (%v), retrying once without pod logs", req.Label, crustGatherFullAttempts, lastErr)
+	fallback := req
+	fallback.Args = append(append([]string{}, req.Args...), "--skip-logs-collection")
+	if err := attemptCrustGather(collector, crustGatherFullAttempts+1, fallback); err != nil {
+		logger.Log("crust-gather: %s logs-skip fallback also failed, giving up: %v", req.Label, err)
+		return snapshot.ResultFailed
 	}
-	logger.Log("crust-gather: %s snapshot pushed to %s (no pod logs)", label, reference)
-	return "degraded"
+	logger.Log("crust-gather: %s snapshot stored at %s (no pod logs)", req.Label, reference)
+	return snapshot.ResultDegraded
 }
 
-// attemptCrustGather runs a single crust-gather collect invocation in its own working
-// directory. attemptNum is used only to keep each attempt's tmpDir unique.
-func attemptCrustGather(label string, attemptNum int, kubeconfig, reference, username, password string, stripProxy bool, extraArgs []string) error {
+// attemptCrustGather runs a single collection in its own working directory. attemptNum
+// is used only to keep each attempt's tmpDir unique.
+func attemptCrustGather(collector SnapshotCollector, attemptNum int, req SnapshotRequest) error {
 	// The command timeout must be larger than the collection duration (5m)
 	// to allow time for the OCI push after collection finishes.
 	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Minute)
 	defer cancel()
 
-	// crust-gather writes collected resources to a local directory before pushing to OCI.
-	// We set cmd.Dir to a unique tmpdir so crust-gather has a writable working directory
+	// crust-gather writes collected resources to a local directory before storing them.
+	// We use a unique tmpdir so crust-gather has a writable working directory
 	// (the container's /app cwd is read-only) and it is cleaned up after the run.
-	// We do not pass -f: crust-gather serve does not support that flag, so layers must
-	// use the default "crust-gather/" prefix for serve --reference to read them.
-	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("crust-gather-%s-%d-", strings.ToLower(label), attemptNum))
+	tmpDir, err := os.MkdirTemp("", fmt.Sprintf("crust-gather-%s-%d-", strings.ToLower(req.Label), attemptNum))
 	if err != nil {
 		return fmt.Errorf("failed to create temp dir: %w", err)
 	}
 	defer os.RemoveAll(tmpDir)
 
-	args := []string{
-		"collect",
-		"--kubeconfig", kubeconfig,
-		"--reference", reference,
-		"--duration", "5m",
-		// Reduce noise: crust-gather's default INFO level emits thousands of
-		// "Pushing layer" messages that drown the test logs. We still surface
-		// genuine warnings and errors via WARN.
-		"--verbosity", "WARN",
-		// Limit concurrent OCI layer uploads to avoid hitting the ACR Basic tier
-		// rate limit. 16 is half the default (32), balancing push speed against
-		// rate limit risk.
-		"--buffer-size", "16",
-	}
-
-	if username != "" && password != "" {
-		args = append(args, "--username", username, "--password", password)
-	}
-
-	args = append(args, extraArgs...)
-
-	cmd := exec.CommandContext(ctx, "crust-gather", args...)
-	cmd.Dir = tmpDir
-	cmd.Stdout = GinkgoWriter
-	cmd.Stderr = GinkgoWriter
-	// For public endpoints, strip proxy env vars: a proxy in the environment triggers
-	// the disabled kube/http-proxy feature gate in crust-gather. For private endpoints
-	// the proxy is needed to route traffic through the VPN to the cluster API server.
-	if stripProxy {
-		cmd.Env = removeProxyEnv(os.Environ())
-	}
-
-	return cmd.Run()
+	return collector.Collect(ctx, tmpDir, req)
 }
 
 // hasPrivateEndpoint reports whether the kubeconfig's API server resolves to a
@@ -690,9 +788,9 @@ func removeProxyEnv(env []string) []string {
 	return result
 }
 
-func cleanupPVs(ctx context.Context) error {
+func cleanupPVs(ctx context.Context, suiteEnv *state.Environment) error {
 	logger.Log("Ensuring all PVs are cleaned up before deleting cluster")
-	wcClient, err := state.GetFramework().WC(state.GetCluster().Name)
+	wcClient, err := suiteEnv.WC()
 	if err != nil {
 		logger.Log("Failed to get WC client, skipping PV cleanup - %v", err)
 		return err
@@ -709,7 +807,7 @@ func cleanupPVs(ctx context.Context) error {
 	for _, pv := range pvs.Items {
 		logger.Log("Deleting PV '%s'...", pv.Name)
 		logger.Log("%v", pv)
-		err := wcClient.Delete(state.GetContext(), &pv, &cr.DeleteOptions{})
+		err := wcClient.Delete(ctx, &pv, &cr.DeleteOptions{})
 		if err != nil && !apierror.IsNotFound(err) {
 			logger.Log("Failed to delete PV '%s' - %v", pv.Name, err)
 		}
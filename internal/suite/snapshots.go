@@ -0,0 +1,285 @@
+package suite
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/snapshot"
+)
+
+const (
+	// EnvSnapshotBackend selects the SnapshotCollector when none is set with
+	// WithSnapshotCollector: "oci" (default), "dir", "tarball" or "none".
+	EnvSnapshotBackend = "E2E_SNAPSHOT_BACKEND"
+	// EnvSnapshotDir is where the "dir" and "tarball" backends write snapshots.
+	// Defaults to a snapshots directory in REPORT_DIR, or the temp directory.
+	EnvSnapshotDir = "E2E_SNAPSHOT_DIR"
+	// EnvCrustGatherRegistry and EnvCrustGatherRepository override the registry and
+	// repository the "oci" backend pushes to.
+	EnvCrustGatherRegistry   = "CRUST_GATHER_REGISTRY"
+	EnvCrustGatherRepository = "CRUST_GATHER_REPOSITORY"
+)
+
+// SnapshotRequest describes a single crust-gather collection.
+type SnapshotRequest struct {
+	// Label identifies the cluster in logs, e.g. "WC".
+	Label string
+	// Kubeconfig is the path of the kubeconfig of the cluster to collect.
+	Kubeconfig string
+	// Tag identifies the snapshot, e.g. "<clusterName>-<suiteSlug>-wc".
+	Tag string
+	// StripProxy removes the proxy env vars from the crust-gather environment.
+	StripProxy bool
+	// Args are extra arguments passed to crust-gather collect.
+	Args []string
+}
+
+// SnapshotCollector stores the crust-gather snapshots taken when a suite fails.
+type SnapshotCollector interface {
+	// Backend identifies where snapshots are stored.
+	Backend() snapshot.Backend
+	// Reference returns the location of the snapshot with the given tag.
+	Reference(tag string) string
+	// Collect runs a single crust-gather collection from workDir and stores the snapshot.
+	// Retries are handled by the caller.
+	Collect(ctx context.Context, workDir string, req SnapshotRequest) error
+}
+
+// WithSnapshotCollector sets the SnapshotCollector used when the suite fails, taking
+// precedence over E2E_SNAPSHOT_BACKEND.
+func WithSnapshotCollector(collector SnapshotCollector) Option {
+	return func(o *Options) { o.SnapshotCollector = collector }
+}
+
+// NewSnapshotCollectorFromEnv returns the SnapshotCollector selected with E2E_SNAPSHOT_BACKEND.
+func NewSnapshotCollectorFromEnv() (SnapshotCollector, error) {
+	backend := snapshot.Backend(strings.ToLower(strings.TrimSpace(os.Getenv(EnvSnapshotBackend))))
+	switch backend {
+	case "", snapshot.BackendOCI:
+		return NewOCISnapshotCollector(), nil
+	case snapshot.BackendDir:
+		return &DirSnapshotCollector{Dir: snapshotDirFromEnv()}, nil
+	case snapshot.BackendTarball:
+		return &DirSnapshotCollector{Dir: snapshotDirFromEnv(), Tarball: true}, nil
+	case snapshot.BackendNone:
+		return NoopSnapshotCollector{}, nil
+	default:
+		return nil, fmt.Errorf("unknown %s %q, expected one of oci, dir, tarball or none", EnvSnapshotBackend, backend)
+	}
+}
+
+func snapshotDirFromEnv() string {
+	if dir := os.Getenv(EnvSnapshotDir); dir != "" {
+		return dir
+	}
+	if reportDir := os.Getenv(snapshot.EnvReportDir); reportDir != "" {
+		return filepath.Join(reportDir, "snapshots")
+	}
+	return filepath.Join(os.TempDir(), "crust-gather-snapshots")
+}
+
+// OCISnapshotCollector pushes snapshots to an OCI registry.
+type OCISnapshotCollector struct {
+	Registry   string
+	Repository string
+	Username   string
+	Password   string
+}
+
+// NewOCISnapshotCollector returns an OCISnapshotCollector pushing to the shared CI registry,
+// unless overridden with CRUST_GATHER_REGISTRY and CRUST_GATHER_REPOSITORY. Credentials are
+// read from CRUST_GATHER_REGISTRY_USERNAME and CRUST_GATHER_REGISTRY_PASSWORD.
+func NewOCISnapshotCollector() *OCISnapshotCollector {
+	c := &OCISnapshotCollector{
+		Registry:   CrustGatherRegistry,
+		Repository: CrustGatherRepository,
+		Username:   os.Getenv("CRUST_GATHER_REGISTRY_USERNAME"),
+		Password:   os.Getenv("CRUST_GATHER_REGISTRY_PASSWORD"),
+	}
+	if registry := os.Getenv(EnvCrustGatherRegistry); registry != "" {
+		c.Registry = registry
+	}
+	if repository := os.Getenv(EnvCrustGatherRepository); repository != "" {
+		c.Repository = repository
+	}
+	return c
+}
+
+func (c *OCISnapshotCollector) Backend() snapshot.Backend { return snapshot.BackendOCI }
+
+func (c *OCISnapshotCollector) Reference(tag string) string {
+	return fmt.Sprintf("%s/%s:%s", c.Registry, c.Repository, tag)
+}
+
+// Collect pushes the snapshot as it is collected.
+// We do not pass -f: crust-gather serve does not support that flag, so layers must
+// use the default "crust-gather/" prefix for serve --reference to read them.
+func (c *OCISnapshotCollector) Collect(ctx context.Context, workDir string, req SnapshotRequest) error {
+	args := []string{
+		"--reference", c.Reference(req.Tag),
+		// Limit concurrent OCI layer uploads to avoid hitting the ACR Basic tier
+		// rate limit. 16 is half the default (32), balancing push speed against
+		// rate limit risk.
+		"--buffer-size", "16",
+	}
+	if c.Username != "" && c.Password != "" {
+		args = append(args, "--username", c.Username, "--password", c.Password)
+	}
+	return runCrustGatherCollect(ctx, workDir, req, args...)
+}
+
+// DirSnapshotCollector writes snapshots to a local directory, either as the directory
+// written by crust-gather or as a gzipped tarball of it. Used where the shared registry
+// isn't reachable, e.g. on forked MCs or in air-gapped environments.
+type DirSnapshotCollector struct {
+	Dir     string
+	Tarball bool
+}
+
+func (c *DirSnapshotCollector) Backend() snapshot.Backend {
+	if c.Tarball {
+		return snapshot.BackendTarball
+	}
+	return snapshot.BackendDir
+}
+
+func (c *DirSnapshotCollector) Reference(tag string) string {
+	if c.Tarball {
+		return filepath.Join(c.Dir, tag+".tar.gz")
+	}
+	return filepath.Join(c.Dir, tag)
+}
+
+func (c *DirSnapshotCollector) Collect(ctx context.Context, workDir string, req SnapshotRequest) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	reference, err := filepath.Abs(c.Reference(req.Tag))
+	if err != nil {
+		return err
+	}
+	// Don't mix the output of a previous attempt into this one.
+	if err := os.RemoveAll(reference); err != nil {
+		return err
+	}
+
+	if !c.Tarball {
+		return runCrustGatherCollect(ctx, workDir, req, "-f", reference)
+	}
+
+	collected := filepath.Join(workDir, "crust-gather")
+	if err := runCrustGatherCollect(ctx, workDir, req, "-f", collected); err != nil {
+		return err
+	}
+	return writeTarball(collected, reference)
+}
+
+// NoopSnapshotCollector disables snapshot collection.
+type NoopSnapshotCollector struct{}
+
+func (NoopSnapshotCollector) Backend() snapshot.Backend { return snapshot.BackendNone }
+
+func (NoopSnapshotCollector) Reference(string) string { return "" }
+
+func (NoopSnapshotCollector) Collect(context.Context, string, SnapshotRequest) error { return nil }
+
+// runCrustGatherCollect runs crust-gather collect from workDir with the arguments common to
+// all backends, followed by backendArgs and the extra arguments of the request.
+func runCrustGatherCollect(ctx context.Context, workDir string, req SnapshotRequest, backendArgs ...string) error {
+	args := []string{
+		"collect",
+		"--kubeconfig", req.Kubeconfig,
+		"--duration", "5m",
+		// Reduce noise: crust-gather's default INFO level emits thousands of
+		// "Pushing layer" messages that drown the test logs. We still surface
+		// genuine warnings and errors via WARN.
+		"--verbosity", "WARN",
+	}
+	args = append(args, backendArgs...)
+	args = append(args, req.Args...)
+
+	cmd := exec.CommandContext(ctx, "crust-gather", args...)
+	cmd.Dir = workDir
+	cmd.Stdout = GinkgoWriter
+	cmd.Stderr = GinkgoWriter
+	// For public endpoints, strip proxy env vars: a proxy in the environment triggers
+	// the disabled kube/http-proxy feature gate in crust-gather. For private endpoints
+	// the proxy is needed to route traffic through the VPN to the cluster API server.
+	if req.StripProxy {
+		cmd.Env = removeProxyEnv(os.Environ())
+	}
+
+	return cmd.Run()
+}
+
+// writeTarball writes the contents of srcDir to a gzipped tarball at dest.
+func writeTarball(srcDir, dest string) (err error) {
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	err = filepath.WalkDir(srcDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+
+		name, err := filepath.Rel(filepath.Dir(srcDir), path)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close() // nolint:errcheck
+		_, err = io.Copy(tw, src)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
@@ -0,0 +1,183 @@
+package suite
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/snapshot"
+)
+
+func TestNewSnapshotCollectorFromEnv(t *testing.T) {
+	testCases := []struct {
+		name        string
+		env         map[string]string
+		expected    SnapshotCollector
+		expectedErr bool
+	}{
+		{
+			name:     "default",
+			expected: &OCISnapshotCollector{Registry: CrustGatherRegistry, Repository: CrustGatherRepository},
+		},
+		{
+			name: "oci with overrides and credentials",
+			env: map[string]string{
+				EnvSnapshotBackend:               " OCI ",
+				EnvCrustGatherRegistry:           "registry.example.com",
+				EnvCrustGatherRepository:         "snapshots",
+				"CRUST_GATHER_REGISTRY_USERNAME": "user",
+				"CRUST_GATHER_REGISTRY_PASSWORD": "password",
+			},
+			expected: &OCISnapshotCollector{Registry: "registry.example.com", Repository: "snapshots", Username: "user", Password: "password"},
+		},
+		{
+			name:     "dir",
+			env:      map[string]string{EnvSnapshotBackend: "dir", EnvSnapshotDir: "/snapshots", snapshot.EnvReportDir: "/reports"},
+			expected: &DirSnapshotCollector{Dir: "/snapshots"},
+		},
+		{
+			name:     "tarball in the report directory",
+			env:      map[string]string{EnvSnapshotBackend: "tarball", snapshot.EnvReportDir: "/reports"},
+			expected: &DirSnapshotCollector{Dir: "/reports/snapshots", Tarball: true},
+		},
+		{
+			name:     "dir in the temp directory",
+			env:      map[string]string{EnvSnapshotBackend: "dir"},
+			expected: &DirSnapshotCollector{Dir: filepath.Join(os.TempDir(), "crust-gather-snapshots")},
+		},
+		{
+			name:     "none",
+			env:      map[string]string{EnvSnapshotBackend: "none"},
+			expected: NoopSnapshotCollector{},
+		},
+		{
+			name:        "unknown",
+			env:         map[string]string{EnvSnapshotBackend: "s3"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, key := range []string{EnvSnapshotBackend, EnvSnapshotDir, snapshot.EnvReportDir, EnvCrustGatherRegistry, EnvCrustGatherRepository, "CRUST_GATHER_REGISTRY_USERNAME", "CRUST_GATHER_REGISTRY_PASSWORD"} {
+				t.Setenv(key, tc.env[key])
+			}
+
+			collector, err := NewSnapshotCollectorFromEnv()
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", collector)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(collector, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, collector)
+			}
+		})
+	}
+}
+
+func TestSnapshotCollectorReference(t *testing.T) {
+	testCases := []struct {
+		collector       SnapshotCollector
+		expectedBackend snapshot.Backend
+		expectedRef     string
+	}{
+		{&OCISnapshotCollector{Registry: "registry.example.com", Repository: "snapshots"}, snapshot.BackendOCI, "registry.example.com/snapshots:t-capa-wc"},
+		{&DirSnapshotCollector{Dir: "/snapshots"}, snapshot.BackendDir, "/snapshots/t-capa-wc"},
+		{&DirSnapshotCollector{Dir: "/snapshots", Tarball: true}, snapshot.BackendTarball, "/snapshots/t-capa-wc.tar.gz"},
+		{NoopSnapshotCollector{}, snapshot.BackendNone, ""},
+	}
+
+	for _, tc := range testCases {
+		if backend := tc.collector.Backend(); backend != tc.expectedBackend {
+			t.Errorf("expected backend %s, got %s", tc.expectedBackend, backend)
+		}
+		if ref := tc.collector.Reference("t-capa-wc"); ref != tc.expectedRef {
+			t.Errorf("expected reference %q, got %q", tc.expectedRef, ref)
+		}
+	}
+}
+
+func TestWriteTarball(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "crust-gather")
+	files := map[string]string{
+		"cluster/namespaces/default.yaml":          "kind: Namespace\n",
+		"cluster/pods/kube-system/coredns.yaml":    "kind: Pod\n",
+		"cluster/pods/kube-system/coredns/log.txt": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Only regular files and directories are archived.
+	if err := os.Symlink("default.yaml", filepath.Join(src, "cluster/namespaces/link.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "t-capa-wc.tar.gz")
+	if err := writeTarball(src, dest); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close() // nolint:errcheck
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	archived := map[string]string{}
+	dirs := 0
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeDir {
+			dirs++
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		archived[header.Name] = string(content)
+	}
+
+	// Entries are relative to the parent of the collected directory, so they keep its name.
+	expected := map[string]string{}
+	for name, content := range files {
+		expected["crust-gather/"+name] = content
+	}
+	if !reflect.DeepEqual(archived, expected) {
+		t.Errorf("expected %v, got %v", expected, archived)
+	}
+	if dirs == 0 {
+		t.Error("expected the directories to be archived")
+	}
+
+	if err := writeTarball(filepath.Join(t.TempDir(), "missing"), filepath.Join(t.TempDir(), "missing.tar.gz")); err == nil {
+		t.Error("expected an error for a missing source directory")
+	}
+}
@@ -0,0 +1,93 @@
+package testenv
+
+import (
+	certmanager "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+)
+
+// ControlPlaneLabel is the label marking control plane nodes.
+const ControlPlaneLabel = "node-role.kubernetes.io/control-plane"
+
+// Node returns a Node with the given Ready condition and labels.
+func Node(name string, ready bool, labels map[string]string) *corev1.Node {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: status},
+			},
+		},
+	}
+}
+
+// ControlPlaneNode returns a Node labelled as a control plane node.
+func ControlPlaneNode(name string, ready bool) *corev1.Node {
+	return Node(name, ready, map[string]string{ControlPlaneLabel: ""})
+}
+
+// Pod returns a Pod in the given phase.
+func Pod(namespace, name string, phase corev1.PodPhase, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Status: corev1.PodStatus{
+			Phase: phase,
+		},
+	}
+}
+
+// MachinePool returns a MachinePool belonging to the given cluster with the given phase
+// and desired/available replicas.
+func MachinePool(namespace, name, clusterName string, phase capi.MachinePoolPhase, desired, available int32) *capi.MachinePool {
+	return &capi.MachinePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				capi.ClusterNameLabel: clusterName,
+			},
+		},
+		Spec: capi.MachinePoolSpec{
+			ClusterName: clusterName,
+			Replicas:    ptr.To(desired),
+		},
+		Status: capi.MachinePoolStatus{
+			Phase:             string(phase),
+			AvailableReplicas: ptr.To(available),
+		},
+	}
+}
+
+// ClusterIssuer returns a cert-manager ClusterIssuer with the given Ready condition.
+func ClusterIssuer(name string, ready bool) *certmanager.ClusterIssuer {
+	status := cmmeta.ConditionFalse
+	if ready {
+		status = cmmeta.ConditionTrue
+	}
+
+	return &certmanager.ClusterIssuer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+		},
+		Status: certmanager.IssuerStatus{
+			Conditions: []certmanager.IssuerCondition{
+				{Type: certmanager.IssuerConditionReady, Status: status},
+			},
+		},
+	}
+}
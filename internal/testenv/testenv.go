@@ -0,0 +1,57 @@
+// Package testenv provides clustertest clients backed by a fake controller-runtime
+// client, so the check functions in internal/common can be unit tested without a cluster.
+//
+// Objects are seeded with the builders in objects.go:
+//
+//	wcClient := testenv.NewClient(
+//		testenv.Node("worker-1", true, nil),
+//		testenv.Pod("kube-system", "coredns", corev1.PodRunning, nil),
+//	)
+package testenv
+
+import (
+	certmanager "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	applicationv1alpha1 "github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+)
+
+// Scheme returns a scheme with all the types the check functions read: core and batch
+// resources, CAPI, cert-manager and Giant Swarm Apps and Catalogs.
+func Scheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(batchv1.AddToScheme(scheme))
+	utilruntime.Must(capi.AddToScheme(scheme))
+	utilruntime.Must(certmanager.AddToScheme(scheme))
+	utilruntime.Must(applicationv1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
+// NewClient returns a clustertest client backed by a fake controller-runtime client
+// seeded with objs.
+func NewClient(objs ...cr.Object) *client.Client {
+	return WrapClient(NewFakeClient(objs...))
+}
+
+// NewFakeClient returns a fake controller-runtime client seeded with objs, for tests
+// that need to tweak the builder, e.g. to inject errors with interceptors.
+func NewFakeClient(objs ...cr.Object) cr.Client {
+	return fake.NewClientBuilder().
+		WithScheme(Scheme()).
+		WithObjects(objs...).
+		WithStatusSubresource(&capi.MachinePool{}, &certmanager.ClusterIssuer{}).
+		Build()
+}
+
+// WrapClient wraps a controller-runtime client in a clustertest client.
+func WrapClient(c cr.Client) *client.Client {
+	return &client.Client{Client: c}
+}
@@ -1,5 +1,7 @@
 package timeout
 
+import "time"
+
 const (
 	// DeployApps is used by "all default apps are deployed without issues"
 	DeployApps TestKey = "deployAppsTimeout"
@@ -7,7 +9,7 @@ const (
 	ClusterReadyTimeout TestKey = "clusterReadyTimeout"
 	// MimirMetrics is used by "ensure key metrics are available on mimir"
 	MimirMetrics TestKey = "mimirMetricsTimeout"
-	// PVCBinding is used by "binds the PVC"
+	// PVCBinding is used by the storage matrix when waiting for a pod to run with its volume bound and mounted
 	PVCBinding TestKey = "pvcBindingTimeout"
 	// CertManager is used by "cert-manager default ClusterIssuers are present and ready"
 	CertManager TestKey = "certManagerTimeout"
@@ -17,4 +19,151 @@ const (
 	ClusterConnection TestKey = "clusterConnectionTimeout"
 	// GatewayAppReady is used by the hello-world gateway app readiness checks
 	GatewayAppReady TestKey = "gatewayAppReadyTimeout"
+	// ClientSetup is used when building the workload cluster client and listing its nodes before a spec
+	ClientSetup TestKey = "clientSetupTimeout"
+	// ControlPlaneNodesReady is used by "has all the control-plane nodes running"
+	ControlPlaneNodesReady TestKey = "controlPlaneNodesReadyTimeout"
+	// WorkerNodesReady is used by "has all the worker nodes running"
+	WorkerNodesReady TestKey = "workerNodesReadyTimeout"
+	// WorkloadsReady is used by the Deployments, StatefulSets, DaemonSets, Jobs and Pods readiness checks
+	WorkloadsReady TestKey = "workloadsReadyTimeout"
+	// MachinePoolsReady is used by "has all machine pools ready and running"
+	MachinePoolsReady TestKey = "machinePoolsReadyTimeout"
+	// ScaleAppReady is used by the scale test when waiting for its hello-world HelmRelease
+	ScaleAppReady TestKey = "scaleAppReadyTimeout"
+	// ScaleUp is used by "scales node by creating anti-affinity pods"
+	ScaleUp TestKey = "scaleUpTimeout"
+	// ScaleDown is used by the scale test when waiting for the cluster-autoscaler to remove the nodes it added,
+	// which it only does after its scale-down delay
+	ScaleDown TestKey = "scaleDownTimeout"
+	// AWSLBControllerBundle is used by "should deploy aws-lb-controller-bundle"
+	AWSLBControllerBundle TestKey = "awsLBControllerBundleTimeout"
+	// GatewayAPIBundle is used by "should deploy gateway-api-bundle"
+	GatewayAPIBundle TestKey = "gatewayAPIBundleTimeout"
+	// GatewayProgrammed is used by "gateway giantswarm-default should be programmed"
+	GatewayProgrammed TestKey = "gatewayProgrammedTimeout"
+	// GatewayDNS is used by "cluster wildcard DNS must be resolvable"
+	GatewayDNS TestKey = "gatewayDNSTimeout"
+	// GatewayCertificate is used by "certificate in envoy-gateway-system should be ready"
+	GatewayCertificate TestKey = "gatewayCertificateTimeout"
+	// HelloWorldReady is used by the hello-world HelmRelease and HTTPRoute readiness checks
+	HelloWorldReady TestKey = "helloWorldReadyTimeout"
+	// HelloWorldResponse is used by "hello world app responds successfully"
+	HelloWorldResponse TestKey = "helloWorldResponseTimeout"
+	// UpgradeApply is used by "should apply new version successfully" when applying the upgraded cluster
+	UpgradeApply TestKey = "upgradeApplyTimeout"
+	// ClusterAppUpgraded is used by "should apply new version successfully" when waiting for the new cluster app
+	ClusterAppUpgraded TestKey = "clusterAppUpgradedTimeout"
+	// ControlPlaneRoll is used by "successfully finishes the control plane nodes rolling update if it is needed"
+	ControlPlaneRoll TestKey = "controlPlaneRollTimeout"
+	// NodeRollDetection is used by "detects if nodes were rolled"
+	NodeRollDetection TestKey = "nodeRollDetectionTimeout"
+	// KarpenterNodePoolsReady is used by "has ready Karpenter NodePools"
+	KarpenterNodePoolsReady TestKey = "karpenterNodePoolsReadyTimeout"
+	// KarpenterScaleUp is used when waiting for Karpenter to provision nodes for pending pods
+	KarpenterScaleUp TestKey = "karpenterScaleUpTimeout"
+	// KarpenterConsolidation is used when waiting for Karpenter to remove nodes that are no longer needed
+	KarpenterConsolidation TestKey = "karpenterConsolidationTimeout"
+	// NetworkPolicy is used by the network policy tests when waiting for traffic to be allowed or blocked
+	NetworkPolicy TestKey = "networkPolicyTimeout"
+	// ProbeReady is used when waiting for the probe pods of the connectivity, DNS and network policy tests to be ready
+	ProbeReady TestKey = "probeReadyTimeout"
+	// Connectivity is used by the connectivity matrix tests when waiting for all calls to succeed
+	Connectivity TestKey = "connectivityTimeout"
+	// InClusterDNS is used by the in-cluster DNS tests when resolving a name from a probe pod
+	InClusterDNS TestKey = "inClusterDNSTimeout"
+	// CertificateIssuance is used by the certificate issuance tests when waiting for a Certificate to be Ready
+	CertificateIssuance TestKey = "certificateIssuanceTimeout"
+	// VolumeExpansion is used by the storage matrix when waiting for an expanded volume to reach its new size
+	VolumeExpansion TestKey = "volumeExpansionTimeout"
+	// VolumeData is used by the storage matrix when reading back the data written to a volume
+	VolumeData TestKey = "volumeDataTimeout"
+	// StorageCleanup is used by the storage matrix when waiting for its namespace, and with it the provisioned volumes, to be deleted
+	StorageCleanup TestKey = "storageCleanupTimeout"
+	// VolumeSnapshot is used by the storage matrix when waiting for a VolumeSnapshot to be ready to use
+	VolumeSnapshot TestKey = "volumeSnapshotTimeout"
+	// PolicyEnforcement is used by the policy enforcement and Pod Security Admission tests when waiting for a Pod to be admitted or denied
+	PolicyEnforcement TestKey = "policyEnforcementTimeout"
+	// ResourceApply is used when creating or deleting the resources deployed by a test, retrying transient API errors
+	ResourceApply TestKey = "resourceApplyTimeout"
+	// NamespaceDeletion is used when waiting for the namespace of a test to be gone before it is reused
+	NamespaceDeletion TestKey = "namespaceDeletionTimeout"
 )
+
+// Definition describes a TestKey and the timeout used when it isn't overridden.
+type Definition struct {
+	Key     TestKey
+	Default time.Duration
+}
+
+// definitions owns the default of every TestKey. Tests must not hard-code their own default.
+var definitions = []Definition{
+	{DeployApps, 15 * time.Minute},
+	{ClusterReadyTimeout, 15 * time.Minute},
+	{MimirMetrics, 10 * time.Minute},
+	{PVCBinding, 5 * time.Minute},
+	{CertManager, 5 * time.Minute},
+	{BundleApps, 5 * time.Minute},
+	{ClusterConnection, 3 * time.Minute},
+	{GatewayAppReady, 5 * time.Minute},
+	{ClientSetup, 1 * time.Minute},
+	{ControlPlaneNodesReady, 15 * time.Minute},
+	{WorkerNodesReady, 15 * time.Minute},
+	{WorkloadsReady, 15 * time.Minute},
+	{MachinePoolsReady, 30 * time.Minute},
+	{ScaleAppReady, 5 * time.Minute},
+	{ScaleUp, 15 * time.Minute},
+	{ScaleDown, 25 * time.Minute},
+	{AWSLBControllerBundle, 15 * time.Minute},
+	{GatewayAPIBundle, 10 * time.Minute},
+	{GatewayProgrammed, 10 * time.Minute},
+	{GatewayDNS, 10 * time.Minute},
+	{GatewayCertificate, 15 * time.Minute},
+	{HelloWorldReady, 6 * time.Minute},
+	{HelloWorldResponse, 15 * time.Minute},
+	{UpgradeApply, 20 * time.Minute},
+	{ClusterAppUpgraded, 10 * time.Minute},
+	{ControlPlaneRoll, 30 * time.Minute},
+	{NodeRollDetection, 15 * time.Minute},
+	{KarpenterNodePoolsReady, 10 * time.Minute},
+	{KarpenterScaleUp, 15 * time.Minute},
+	{KarpenterConsolidation, 20 * time.Minute},
+	{NetworkPolicy, 3 * time.Minute},
+	{ProbeReady, 10 * time.Minute},
+	{Connectivity, 5 * time.Minute},
+	{InClusterDNS, 5 * time.Minute},
+	{CertificateIssuance, 10 * time.Minute},
+	{VolumeExpansion, 10 * time.Minute},
+	{VolumeData, 1 * time.Minute},
+	{StorageCleanup, 15 * time.Minute},
+	{VolumeSnapshot, 10 * time.Minute},
+	{PolicyEnforcement, 3 * time.Minute},
+	{ResourceApply, 1 * time.Minute},
+	{NamespaceDeletion, 5 * time.Minute},
+}
+
+// Keys lists every TestKey that tests support overriding. It is used to reject
+// unknown keys when timeouts are declared outside of Go code, e.g. in a suite manifest.
+var Keys = func() []TestKey {
+	keys := make([]TestKey, 0, len(definitions))
+	for _, d := range definitions {
+		keys = append(keys, d.Key)
+	}
+	return keys
+}()
+
+// Lookup returns the Definition of the given TestKey.
+func Lookup(key TestKey) (Definition, bool) {
+	for _, d := range definitions {
+		if d.Key == key {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// IsKnown reports whether the given TestKey is one of Keys.
+func IsKnown(key TestKey) bool {
+	_, ok := Lookup(key)
+	return ok
+}
@@ -1,11 +1,17 @@
-// package timeout contains types and constants to be used when overriding default test timeouts
+// package timeout contains the registry of test timeouts and the types used when overriding them
 //
-// Each test case that supports overriding the timeout it uses by default will need its own `TestKey` defining
-// in the constants. Once this is available it can be used within the test case like the following:
+// Each test case that supports overriding the timeout it uses will need its own `TestKey` defining
+// in the constants, together with its default in `definitions`. Once this is available it can be
+// used within the test case like the following:
 //
-//	timeout := state.GetTestTimeout(timeout.DeployApps, 15*time.Minute)
+//	timeout := env.Timeout(timeout.DeployApps)
 //
-// To then override the timeout in a specific test sutie you can do so like this following:
+// To then override the timeout in a specific test suite, add it to the suite manifest:
 //
-//	state.SetTestTimeout(timeout.DeployApps, time.Minute*25)
+//	timeouts:
+//	  deployAppsTimeout: 25m
+//
+// or set the E2E_TIMEOUT_OVERRIDES env var, which takes precedence over the suite:
+//
+//	E2E_TIMEOUT_OVERRIDES="deployAppsTimeout=25m,pvcBindingTimeout=10m"
 package timeout
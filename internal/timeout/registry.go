@@ -0,0 +1,178 @@
+package timeout
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EnvOverrides is the env var holding comma-separated timeout overrides,
+// e.g. "deployAppsTimeout=30m,pvcBindingTimeout=10m".
+const EnvOverrides = "E2E_TIMEOUT_OVERRIDES"
+
+// Source identifies where the effective value of a timeout came from.
+type Source string
+
+const (
+	// SourceDefault is the default declared for the TestKey.
+	SourceDefault Source = "default"
+	// SourceSuite is an override from the suite, either its manifest or Go code.
+	SourceSuite Source = "suite"
+	// SourceEnv is an override from the E2E_TIMEOUT_OVERRIDES env var.
+	SourceEnv Source = "env"
+)
+
+// precedence orders sources so an override is only replaced by one of the same or
+// higher precedence. Env overrides win so CI can tune a suite without code changes.
+func (s Source) precedence() int {
+	switch s {
+	case SourceEnv:
+		return 2
+	case SourceSuite:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Effective is the timeout in use for a TestKey.
+type Effective struct {
+	Key      TestKey
+	Duration time.Duration
+	Default  time.Duration
+	Source   Source
+}
+
+type override struct {
+	duration time.Duration
+	source   Source
+}
+
+// Registry resolves the timeout of every TestKey from its default and any overrides.
+type Registry struct {
+	mu        sync.RWMutex
+	overrides map[TestKey]override
+}
+
+// NewRegistry returns a Registry with no overrides.
+func NewRegistry() *Registry {
+	return &Registry{overrides: map[TestKey]override{}}
+}
+
+// Set overrides the timeout of the given TestKey. The override is ignored if the key
+// was already overridden by a source of higher precedence.
+func (r *Registry) Set(key TestKey, d time.Duration, source Source) error {
+	if !IsKnown(key) {
+		return fmt.Errorf("unknown timeout key %q", key)
+	}
+	if d <= 0 {
+		return fmt.Errorf("timeout %q must be positive, got %s", key, d)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.overrides[key]; ok && existing.source.precedence() > source.precedence() {
+		return nil
+	}
+	r.overrides[key] = override{duration: d, source: source}
+	return nil
+}
+
+// Get returns the timeout for the given TestKey. It panics for keys without a
+// Definition as those are a programming error.
+func (r *Registry) Get(key TestKey) time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if o, ok := r.overrides[key]; ok {
+		return o.duration
+	}
+
+	def, ok := Lookup(key)
+	if !ok {
+		panic(fmt.Sprintf("timeout key %q has no definition", key))
+	}
+	return def.Default
+}
+
+// ApplyEnv applies the overrides found in the E2E_TIMEOUT_OVERRIDES env var.
+func (r *Registry) ApplyEnv() error {
+	overrides, err := ParseOverrides(os.Getenv(EnvOverrides))
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", EnvOverrides, err)
+	}
+	for key, d := range overrides {
+		if err := r.Set(key, d, SourceEnv); err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvOverrides, err)
+		}
+	}
+	return nil
+}
+
+// Effective returns the timeout in use for every TestKey, sorted by key.
+func (r *Registry) Effective() []Effective {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	effective := make([]Effective, 0, len(definitions))
+	for _, def := range definitions {
+		e := Effective{Key: def.Key, Duration: def.Default, Default: def.Default, Source: SourceDefault}
+		if o, ok := r.overrides[def.Key]; ok {
+			e.Duration = o.duration
+			e.Source = o.source
+		}
+		effective = append(effective, e)
+	}
+
+	sort.Slice(effective, func(i, j int) bool {
+		return effective[i].Key < effective[j].Key
+	})
+	return effective
+}
+
+// String renders the effective timeouts one per line, marking overridden values with their source.
+func (r *Registry) String() string {
+	var sb strings.Builder
+	for _, e := range r.Effective() {
+		if e.Source == SourceDefault {
+			fmt.Fprintf(&sb, "%s=%s\n", e.Key, e.Duration)
+		} else {
+			fmt.Fprintf(&sb, "%s=%s (%s, default %s)\n", e.Key, e.Duration, e.Source, e.Default)
+		}
+	}
+	return sb.String()
+}
+
+// ParseOverrides parses comma-separated "key=duration" pairs, e.g. "deployAppsTimeout=30m".
+// Unknown keys, keys given more than once and invalid durations are rejected.
+func ParseOverrides(value string) (map[TestKey]time.Duration, error) {
+	overrides := map[TestKey]time.Duration{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, rawDuration, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected key=duration, got %q", pair)
+		}
+
+		key := TestKey(strings.TrimSpace(name))
+		if !IsKnown(key) {
+			return nil, fmt.Errorf("unknown timeout key %q", key)
+		}
+		if _, ok := overrides[key]; ok {
+			return nil, fmt.Errorf("duplicate timeout key %q", key)
+		}
+
+		d, err := time.ParseDuration(strings.TrimSpace(rawDuration))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration for timeout %q: %w", key, err)
+		}
+		overrides[key] = d
+	}
+	return overrides, nil
+}
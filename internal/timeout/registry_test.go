@@ -0,0 +1,166 @@
+package timeout
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseOverrides(t *testing.T) {
+	testCases := []struct {
+		name        string
+		value       string
+		expected    map[TestKey]time.Duration
+		expectedErr string
+	}{
+		{
+			name:     "empty",
+			value:    "",
+			expected: map[TestKey]time.Duration{},
+		},
+		{
+			name:  "multiple with whitespace",
+			value: " deployAppsTimeout = 30m, pvcBindingTimeout=90s,",
+			expected: map[TestKey]time.Duration{
+				DeployApps: 30 * time.Minute,
+				PVCBinding: 90 * time.Second,
+			},
+		},
+		{
+			name:        "missing separator",
+			value:       "deployAppsTimeout:30m",
+			expectedErr: "expected key=duration",
+		},
+		{
+			name:        "invalid duration",
+			value:       "deployAppsTimeout=30",
+			expectedErr: `invalid duration for timeout "deployAppsTimeout"`,
+		},
+		{
+			name:        "unknown key",
+			value:       "deployAppsTimeout=30m,fooTimeout=1m",
+			expectedErr: `unknown timeout key "fooTimeout"`,
+		},
+		{
+			name:        "duplicate key",
+			value:       "deployAppsTimeout=30m,pvcBindingTimeout=1m,deployAppsTimeout=40m",
+			expectedErr: `duplicate timeout key "deployAppsTimeout"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			overrides, err := ParseOverrides(tc.value)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Fatalf("expected an error containing %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(overrides, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, overrides)
+			}
+		})
+	}
+}
+
+func TestRegistryPrecedence(t *testing.T) {
+	def, ok := Lookup(DeployApps)
+	if !ok {
+		t.Fatal("expected a definition for DeployApps")
+	}
+
+	r := NewRegistry()
+	if d := r.Get(DeployApps); d != def.Default {
+		t.Errorf("expected the default %s, got %s", def.Default, d)
+	}
+
+	if err := r.Set(DeployApps, 20*time.Minute, SourceSuite); err != nil {
+		t.Fatal(err)
+	}
+	if d := r.Get(DeployApps); d != 20*time.Minute {
+		t.Errorf("expected the suite override to replace the default, got %s", d)
+	}
+
+	if err := r.Set(DeployApps, 30*time.Minute, SourceEnv); err != nil {
+		t.Fatal(err)
+	}
+	if d := r.Get(DeployApps); d != 30*time.Minute {
+		t.Errorf("expected the env override to replace the suite override, got %s", d)
+	}
+
+	// A suite setting its timeout after the env was applied must not win.
+	if err := r.Set(DeployApps, 25*time.Minute, SourceSuite); err != nil {
+		t.Fatal(err)
+	}
+	if d := r.Get(DeployApps); d != 30*time.Minute {
+		t.Errorf("expected the env override to be kept, got %s", d)
+	}
+
+	if err := r.Set(DeployApps, 35*time.Minute, SourceEnv); err != nil {
+		t.Fatal(err)
+	}
+	if d := r.Get(DeployApps); d != 35*time.Minute {
+		t.Errorf("expected a later env override to replace the earlier one, got %s", d)
+	}
+
+	for _, e := range r.Effective() {
+		switch e.Key {
+		case DeployApps:
+			if e.Source != SourceEnv || e.Duration != 35*time.Minute || e.Default != def.Default {
+				t.Errorf("unexpected effective timeout for %s: %+v", e.Key, e)
+			}
+		default:
+			if e.Source != SourceDefault || e.Duration != e.Default {
+				t.Errorf("expected %s to use its default, got %+v", e.Key, e)
+			}
+		}
+	}
+
+	if err := r.Set("fooTimeout", time.Minute, SourceSuite); err == nil {
+		t.Error("expected an unknown key to be rejected")
+	}
+	if err := r.Set(PVCBinding, 0, SourceSuite); err == nil {
+		t.Error("expected a zero timeout to be rejected")
+	}
+}
+
+func TestRegistryApplyEnv(t *testing.T) {
+	t.Setenv(EnvOverrides, "deployAppsTimeout=30m,pvcBindingTimeout=10m")
+
+	r := NewRegistry()
+	if err := r.Set(DeployApps, 20*time.Minute, SourceSuite); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Set(CertManager, 20*time.Minute, SourceSuite); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.ApplyEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[TestKey]time.Duration{
+		DeployApps:  30 * time.Minute,
+		PVCBinding:  10 * time.Minute,
+		CertManager: 20 * time.Minute,
+	}
+	for key, d := range expected {
+		if actual := r.Get(key); actual != d {
+			t.Errorf("expected %s=%s, got %s", key, d, actual)
+		}
+	}
+	if s := r.String(); !strings.Contains(s, "deployAppsTimeout=30m0s (env, default 15m0s)\n") {
+		t.Errorf("expected the env override in the rendered timeouts, got:\n%s", s)
+	}
+
+	for _, value := range []string{"deployAppsTimeout=30m,deployAppsTimeout=40m", "fooTimeout=1m", "pvcBindingTimeout=-1m"} {
+		t.Setenv(EnvOverrides, value)
+		if err := NewRegistry().ApplyEnv(); err == nil || !strings.Contains(err.Error(), EnvOverrides) {
+			t.Errorf("expected %q to be rejected, got %v", value, err)
+		}
+	}
+}
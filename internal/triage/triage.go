@@ -0,0 +1,232 @@
+// Package triage summarises the failures of a suite run by owning team.
+//
+// The owning teams are taken from the TEAM report entries added by
+// helper.SetResponsibleTeam, falling back to the SUITE_TEAM entry of the suite manifest.
+// Apps and HelmReleases recorded with helper.RecordFailingResource are listed under the
+// spec they failed in, with their last error condition.
+package triage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/onsi/ginkgo/v2/types"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
+)
+
+const (
+	// JSONFileName and MarkdownFileName are the triage files written to the report directory.
+	JSONFileName     = "triage.json"
+	MarkdownFileName = "triage.md"
+
+	// Unassigned groups the failures that no team could be found for.
+	Unassigned helper.Team = "Unassigned"
+
+	teamEntry      = "TEAM"
+	suiteTeamEntry = "SUITE_TEAM"
+)
+
+// Report is the failure triage of a suite run.
+type Report struct {
+	Suite string         `json:"suite"`
+	Teams []TeamFailures `json:"teams"`
+}
+
+// TeamFailures are the failed specs owned by a team.
+type TeamFailures struct {
+	Team    helper.Team  `json:"team"`
+	SlackID string       `json:"slackId,omitempty"`
+	Specs   []FailedSpec `json:"specs"`
+}
+
+// FailedSpec is a failed spec, or a failed suite node such as BeforeSuite.
+type FailedSpec struct {
+	Name      string                   `json:"name"`
+	State     string                   `json:"state"`
+	Message   string                   `json:"message,omitempty"`
+	Location  string                   `json:"location,omitempty"`
+	Resources []helper.FailingResource `json:"resources,omitempty"`
+}
+
+// Build groups the failed specs of report by owning team. A spec owned by several teams
+// is listed under each of them, with the failing resources of that team and those without
+// a team.
+func Build(report types.Report) Report {
+	suiteTeam := Unassigned
+	for _, spec := range report.SpecReports {
+		if teams := entryValues(spec, suiteTeamEntry); len(teams) > 0 {
+			suiteTeam = helper.Team(teams[0])
+			break
+		}
+	}
+
+	byTeam := map[helper.Team][]FailedSpec{}
+	for _, spec := range report.SpecReports {
+		if !spec.State.Is(types.SpecStateFailureStates) {
+			continue
+		}
+
+		teams := []helper.Team{}
+		for _, team := range entryValues(spec, teamEntry) {
+			teams = appendUnique(teams, helper.Team(team))
+		}
+		if len(teams) == 0 {
+			if suiteTeams := entryValues(spec, suiteTeamEntry); len(suiteTeams) > 0 {
+				teams = append(teams, helper.Team(suiteTeams[0]))
+			} else {
+				teams = append(teams, suiteTeam)
+			}
+		}
+
+		resources := failingResources(spec)
+		for _, team := range teams {
+			failed := FailedSpec{
+				Name:     specName(spec),
+				State:    spec.State.String(),
+				Message:  spec.Failure.Message,
+				Location: spec.Failure.Location.String(),
+			}
+			if spec.Failure.Location.FileName == "" {
+				failed.Location = ""
+			}
+			for _, resource := range resources {
+				if resource.Team == "" || resource.Team == team {
+					failed.Resources = append(failed.Resources, resource)
+				}
+			}
+			byTeam[team] = append(byTeam[team], failed)
+		}
+	}
+
+	triage := Report{Suite: report.SuiteDescription, Teams: []TeamFailures{}}
+	for team, specs := range byTeam {
+		triage.Teams = append(triage.Teams, TeamFailures{
+			Team:    team,
+			SlackID: helper.TeamSlackID(team),
+			Specs:   specs,
+		})
+	}
+	sort.Slice(triage.Teams, func(i, j int) bool {
+		// Keep the failures without an owner at the end.
+		if (triage.Teams[i].Team == Unassigned) != (triage.Teams[j].Team == Unassigned) {
+			return triage.Teams[j].Team == Unassigned
+		}
+		return triage.Teams[i].Team < triage.Teams[j].Team
+	})
+	return triage
+}
+
+// Markdown renders the triage as a Markdown document.
+func (r Report) Markdown() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# Failure triage: %s\n\n", r.Suite)
+	if len(r.Teams) == 0 {
+		b.WriteString("No failures.\n")
+		return b.String()
+	}
+
+	for _, team := range r.Teams {
+		fmt.Fprintf(b, "## %s", team.Team)
+		if team.SlackID != "" {
+			fmt.Fprintf(b, " (Slack `%s`)", team.SlackID)
+		}
+		fmt.Fprintf(b, "\n\n")
+
+		for _, spec := range team.Specs {
+			fmt.Fprintf(b, "### %s\n\n", spec.Name)
+			fmt.Fprintf(b, "- State: %s\n", spec.State)
+			if spec.Location != "" {
+				fmt.Fprintf(b, "- Location: `%s`\n", spec.Location)
+			}
+			for _, resource := range spec.Resources {
+				fmt.Fprintf(b, "- %s\n", resource)
+			}
+			if spec.Message != "" {
+				fmt.Fprintf(b, "\n```\n%s\n```\n", strings.TrimSpace(spec.Message))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// Write writes the JSON and Markdown triage files to dir.
+func Write(dir string, r Report) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, JSONFileName), data, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, MarkdownFileName), []byte(r.Markdown()), 0o644)
+}
+
+func specName(spec types.SpecReport) string {
+	if spec.LeafNodeType == types.NodeTypeIt {
+		return spec.FullText()
+	}
+	if spec.FullText() == "" {
+		return fmt.Sprintf("[%s]", spec.LeafNodeType)
+	}
+	return fmt.Sprintf("[%s] %s", spec.LeafNodeType, spec.FullText())
+}
+
+func failingResources(spec types.SpecReport) []helper.FailingResource {
+	resources := []helper.FailingResource{}
+	for _, entry := range spec.ReportEntries {
+		if entry.Name != helper.FailingResourceEntry {
+			continue
+		}
+		resource := helper.FailingResource{}
+		if err := decodeEntry(entry, &resource); err == nil {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+func entryValues(spec types.SpecReport, name string) []string {
+	values := []string{}
+	for _, entry := range spec.ReportEntries {
+		if entry.Name != name {
+			continue
+		}
+		value := ""
+		if err := decodeEntry(entry, &value); err == nil && value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// decodeEntry decodes the value of a report entry. Entries read back from a JSON report
+// only carry their JSON encoding, while entries of the running suite carry the raw value.
+func decodeEntry(entry types.ReportEntry, v interface{}) error {
+	data := entry.Value.AsJSON
+	if data == "" {
+		raw, err := json.Marshal(entry.Value.GetRawValue())
+		if err != nil {
+			return err
+		}
+		data = string(raw)
+	}
+	return json.Unmarshal([]byte(data), v)
+}
+
+func appendUnique(teams []helper.Team, team helper.Team) []helper.Team {
+	for _, t := range teams {
+		if t == team {
+			return teams
+		}
+	}
+	return append(teams, team)
+}
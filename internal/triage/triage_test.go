@@ -0,0 +1,121 @@
+package triage
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/onsi/ginkgo/v2/types"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/helper"
+)
+
+func entry(name string, value interface{}) types.ReportEntry {
+	return types.ReportEntry{Name: name, Value: types.WrapEntryValue(value)}
+}
+
+func spec(text string, state types.SpecState, entries ...types.ReportEntry) types.SpecReport {
+	return types.SpecReport{
+		LeafNodeType:  types.NodeTypeIt,
+		LeafNodeText:  text,
+		State:         state,
+		ReportEntries: entries,
+		Failure:       types.Failure{Message: text + " failed"},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	report := types.Report{
+		SuiteDescription: "CAPA Standard Suite",
+		SpecReports: types.SpecReports{
+			{LeafNodeType: types.NodeTypeBeforeSuite, State: types.SpecStatePassed},
+			spec("passes", types.SpecStatePassed,
+				entry(suiteTeamEntry, "Phoenix")),
+			spec("has apps deployed", types.SpecStateFailed,
+				entry(suiteTeamEntry, "Phoenix"),
+				entry(teamEntry, "Atlas"),
+				entry(teamEntry, "Shield"),
+				entry(teamEntry, "Atlas"),
+				entry(helper.FailingResourceEntry, helper.FailingResource{Kind: "App", Name: "prometheus-agent", Team: helper.TeamAtlas, Reason: "failed"}),
+				entry(helper.FailingResourceEntry, helper.FailingResource{Kind: "HelmRelease", Name: "kyverno", Team: helper.TeamShield, Reason: "InstallFailed"}),
+				entry(helper.FailingResourceEntry, helper.FailingResource{Kind: "HelmRelease", Name: "unowned", Reason: "NoReadyCondition"}),
+			),
+			spec("has nodes ready", types.SpecStateTimedout,
+				entry(suiteTeamEntry, "Phoenix")),
+			{LeafNodeType: types.NodeTypeAfterSuite, State: types.SpecStatePanicked},
+		},
+	}
+
+	triage := Build(report)
+
+	got := map[helper.Team][]string{}
+	for _, team := range triage.Teams {
+		for _, s := range team.Specs {
+			resources := []string{}
+			for _, r := range s.Resources {
+				resources = append(resources, r.Name)
+			}
+			got[team.Team] = append(got[team.Team], s.Name+"="+strings.Join(resources, ","))
+		}
+	}
+
+	expected := map[helper.Team][]string{
+		helper.TeamAtlas:   {"has apps deployed=prometheus-agent,unowned"},
+		helper.TeamShield:  {"has apps deployed=kyverno,unowned"},
+		helper.TeamPhoenix: {"has nodes ready=", "[AfterSuite]="},
+	}
+	gotJSON, _ := json.Marshal(got)
+	expectedJSON, _ := json.Marshal(expected)
+	if string(gotJSON) != string(expectedJSON) {
+		t.Fatalf("expected %s, got %s", expectedJSON, gotJSON)
+	}
+
+	order := []helper.Team{}
+	for _, team := range triage.Teams {
+		order = append(order, team.Team)
+	}
+	if fmt.Sprint(order) != "[Atlas Phoenix Shield]" {
+		t.Errorf("expected teams sorted by name, got %v", order)
+	}
+	if triage.Teams[0].SlackID == "" {
+		t.Errorf("expected the Slack ID of %s from the team registry", triage.Teams[0].Team)
+	}
+}
+
+func TestBuildUnassigned(t *testing.T) {
+	report := types.Report{
+		SpecReports: types.SpecReports{
+			{LeafNodeType: types.NodeTypeBeforeSuite, State: types.SpecStateFailed},
+		},
+	}
+
+	triage := Build(report)
+	if len(triage.Teams) != 1 || triage.Teams[0].Team != Unassigned {
+		t.Fatalf("expected a single %s team, got %+v", Unassigned, triage.Teams)
+	}
+	if !strings.Contains(triage.Markdown(), "## Unassigned") {
+		t.Errorf("expected the Markdown to contain the Unassigned team:\n%s", triage.Markdown())
+	}
+}
+
+func TestBuildFromJSONReport(t *testing.T) {
+	data, err := json.Marshal(types.Report{
+		SpecReports: types.SpecReports{
+			spec("fails", types.SpecStateFailed, entry(teamEntry, "Tenet")),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := types.Report{}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	triage := Build(report)
+	if len(triage.Teams) != 1 || triage.Teams[0].Team != helper.TeamTenet {
+		t.Fatalf("expected the Tenet team, got %+v", triage.Teams)
+	}
+}
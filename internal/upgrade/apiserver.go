@@ -0,0 +1,247 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+)
+
+const (
+	apiServerWatchInterval   = 1 * time.Second
+	apiServerRequestTimeout  = 2 * time.Second
+	apiServerCheckReadyz     = "readyz"
+	apiServerCheckNamespaced = "namespaced-get"
+)
+
+// apiServerClient calls the workload cluster API server directly, with the CAPI kubeconfig
+// of the cluster. The kubeconfig is reloaded when its credentials expire, as the token in the
+// kubeconfig of EKS clusters does during long upgrades.
+type apiServerClient struct {
+	mcClient    *client.Client
+	clusterName string
+	namespace   string
+
+	mu        sync.Mutex
+	clientset kubernetes.Interface
+}
+
+func newAPIServerClient(ctx context.Context, mcClient *client.Client, clusterName, namespace string) (*apiServerClient, error) {
+	c := &apiServerClient{mcClient: mcClient, clusterName: clusterName, namespace: namespace}
+	if _, err := c.reload(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *apiServerClient) reload(ctx context.Context) (kubernetes.Interface, error) {
+	secret := &corev1.Secret{}
+	err := c.mcClient.Get(ctx, cr.ObjectKey{Name: fmt.Sprintf("%s-kubeconfig", c.clusterName), Namespace: c.namespace}, secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CAPI kubeconfig secret: %w", err)
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig(secret.Data["value"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CAPI kubeconfig: %w", err)
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clientset = clientset
+	return clientset, nil
+}
+
+// Do runs request with the given timeout. A request rejected as Unauthorized is retried
+// once with a reloaded kubeconfig.
+func (c *apiServerClient) Do(ctx context.Context, timeout time.Duration, request func(context.Context, kubernetes.Interface) error) error {
+	c.mu.Lock()
+	clientset := c.clientset
+	c.mu.Unlock()
+
+	requestCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := request(requestCtx, clientset)
+	if !apierror.IsUnauthorized(err) {
+		return err
+	}
+
+	logger.Log("API server rejected the CAPI kubeconfig credentials, reloading them")
+	clientset, err = c.reload(ctx)
+	if err != nil {
+		return err
+	}
+
+	retryCtx, cancelRetry := context.WithTimeout(ctx, timeout)
+	defer cancelRetry()
+	return request(retryCtx, clientset)
+}
+
+// Ready calls the /readyz endpoint of the API server.
+func (c *apiServerClient) Ready(ctx context.Context) error {
+	return c.Do(ctx, apiServerRequestTimeout, readyz)
+}
+
+func readyz(ctx context.Context, clientset kubernetes.Interface) error {
+	_, err := clientset.CoreV1().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx)
+	return err
+}
+
+// apiServerWatcher sends lightweight requests to the API server until it is stopped,
+// recording the error windows in a timeline and the latency of the successful requests.
+type apiServerWatcher struct {
+	apiServer *apiServerClient
+	timeline  *availabilityTimeline
+
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func startAPIServerWatcher(ctx context.Context, apiServer *apiServerClient) *apiServerWatcher {
+	ctx, cancel := context.WithCancel(ctx)
+	w := &apiServerWatcher{
+		apiServer: apiServer,
+		timeline:  newAvailabilityTimeline([]string{apiServerCheckReadyz, apiServerCheckNamespaced}, time.Now()),
+		latencies: map[string][]time.Duration{},
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+
+	logger.Log("Starting the API server availability watcher")
+	go w.run(ctx)
+	return w
+}
+
+func (w *apiServerWatcher) run(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(apiServerWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		w.check(ctx, apiServerCheckReadyz, readyz)
+		w.check(ctx, apiServerCheckNamespaced, func(ctx context.Context, clientset kubernetes.Interface) error {
+			_, err := clientset.CoreV1().Services(metav1.NamespaceDefault).Get(ctx, "kubernetes", metav1.GetOptions{})
+			return err
+		})
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *apiServerWatcher) check(ctx context.Context, name string, request func(context.Context, kubernetes.Interface) error) {
+	start := time.Now()
+	err := w.apiServer.Do(ctx, apiServerRequestTimeout, request)
+	if ctx.Err() != nil {
+		// Stopped while the request was in flight.
+		return
+	}
+
+	w.timeline.record(name, start, err)
+	if err == nil {
+		w.mu.Lock()
+		w.latencies[name] = append(w.latencies[name], time.Since(start))
+		w.mu.Unlock()
+	}
+}
+
+// Stop stops watching and returns the results. It is safe to call more than once.
+func (w *apiServerWatcher) Stop() *apiServerWatchResult {
+	w.cancel()
+	<-w.done
+	w.timeline.finish(time.Now())
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	result := &apiServerWatchResult{Timeline: w.timeline, Latencies: map[string]latencySummary{}}
+	for name, latencies := range w.latencies {
+		result.Latencies[name] = summarizeLatencies(latencies)
+	}
+	return result
+}
+
+// apiServerWatchResult holds the error windows and the latency of every check of the watcher.
+type apiServerWatchResult struct {
+	Timeline  *availabilityTimeline
+	Latencies map[string]latencySummary
+}
+
+func (r *apiServerWatchResult) String() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "Latency of successful requests:\n")
+	for _, name := range r.Timeline.Targets {
+		fmt.Fprintf(b, "  %s: %s\n", name, r.Latencies[name])
+	}
+	b.WriteString(r.Timeline.String())
+	return b.String()
+}
+
+// latencySummary holds the percentiles of a set of request latencies.
+type latencySummary struct {
+	Count int
+	P50   time.Duration
+	P90   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+func (s latencySummary) String() string {
+	if s.Count == 0 {
+		return "no successful requests"
+	}
+	return fmt.Sprintf("p50 %s, p90 %s, p99 %s, max %s (%d requests)",
+		s.P50.Round(time.Millisecond), s.P90.Round(time.Millisecond), s.P99.Round(time.Millisecond), s.Max.Round(time.Millisecond), s.Count)
+}
+
+func summarizeLatencies(latencies []time.Duration) latencySummary {
+	if len(latencies) == 0 {
+		return latencySummary{}
+	}
+
+	sorted := append([]time.Duration{}, latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return latencySummary{
+		Count: len(sorted),
+		P50:   percentile(sorted, 50),
+		P90:   percentile(sorted, 90),
+		P99:   percentile(sorted, 99),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// percentile returns the nearest-rank percentile p of the sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
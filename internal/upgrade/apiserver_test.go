@@ -0,0 +1,44 @@
+package upgrade
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeLatencies(t *testing.T) {
+	latencies := []time.Duration{}
+	// 100 latencies from 100ms down to 1ms, in reverse order to check they get sorted.
+	for i := 100; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	summary := summarizeLatencies(latencies)
+	expected := latencySummary{
+		Count: 100,
+		P50:   50 * time.Millisecond,
+		P90:   90 * time.Millisecond,
+		P99:   99 * time.Millisecond,
+		Max:   100 * time.Millisecond,
+	}
+	if summary != expected {
+		t.Errorf("expected %+v, got %+v", expected, summary)
+	}
+	if latencies[0] != 100*time.Millisecond {
+		t.Errorf("expected the latencies not to be sorted in place")
+	}
+
+	if s := summary.String(); s != "p50 50ms, p90 90ms, p99 99ms, max 100ms (100 requests)" {
+		t.Errorf("unexpected summary %q", s)
+	}
+}
+
+func TestSummarizeLatenciesSingleRequest(t *testing.T) {
+	summary := summarizeLatencies([]time.Duration{7 * time.Millisecond})
+	if summary.P50 != 7*time.Millisecond || summary.P99 != 7*time.Millisecond || summary.Max != 7*time.Millisecond {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	if s := summarizeLatencies(nil).String(); s != "no successful requests" {
+		t.Errorf("unexpected summary %q", s)
+	}
+}
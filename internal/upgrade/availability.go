@@ -0,0 +1,422 @@
+package upgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	utilexec "k8s.io/client-go/util/exec"
+	"k8s.io/utils/ptr"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+)
+
+const (
+	availabilityNamespace = "test-upgrade-availability"
+	availabilityName      = "availability"
+	availabilityContainer = "availability"
+	availabilityPort      = 8080
+	availabilityImage     = "gsoci.azurecr.io/giantswarm/nginx-unprivileged:1.31-alpine"
+	availabilityReplicas  = 3
+
+	availabilityInterval       = 2 * time.Second
+	availabilityRequestTimeout = 3 * time.Second
+
+	// availabilityTargetInCluster calls the Service from one of the app pods.
+	availabilityTargetInCluster = "in-cluster"
+	// availabilityTargetAPIServer calls the Service through the API server Service proxy.
+	availabilityTargetAPIServer = "api-server"
+)
+
+var availabilityLabels = map[string]string{"app.kubernetes.io/name": availabilityName}
+
+// availabilityObjects returns the namespace, the replicated app, its PodDisruptionBudget
+// and Service. The replicas prefer different nodes and the PodDisruptionBudget allows only
+// one of them to be evicted at a time, so a well-behaved node roll never takes the app down.
+func availabilityObjects() []cr.Object {
+	user := int64(101)
+
+	return []cr.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: availabilityNamespace}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      availabilityName,
+				Namespace: availabilityNamespace,
+				Labels:    availabilityLabels,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: ptr.To(int32(availabilityReplicas)),
+				Selector: &metav1.LabelSelector{MatchLabels: availabilityLabels},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: availabilityLabels},
+					Spec: corev1.PodSpec{
+						Affinity: &corev1.Affinity{
+							PodAntiAffinity: &corev1.PodAntiAffinity{
+								PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+									{
+										Weight: 100,
+										PodAffinityTerm: corev1.PodAffinityTerm{
+											LabelSelector: &metav1.LabelSelector{MatchLabels: availabilityLabels},
+											TopologyKey:   "kubernetes.io/hostname",
+										},
+									},
+								},
+							},
+						},
+						SecurityContext: &corev1.PodSecurityContext{
+							RunAsUser:    &user,
+							RunAsNonRoot: ptr.To(true),
+							SeccompProfile: &corev1.SeccompProfile{
+								Type: corev1.SeccompProfileTypeRuntimeDefault,
+							},
+						},
+						Containers: []corev1.Container{
+							{
+								Name:  availabilityContainer,
+								Image: availabilityImage,
+								Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: availabilityPort}},
+								ReadinessProbe: &corev1.Probe{
+									ProbeHandler: corev1.ProbeHandler{
+										HTTPGet: &corev1.HTTPGetAction{Path: "/", Port: intstr.FromInt32(availabilityPort)},
+									},
+								},
+								SecurityContext: &corev1.SecurityContext{
+									Capabilities: &corev1.Capabilities{
+										Drop: []corev1.Capability{"ALL"},
+									},
+									AllowPrivilegeEscalation: ptr.To(false),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		&policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: availabilityName, Namespace: availabilityNamespace},
+			Spec: policyv1.PodDisruptionBudgetSpec{
+				MaxUnavailable: ptr.To(intstr.FromInt32(1)),
+				Selector:       &metav1.LabelSelector{MatchLabels: availabilityLabels},
+			},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: availabilityName, Namespace: availabilityNamespace},
+			Spec: corev1.ServiceSpec{
+				Selector: availabilityLabels,
+				Ports: []corev1.ServicePort{
+					{Name: "http", Port: availabilityPort, TargetPort: intstr.FromInt32(availabilityPort)},
+				},
+			},
+		},
+	}
+}
+
+func createAvailabilityApp(ctx context.Context, wcClient *client.Client) error {
+	for _, obj := range availabilityObjects() {
+		logger.Log("Creating %T '%s'", obj, obj.GetName())
+		err := wcClient.Create(ctx, obj)
+		if err != nil && !apierror.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAvailabilityAppReady returns a function that succeeds once all replicas of the app are ready.
+func checkAvailabilityAppReady(ctx context.Context, wcClient *client.Client) func() error {
+	return func() error {
+		deployment := &appsv1.Deployment{}
+		err := wcClient.Get(ctx, cr.ObjectKey{Name: availabilityName, Namespace: availabilityNamespace}, deployment)
+		if err != nil {
+			return err
+		}
+		if deployment.Status.ReadyReplicas != availabilityReplicas {
+			logger.Log("Deployment '%s' has %d/%d ready replicas", availabilityName, deployment.Status.ReadyReplicas, availabilityReplicas)
+			return fmt.Errorf("deployment %s is not ready", availabilityName)
+		}
+		return nil
+	}
+}
+
+func deleteAvailabilityNamespace(ctx context.Context, wcClient *client.Client) error {
+	logger.Log("Deleting Namespace '%s'", availabilityNamespace)
+	err := wcClient.Delete(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: availabilityNamespace}})
+	if err != nil && !apierror.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// errProbeUnavailable marks a probe that couldn't be run, e.g. because the exec into an app
+// pod failed. It doesn't tell anything about the availability of the app.
+var errProbeUnavailable = errors.New("probe unavailable")
+
+// availabilityProber calls the app on every target until it is stopped, recording the
+// results in its timeline.
+type availabilityProber struct {
+	wcClient  *client.Client
+	apiServer *apiServerClient
+	timeline  *availabilityTimeline
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startAvailabilityProber starts probing in the background. apiServer may be nil, in which
+// case the app is only called in-cluster.
+func startAvailabilityProber(ctx context.Context, wcClient *client.Client, apiServer *apiServerClient) *availabilityProber {
+	targets := []string{availabilityTargetInCluster}
+	if apiServer != nil {
+		targets = append(targets, availabilityTargetAPIServer)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	p := &availabilityProber{
+		wcClient:  wcClient,
+		apiServer: apiServer,
+		timeline:  newAvailabilityTimeline(targets, time.Now()),
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+
+	logger.Log("Starting the availability prober for targets %v", targets)
+	go p.run(ctx)
+	return p
+}
+
+func (p *availabilityProber) run(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(availabilityInterval)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		p.timeline.record(availabilityTargetInCluster, time.Now(), p.probeInCluster(ctx, i))
+		if p.apiServer != nil {
+			p.timeline.record(availabilityTargetAPIServer, time.Now(), probeAPIServer(ctx, p.apiServer))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop stops probing and returns the timeline. It is safe to call more than once.
+func (p *availabilityProber) Stop() *availabilityTimeline {
+	p.cancel()
+	<-p.done
+	p.timeline.finish(time.Now())
+	return p.timeline
+}
+
+// probeInCluster calls the Service from one of the ready app pods, rotating between them.
+func (p *availabilityProber) probeInCluster(ctx context.Context, i int) error {
+	pods := &corev1.PodList{}
+	err := p.wcClient.List(ctx, pods, cr.InNamespace(availabilityNamespace), cr.MatchingLabels(availabilityLabels))
+	if err != nil {
+		return fmt.Errorf("%w: failed to list pods: %s", errProbeUnavailable, err)
+	}
+
+	ready := []corev1.Pod{}
+	for _, pod := range pods.Items {
+		if isPodReady(pod) {
+			ready = append(ready, pod)
+		}
+	}
+	if len(ready) == 0 {
+		return fmt.Errorf("no ready pods")
+	}
+	sort.Slice(ready, func(i, j int) bool { return ready[i].Name < ready[j].Name })
+	pod := ready[i%len(ready)]
+
+	url := fmt.Sprintf("http://%s.%s.svc.cluster.local:%d/", availabilityName, availabilityNamespace, availabilityPort)
+	cmd := []string{"wget", "-q", "-O", "/dev/null", "-T", fmt.Sprint(int(availabilityRequestTimeout.Seconds())), url}
+	_, stderr, err := p.wcClient.ExecInPod(ctx, pod.Name, pod.Namespace, availabilityContainer, cmd)
+
+	var exitErr utilexec.ExitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &exitErr):
+		return fmt.Errorf("from pod %s: %s (stderr: %q)", pod.Name, err, strings.TrimSpace(stderr))
+	default:
+		return fmt.Errorf("%w: exec into pod %s failed: %s", errProbeUnavailable, pod.Name, err)
+	}
+}
+
+// probeAPIServer calls the Service through the API server Service proxy.
+func probeAPIServer(ctx context.Context, apiServer *apiServerClient) error {
+	return apiServer.Do(ctx, availabilityRequestTimeout, func(ctx context.Context, clientset kubernetes.Interface) error {
+		_, err := clientset.CoreV1().Services(availabilityNamespace).
+			ProxyGet("http", availabilityName, fmt.Sprint(availabilityPort), "/", nil).
+			DoRaw(ctx)
+		return err
+	})
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// availabilityFailure is a failed call to a target.
+type availabilityFailure struct {
+	Time   time.Time
+	Target string
+	Error  string
+}
+
+// availabilityOutage is a period during which every call to a target failed. It lasts from
+// the first failed call until the next successful one.
+type availabilityOutage struct {
+	Target   string
+	Start    time.Time
+	End      time.Time
+	Failures int
+}
+
+func (o availabilityOutage) Duration() time.Duration {
+	return o.End.Sub(o.Start)
+}
+
+// availabilityTimeline collects the results of the availability prober. Probes that
+// couldn't be run (errProbeUnavailable) are recorded but neither start nor end an outage.
+type availabilityTimeline struct {
+	mu sync.Mutex
+
+	Start   time.Time
+	End     time.Time
+	Targets []string
+
+	requests    map[string]int
+	unavailable map[string]int
+	failures    []availabilityFailure
+	outages     []availabilityOutage
+	// open holds the index in outages of the ongoing outage of each target.
+	open map[string]int
+}
+
+func newAvailabilityTimeline(targets []string, start time.Time) *availabilityTimeline {
+	return &availabilityTimeline{
+		Start:       start,
+		Targets:     targets,
+		requests:    map[string]int{},
+		unavailable: map[string]int{},
+		open:        map[string]int{},
+	}
+}
+
+func (t *availabilityTimeline) record(target string, at time.Time, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.requests[target]++
+
+	switch {
+	case errors.Is(err, errProbeUnavailable):
+		t.unavailable[target]++
+		logger.Log("Availability probe %s couldn't run: %v", target, err)
+	case err != nil:
+		t.failures = append(t.failures, availabilityFailure{Time: at, Target: target, Error: err.Error()})
+		if i, ok := t.open[target]; ok {
+			t.outages[i].End = at
+			t.outages[i].Failures++
+		} else {
+			logger.Log("Availability probe %s started failing: %v", target, err)
+			t.open[target] = len(t.outages)
+			t.outages = append(t.outages, availabilityOutage{Target: target, Start: at, End: at, Failures: 1})
+		}
+	default:
+		if i, ok := t.open[target]; ok {
+			t.outages[i].End = at
+			delete(t.open, target)
+			logger.Log("Availability probe %s recovered after %s", target, t.outages[i].Duration())
+		}
+	}
+}
+
+// finish ends the timeline, closing ongoing outages at end.
+func (t *availabilityTimeline) finish(end time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.End.IsZero() {
+		return
+	}
+	t.End = end
+	for target, i := range t.open {
+		t.outages[i].End = end
+		delete(t.open, target)
+	}
+}
+
+// Downtime returns the total duration of the outages of target.
+func (t *availabilityTimeline) Downtime(target string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var downtime time.Duration
+	for _, outage := range t.outages {
+		if outage.Target == target {
+			downtime += outage.Duration()
+		}
+	}
+	return downtime
+}
+
+// String renders a summary per target followed by the outages and every failed call.
+func (t *availabilityTimeline) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "Probed from %s to %s\n", t.Start.UTC().Format(time.RFC3339), t.End.UTC().Format(time.RFC3339))
+	for _, target := range t.Targets {
+		var downtime time.Duration
+		failed := 0
+		for _, outage := range t.outages {
+			if outage.Target == target {
+				downtime += outage.Duration()
+				failed += outage.Failures
+			}
+		}
+		fmt.Fprintf(b, "%s: %d requests, %d failed, %d not run, downtime %s\n", target, t.requests[target], failed, t.unavailable[target], downtime)
+	}
+
+	if len(t.outages) > 0 {
+		fmt.Fprintf(b, "Outages:\n")
+		for _, outage := range t.outages {
+			fmt.Fprintf(b, "  %s %s - %s (%s, %d failed requests)\n", outage.Target, outage.Start.UTC().Format(time.RFC3339), outage.End.UTC().Format(time.RFC3339), outage.Duration(), outage.Failures)
+		}
+	}
+	if len(t.failures) > 0 {
+		fmt.Fprintf(b, "Failed requests:\n")
+		for _, failure := range t.failures {
+			fmt.Fprintf(b, "  %s %s: %s\n", failure.Time.UTC().Format(time.RFC3339), failure.Target, failure.Error)
+		}
+	}
+	return b.String()
+}
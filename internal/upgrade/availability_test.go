@@ -0,0 +1,63 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAvailabilityTimeline(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+
+	timeline := newAvailabilityTimeline([]string{availabilityTargetInCluster, availabilityTargetAPIServer}, start)
+
+	timeline.record(availabilityTargetInCluster, at(0), nil)
+	timeline.record(availabilityTargetInCluster, at(2), errors.New("connection refused"))
+	// A probe that couldn't run neither ends nor extends the outage.
+	timeline.record(availabilityTargetInCluster, at(4), fmt.Errorf("%w: exec failed", errProbeUnavailable))
+	timeline.record(availabilityTargetInCluster, at(6), errors.New("timed out"))
+	timeline.record(availabilityTargetInCluster, at(8), nil)
+	timeline.record(availabilityTargetInCluster, at(10), errors.New("no ready pods"))
+	timeline.record(availabilityTargetInCluster, at(12), nil)
+
+	timeline.record(availabilityTargetAPIServer, at(0), nil)
+	timeline.record(availabilityTargetAPIServer, at(20), errors.New("service unavailable"))
+	// The outage is still ongoing when the timeline ends.
+	timeline.finish(at(30))
+
+	if downtime := timeline.Downtime(availabilityTargetInCluster); downtime != 8*time.Second {
+		t.Errorf("expected in-cluster downtime of 8s, got %s", downtime)
+	}
+	if downtime := timeline.Downtime(availabilityTargetAPIServer); downtime != 10*time.Second {
+		t.Errorf("expected api-server downtime of 10s, got %s", downtime)
+	}
+
+	s := timeline.String()
+	for _, expected := range []string{
+		"in-cluster: 7 requests, 3 failed, 1 not run, downtime 8s",
+		"api-server: 2 requests, 1 failed, 0 not run, downtime 10s",
+		"in-cluster 2026-01-01T12:00:02Z - 2026-01-01T12:00:08Z (6s, 2 failed requests)",
+		"api-server 2026-01-01T12:00:20Z - 2026-01-01T12:00:30Z (10s, 1 failed requests)",
+		"2026-01-01T12:00:06Z in-cluster: timed out",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("expected timeline to contain %q:\n%s", expected, s)
+		}
+	}
+}
+
+func TestAvailabilityTimelineFinishIsIdempotent(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	timeline := newAvailabilityTimeline([]string{availabilityTargetInCluster}, start)
+	timeline.record(availabilityTargetInCluster, start, errors.New("connection refused"))
+	timeline.finish(start.Add(time.Minute))
+	timeline.finish(start.Add(time.Hour))
+
+	if downtime := timeline.Downtime(availabilityTargetInCluster); downtime != time.Minute {
+		t.Errorf("expected downtime of 1m, got %s", downtime)
+	}
+}
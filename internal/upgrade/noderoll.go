@@ -0,0 +1,361 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+)
+
+const (
+	nodeRollWatchInterval = 10 * time.Second
+
+	controlPlanePool = "control-plane"
+	unknownPool      = "unknown"
+
+	nodeRollAdded    = "added"
+	nodeRollRemoved  = "removed"
+	nodeRollReplaced = "replaced"
+)
+
+// nodePoolLabels are the node labels naming the pool of a worker node without a Machine,
+// e.g. nodes of a MachinePool on CAPA or of a managed node group on EKS.
+var nodePoolLabels = []string{
+	"giantswarm.io/machine-pool",
+	"giantswarm.io/machine-deployment",
+	"eks.amazonaws.com/nodegroup",
+	"karpenter.sh/nodepool",
+}
+
+// nodePool returns the pool of node, preferring the labels of its Machine.
+func nodePool(node corev1.Node, machine *capi.Machine) string {
+	if machine != nil {
+		if _, ok := machine.Labels[capi.MachineControlPlaneLabel]; ok {
+			return controlPlanePool
+		}
+		for _, label := range []string{capi.MachineDeploymentNameLabel, capi.MachinePoolNameLabel} {
+			if name := machine.Labels[label]; name != "" {
+				return name
+			}
+		}
+	}
+
+	if _, ok := node.Labels["node-role.kubernetes.io/control-plane"]; ok {
+		return controlPlanePool
+	}
+	for _, label := range nodePoolLabels {
+		if name := node.Labels[label]; name != "" {
+			return name
+		}
+	}
+	return unknownPool
+}
+
+// nodeRollEvent is a change to the nodes of a pool.
+type nodeRollEvent struct {
+	Time time.Time `json:"time"`
+	Node string    `json:"node"`
+	Type string    `json:"type"`
+}
+
+// nodePoolRoll describes how the nodes of a pool changed during the upgrade.
+type nodePoolRoll struct {
+	Name         string          `json:"name"`
+	ControlPlane bool            `json:"controlPlane"`
+	InitialNodes int             `json:"initialNodes"`
+	FinalNodes   int             `json:"finalNodes"`
+	Removed      []string        `json:"removed,omitempty"`
+	Replaced     []string        `json:"replaced,omitempty"`
+	Added        []string        `json:"added,omitempty"`
+	Events       []nodeRollEvent `json:"events,omitempty"`
+	// MaxSurge is the most nodes observed above InitialNodes at the same time.
+	MaxSurge int `json:"maxSurge"`
+	// Rolled is set when an initial node of the pool was removed or replaced, and Completed
+	// once all of them were and the surge nodes are gone again.
+	Rolled     bool       `json:"rolled"`
+	Completed  bool       `json:"completed"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Duration   string     `json:"duration,omitempty"`
+}
+
+// nodeRollReport describes how the nodes of the cluster changed during the upgrade.
+type nodeRollReport struct {
+	Rolled bool           `json:"rolled"`
+	Start  time.Time      `json:"start"`
+	End    time.Time      `json:"end"`
+	Pools  []nodePoolRoll `json:"pools"`
+}
+
+// nodeRollTracker compares observations of the nodes of the cluster with the first one.
+type nodeRollTracker struct {
+	mu sync.Mutex
+
+	start    time.Time
+	end      time.Time
+	initial  map[string]nodeInfo
+	previous map[string]nodeInfo
+	// pools holds the pool of every node ever observed, as nodes that are gone can't be looked up.
+	pools    map[string]string
+	events   []nodeRollEvent
+	maxSurge map[string]int
+}
+
+func newNodeRollTracker(at time.Time, nodes map[string]nodeInfo) *nodeRollTracker {
+	t := &nodeRollTracker{
+		start:    at,
+		initial:  nodes,
+		previous: nodes,
+		pools:    map[string]string{},
+		maxSurge: map[string]int{},
+	}
+	for name, node := range nodes {
+		t.pools[name] = node.Pool
+	}
+	return t
+}
+
+// observe records the changes since the previous observation.
+func (t *nodeRollTracker) observe(at time.Time, nodes map[string]nodeInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, name := range sortedNodeNames(t.previous) {
+		if _, ok := nodes[name]; !ok {
+			t.events = append(t.events, nodeRollEvent{Time: at, Node: name, Type: nodeRollRemoved})
+			logger.Log("Node %s of pool %s was removed", name, t.pools[name])
+		}
+	}
+	for _, name := range sortedNodeNames(nodes) {
+		node := nodes[name]
+		t.pools[name] = node.Pool
+		previous, ok := t.previous[name]
+		switch {
+		case !ok:
+			t.events = append(t.events, nodeRollEvent{Time: at, Node: name, Type: nodeRollAdded})
+			logger.Log("Node %s of pool %s was added", name, node.Pool)
+		case previous.UID != node.UID:
+			t.events = append(t.events, nodeRollEvent{Time: at, Node: name, Type: nodeRollReplaced})
+			logger.Log("Node %s of pool %s was replaced (UID changed: %s -> %s)", name, node.Pool, previous.UID, node.UID)
+		}
+	}
+	t.previous = nodes
+
+	initialCounts := countNodesByPool(t.initial)
+	for pool, count := range countNodesByPool(nodes) {
+		if surge := count - initialCounts[pool]; surge > t.maxSurge[pool] {
+			t.maxSurge[pool] = surge
+		}
+	}
+}
+
+// finish ends the tracking at the given time.
+func (t *nodeRollTracker) finish(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.end.IsZero() {
+		t.end = at
+	}
+}
+
+// Report returns the roll of every pool that had nodes at the start or the end of the tracking.
+func (t *nodeRollTracker) Report() nodeRollReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pools := map[string]*nodePoolRoll{}
+	pool := func(name string) *nodePoolRoll {
+		if _, ok := pools[name]; !ok {
+			pools[name] = &nodePoolRoll{Name: name, ControlPlane: name == controlPlanePool, MaxSurge: t.maxSurge[name]}
+		}
+		return pools[name]
+	}
+
+	for _, name := range sortedNodeNames(t.initial) {
+		p := pool(t.initial[name].Pool)
+		p.InitialNodes++
+		current, ok := t.previous[name]
+		switch {
+		case !ok:
+			p.Removed = append(p.Removed, name)
+		case current.UID != t.initial[name].UID:
+			p.Replaced = append(p.Replaced, name)
+		}
+	}
+	for _, name := range sortedNodeNames(t.previous) {
+		p := pool(t.previous[name].Pool)
+		p.FinalNodes++
+		if _, ok := t.initial[name]; !ok {
+			p.Added = append(p.Added, name)
+		}
+	}
+	for _, event := range t.events {
+		p := pool(t.pools[event.Node])
+		p.Events = append(p.Events, event)
+	}
+
+	report := nodeRollReport{Start: t.start, End: t.end}
+	for _, p := range pools {
+		p.Rolled = len(p.Removed)+len(p.Replaced) > 0
+		p.Completed = p.Rolled && len(p.Removed)+len(p.Replaced) == p.InitialNodes && p.FinalNodes <= p.InitialNodes
+		if len(p.Events) > 0 {
+			startedAt := p.Events[0].Time
+			finishedAt := p.Events[len(p.Events)-1].Time
+			p.StartedAt = &startedAt
+			p.FinishedAt = &finishedAt
+			p.Duration = finishedAt.Sub(startedAt).String()
+		}
+		report.Rolled = report.Rolled || p.Rolled
+		report.Pools = append(report.Pools, *p)
+	}
+	sort.Slice(report.Pools, func(i, j int) bool {
+		if report.Pools[i].ControlPlane != report.Pools[j].ControlPlane {
+			return report.Pools[i].ControlPlane
+		}
+		return report.Pools[i].Name < report.Pools[j].Name
+	})
+	return report
+}
+
+// RollComplete reports whether every pool that started rolling has completed its roll.
+// It is false as long as no pool started rolling.
+func (r nodeRollReport) RollComplete() bool {
+	if !r.Rolled {
+		return false
+	}
+	for _, pool := range r.Pools {
+		if pool.Rolled && !pool.Completed {
+			return false
+		}
+	}
+	return true
+}
+
+// nodeRollWatcher observes the Nodes of the workload cluster and the Machines of the
+// cluster on the MC until it is stopped.
+type nodeRollWatcher struct {
+	tracker *nodeRollTracker
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// startNodeRollWatcher takes the first observation of the nodes and keeps observing them
+// in the background.
+func startNodeRollWatcher(ctx context.Context, wcClient *client.Client, mcClient *client.Client, clusterName, namespace string) (*nodeRollWatcher, error) {
+	nodes, err := listPoolNodes(ctx, wcClient, mcClient, clusterName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &nodeRollWatcher{
+		tracker: newNodeRollTracker(time.Now(), nodes),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(nodeRollWatchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			nodes, err := listPoolNodes(ctx, wcClient, mcClient, clusterName, namespace)
+			if err != nil {
+				logger.Log("Failed to list nodes for roll detection: %v", err)
+				continue
+			}
+			w.tracker.observe(time.Now(), nodes)
+		}
+	}()
+
+	return w, nil
+}
+
+// Stop stops watching and returns the report. It is safe to call more than once.
+func (w *nodeRollWatcher) Stop() nodeRollReport {
+	w.cancel()
+	<-w.done
+	w.tracker.finish(time.Now())
+	return w.tracker.Report()
+}
+
+// listPoolNodes returns the nodes of the workload cluster by name, with the pool they belong to.
+func listPoolNodes(ctx context.Context, wcClient *client.Client, mcClient *client.Client, clusterName, namespace string) (map[string]nodeInfo, error) {
+	nodes := &corev1.NodeList{}
+	if err := wcClient.List(ctx, nodes); err != nil {
+		return nil, err
+	}
+
+	// Not every provider has Machines for every node, e.g. EKS, so failing to list them
+	// only loses the pool names from the Machines.
+	machines := &capi.MachineList{}
+	if err := mcClient.List(ctx, machines, cr.InNamespace(namespace), cr.MatchingLabels{capi.ClusterNameLabel: clusterName}); err != nil {
+		logger.Log("Failed to list Machines for roll detection: %v", err)
+	}
+	machinesByNode := map[string]*capi.Machine{}
+	for i := range machines.Items {
+		if name := machines.Items[i].Status.NodeRef.Name; name != "" {
+			machinesByNode[name] = &machines.Items[i]
+		}
+	}
+
+	result := make(map[string]nodeInfo, len(nodes.Items))
+	for _, node := range nodes.Items {
+		result[node.Name] = nodeInfo{
+			Name:      node.Name,
+			UID:       node.UID,
+			CreatedAt: node.CreationTimestamp.Time,
+			Pool:      nodePool(node, machinesByNode[node.Name]),
+		}
+	}
+	return result, nil
+}
+
+func countNodesByPool(nodes map[string]nodeInfo) map[string]int {
+	counts := map[string]int{}
+	for _, node := range nodes {
+		counts[node.Pool]++
+	}
+	return counts
+}
+
+func sortedNodeNames(nodes map[string]nodeInfo) []string {
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// String summarizes the roll of every pool.
+func (r nodeRollReport) String() string {
+	s := fmt.Sprintf("Node roll report: rolled=%t\n", r.Rolled)
+	for _, pool := range r.Pools {
+		s += fmt.Sprintf("  %s: %d -> %d nodes, removed %v, replaced %v, added %v, max surge %d, rolled=%t, completed=%t",
+			pool.Name, pool.InitialNodes, pool.FinalNodes, pool.Removed, pool.Replaced, pool.Added, pool.MaxSurge, pool.Rolled, pool.Completed)
+		if pool.Duration != "" {
+			s += fmt.Sprintf(", took %s", pool.Duration)
+		}
+		s += "\n"
+	}
+	return s
+}
@@ -0,0 +1,179 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	capi "sigs.k8s.io/cluster-api/api/core/v1beta2"
+)
+
+func TestNodePool(t *testing.T) {
+	node := func(labels map[string]string) corev1.Node {
+		return corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node", Labels: labels}}
+	}
+	machine := func(labels map[string]string) *capi.Machine {
+		return &capi.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine", Labels: labels}}
+	}
+
+	testCases := []struct {
+		name     string
+		node     corev1.Node
+		machine  *capi.Machine
+		expected string
+	}{
+		{
+			name:     "control plane machine",
+			node:     node(nil),
+			machine:  machine(map[string]string{capi.MachineControlPlaneLabel: ""}),
+			expected: controlPlanePool,
+		},
+		{
+			name:     "machine deployment machine",
+			node:     node(map[string]string{"giantswarm.io/machine-pool": "ignored"}),
+			machine:  machine(map[string]string{capi.MachineDeploymentNameLabel: "md-0"}),
+			expected: "md-0",
+		},
+		{
+			name:     "control plane node without machine",
+			node:     node(map[string]string{"node-role.kubernetes.io/control-plane": ""}),
+			expected: controlPlanePool,
+		},
+		{
+			name:     "machine pool node without machine",
+			node:     node(map[string]string{"giantswarm.io/machine-pool": "mp-0"}),
+			expected: "mp-0",
+		},
+		{
+			name:     "unlabelled node",
+			node:     node(nil),
+			expected: unknownPool,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if pool := nodePool(tc.node, tc.machine); pool != tc.expected {
+				t.Errorf("expected pool %q, got %q", tc.expected, pool)
+			}
+		})
+	}
+}
+
+func TestNodeRollTracker(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	nodes := func(infos ...nodeInfo) map[string]nodeInfo {
+		result := map[string]nodeInfo{}
+		for _, info := range infos {
+			result[info.Name] = info
+		}
+		return result
+	}
+	node := func(name, uid, pool string) nodeInfo {
+		return nodeInfo{Name: name, UID: types.UID(uid), Pool: pool}
+	}
+
+	tracker := newNodeRollTracker(start, nodes(
+		node("cp-a", "1", controlPlanePool),
+		node("worker-a", "2", "mp-0"),
+		node("worker-b", "3", "mp-0"),
+		node("other-a", "4", "mp-1"),
+	))
+	if tracker.Report().Rolled {
+		t.Fatalf("expected no roll before any change")
+	}
+
+	// The control plane node is replaced in place, keeping its name.
+	tracker.observe(at(1), nodes(
+		node("cp-a", "5", controlPlanePool),
+		node("worker-a", "2", "mp-0"),
+		node("worker-b", "3", "mp-0"),
+		node("other-a", "4", "mp-1"),
+	))
+	// mp-0 surges by one node, then rolls its nodes one after the other.
+	tracker.observe(at(2), nodes(
+		node("cp-a", "5", controlPlanePool),
+		node("worker-a", "2", "mp-0"),
+		node("worker-b", "3", "mp-0"),
+		node("worker-c", "6", "mp-0"),
+		node("other-a", "4", "mp-1"),
+	))
+	tracker.observe(at(4), nodes(
+		node("cp-a", "5", controlPlanePool),
+		node("worker-b", "3", "mp-0"),
+		node("worker-c", "6", "mp-0"),
+		node("other-a", "4", "mp-1"),
+	))
+	if report := tracker.Report(); !report.Rolled || report.RollComplete() {
+		t.Fatalf("expected an incomplete roll, got %s", report)
+	}
+
+	tracker.observe(at(6), nodes(
+		node("cp-a", "5", controlPlanePool),
+		node("worker-c", "6", "mp-0"),
+		node("worker-d", "7", "mp-0"),
+		node("other-a", "4", "mp-1"),
+	))
+	tracker.finish(at(7))
+
+	report := tracker.Report()
+	if !report.Rolled || !report.RollComplete() {
+		t.Fatalf("expected a complete roll, got %s", report)
+	}
+
+	timeAt := func(minutes int) *time.Time { ts := at(minutes); return &ts }
+	expected := nodeRollReport{
+		Rolled: true,
+		Start:  start,
+		End:    at(7),
+		Pools: []nodePoolRoll{
+			{
+				Name:         controlPlanePool,
+				ControlPlane: true,
+				InitialNodes: 1,
+				FinalNodes:   1,
+				Replaced:     []string{"cp-a"},
+				Events:       []nodeRollEvent{{Time: at(1), Node: "cp-a", Type: nodeRollReplaced}},
+				Rolled:       true,
+				Completed:    true,
+				StartedAt:    timeAt(1),
+				FinishedAt:   timeAt(1),
+				Duration:     "0s",
+			},
+			{
+				Name:         "mp-0",
+				InitialNodes: 2,
+				FinalNodes:   2,
+				Removed:      []string{"worker-a", "worker-b"},
+				Added:        []string{"worker-c", "worker-d"},
+				Events: []nodeRollEvent{
+					{Time: at(2), Node: "worker-c", Type: nodeRollAdded},
+					{Time: at(4), Node: "worker-a", Type: nodeRollRemoved},
+					{Time: at(6), Node: "worker-b", Type: nodeRollRemoved},
+					{Time: at(6), Node: "worker-d", Type: nodeRollAdded},
+				},
+				MaxSurge:   1,
+				Rolled:     true,
+				Completed:  true,
+				StartedAt:  timeAt(2),
+				FinishedAt: timeAt(6),
+				Duration:   "4m0s",
+			},
+			{
+				Name:         "mp-1",
+				InitialNodes: 1,
+				FinalNodes:   1,
+			},
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		got, _ := json.MarshalIndent(report, "", "  ")
+		want, _ := json.MarshalIndent(expected, "", "  ")
+		t.Errorf("unexpected report:\n%s\nexpected:\n%s", got, want)
+	}
+}
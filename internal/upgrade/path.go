@@ -0,0 +1,72 @@
+package upgrade
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/giantswarm/clustertest/v5/pkg/env"
+)
+
+// EnvUpgradePath is the env var declaring the releases an upgrade suite goes through, as a
+// comma-separated ordered list, e.g. "29.6.0,29.7.2,30.1.0". The cluster is created with the
+// first release and upgraded to each of the following ones in turn. When set, it takes
+// precedence over the releases resolved from E2E_RELEASE_PRE_UPGRADE and E2E_RELEASE_VERSION.
+const EnvUpgradePath = "E2E_UPGRADE_PATH"
+
+// Path returns the releases declared in E2E_UPGRADE_PATH, or nil if it isn't set.
+func Path() ([]string, error) {
+	return parseUpgradePath(os.Getenv(EnvUpgradePath))
+}
+
+func parseUpgradePath(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	releases := []string{}
+	for _, release := range strings.Split(value, ",") {
+		release = strings.TrimSpace(release)
+		if release == "" {
+			return nil, fmt.Errorf("%s %q has an empty release", EnvUpgradePath, value)
+		}
+		if len(releases) > 0 && releases[len(releases)-1] == release {
+			return nil, fmt.Errorf("%s %q upgrades release %s to itself", EnvUpgradePath, value, release)
+		}
+		releases = append(releases, release)
+	}
+
+	if len(releases) < 2 {
+		return nil, fmt.Errorf("%s %q needs at least the release to create the cluster with and the one to upgrade to", EnvUpgradePath, value)
+	}
+	return releases, nil
+}
+
+// intermediateReleases returns the releases of the upgrade path between the first and the
+// last one.
+func intermediateReleases() ([]string, error) {
+	releases, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) < 3 {
+		return nil, nil
+	}
+	return releases[1 : len(releases)-1], nil
+}
+
+// withoutOverrideVersions calls fn with E2E_OVERRIDE_VERSIONS unset. The overrides are meant
+// for the release the upgrade ends with, so clusters built for an intermediate release use the
+// app versions pinned by that release instead.
+func withoutOverrideVersions(fn func() error) error {
+	value, ok := os.LookupEnv(env.OverrideVersions)
+	if !ok {
+		return fn()
+	}
+
+	if err := os.Unsetenv(env.OverrideVersions); err != nil {
+		return err
+	}
+	defer os.Setenv(env.OverrideVersions, value) // nolint:errcheck
+	return fn()
+}
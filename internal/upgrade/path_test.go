@@ -0,0 +1,85 @@
+package upgrade
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/giantswarm/clustertest/v5/pkg/env"
+)
+
+func TestParseUpgradePath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected []string
+		wantErr  bool
+	}{
+		{name: "unset", value: "", expected: nil},
+		{name: "single hop", value: "29.6.0,30.1.0", expected: []string{"29.6.0", "30.1.0"}},
+		{name: "multiple hops with spaces", value: " 29.6.0, 29.7.2 ,30.1.0 ", expected: []string{"29.6.0", "29.7.2", "30.1.0"}},
+		{name: "single release", value: "30.1.0", wantErr: true},
+		{name: "empty release", value: "29.6.0,,30.1.0", wantErr: true},
+		{name: "upgrade to itself", value: "29.6.0,29.6.0,30.1.0", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			releases, err := parseUpgradePath(tc.value)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", releases)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(releases, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, releases)
+			}
+		})
+	}
+}
+
+func TestIntermediateReleases(t *testing.T) {
+	t.Setenv(EnvUpgradePath, "29.6.0,29.7.2,29.8.0,30.1.0")
+	releases, err := intermediateReleases()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(releases, []string{"29.7.2", "29.8.0"}) {
+		t.Errorf("unexpected intermediate releases %v", releases)
+	}
+
+	t.Setenv(EnvUpgradePath, "29.6.0,30.1.0")
+	releases, err = intermediateReleases()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if releases != nil {
+		t.Errorf("expected no intermediate releases, got %v", releases)
+	}
+
+	t.Setenv(EnvUpgradePath, "29.6.0,,30.1.0")
+	if releases, err := intermediateReleases(); err == nil {
+		t.Errorf("expected an error, got %v", releases)
+	}
+}
+
+func TestWithoutOverrideVersions(t *testing.T) {
+	t.Setenv(env.OverrideVersions, "cluster-aws=1.2.3")
+
+	err := withoutOverrideVersions(func() error {
+		if value, ok := os.LookupEnv(env.OverrideVersions); ok {
+			t.Errorf("expected %s to be unset, got %q", env.OverrideVersions, value)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value := os.Getenv(env.OverrideVersions); value != "cluster-aws=1.2.3" {
+		t.Errorf("expected %s to be restored, got %q", env.OverrideVersions, value)
+	}
+}
@@ -0,0 +1,204 @@
+package upgrade
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+)
+
+const (
+	persistenceNamespace = "test-upgrade-persistence"
+	persistenceName      = "persistence"
+	persistencePod       = persistenceName + "-0"
+	persistenceContainer = "persistence"
+	persistenceDataFile  = "/data/payload"
+)
+
+var persistenceLabels = map[string]string{"app.kubernetes.io/name": persistenceName}
+
+// persistedData is what the upgrade tests record about the StatefulSet before the upgrade.
+type persistedData struct {
+	Checksum string
+	PodUID   types.UID
+	NodeName string
+	NodeUID  types.UID
+}
+
+// persistenceStatefulSet runs a single replica with a PVC from the default StorageClass
+// mounted at /data. Data is written and checked with ExecInPod.
+func persistenceStatefulSet() *appsv1.StatefulSet {
+	user := int64(1001)
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      persistenceName,
+			Namespace: persistenceNamespace,
+			Labels:    persistenceLabels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    ptr.To(int32(1)),
+			ServiceName: persistenceName,
+			Selector:    &metav1.LabelSelector{MatchLabels: persistenceLabels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: persistenceLabels},
+				Spec: corev1.PodSpec{
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:    &user,
+						RunAsGroup:   &user,
+						FSGroup:      &user,
+						RunAsNonRoot: ptr.To(true),
+						SeccompProfile: &corev1.SeccompProfile{
+							Type: corev1.SeccompProfileTypeRuntimeDefault,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:  persistenceContainer,
+							Image: "gsoci.azurecr.io/giantswarm/alpine:latest",
+							Args:  []string{"sleep", "99999999"},
+							VolumeMounts: []corev1.VolumeMount{
+								{Name: "data", MountPath: "/data"},
+							},
+							SecurityContext: &corev1.SecurityContext{
+								Capabilities: &corev1.Capabilities{
+									Drop: []corev1.Capability{"ALL"},
+								},
+								AllowPrivilegeEscalation: ptr.To(false),
+							},
+						},
+					},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "data"},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.VolumeResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func createPersistenceStatefulSet(ctx context.Context, wcClient *client.Client) error {
+	for _, obj := range []cr.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: persistenceNamespace}},
+		persistenceStatefulSet(),
+	} {
+		logger.Log("Creating %T '%s'", obj, obj.GetName())
+		err := wcClient.Create(ctx, obj)
+		if err != nil && !apierror.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPersistencePodReady returns a function that succeeds once the StatefulSet pod is ready.
+func checkPersistencePodReady(ctx context.Context, wcClient *client.Client) func() error {
+	return func() error {
+		statefulSet := &appsv1.StatefulSet{}
+		err := wcClient.Get(ctx, cr.ObjectKey{Name: persistenceName, Namespace: persistenceNamespace}, statefulSet)
+		if err != nil {
+			return err
+		}
+		if statefulSet.Status.ReadyReplicas != 1 {
+			logger.Log("StatefulSet '%s' has %d ready replicas, waiting for 1", persistenceName, statefulSet.Status.ReadyReplicas)
+			return fmt.Errorf("StatefulSet %s is not ready", persistenceName)
+		}
+		return nil
+	}
+}
+
+// writePersistedData writes random data to the volume of the StatefulSet pod and returns
+// its checksum along with where the pod runs.
+func writePersistedData(ctx context.Context, wcClient *client.Client) (persistedData, error) {
+	cmd := []string{"sh", "-c", fmt.Sprintf("dd if=/dev/urandom of=%[1]s bs=1M count=16 2>/dev/null && sync && sha256sum %[1]s", persistenceDataFile)}
+	stdout, stderr, err := wcClient.ExecInPod(ctx, persistencePod, persistenceNamespace, persistenceContainer, cmd)
+	if err != nil {
+		return persistedData{}, fmt.Errorf("failed to write data in pod %s: %s (stderr: %q)", persistencePod, err, stderr)
+	}
+
+	data, err := currentPersistenceLocation(ctx, wcClient)
+	if err != nil {
+		return persistedData{}, err
+	}
+	data.Checksum = firstField(stdout)
+	if data.Checksum == "" {
+		return persistedData{}, fmt.Errorf("no checksum in the output of sha256sum: %q", stdout)
+	}
+
+	logger.Log("Wrote data with checksum %s to pod '%s' on node '%s'", data.Checksum, persistencePod, data.NodeName)
+	return data, nil
+}
+
+// readPersistedChecksum returns the checksum of the data on the volume of the StatefulSet pod.
+func readPersistedChecksum(ctx context.Context, wcClient *client.Client) (string, error) {
+	stdout, stderr, err := wcClient.ExecInPod(ctx, persistencePod, persistenceNamespace, persistenceContainer, []string{"sha256sum", persistenceDataFile})
+	if err != nil {
+		return "", fmt.Errorf("failed to read data in pod %s: %s (stderr: %q)", persistencePod, err, stderr)
+	}
+	return firstField(stdout), nil
+}
+
+// currentPersistenceLocation returns the pod and node the StatefulSet pod currently runs on.
+func currentPersistenceLocation(ctx context.Context, wcClient *client.Client) (persistedData, error) {
+	pod := &corev1.Pod{}
+	err := wcClient.Get(ctx, cr.ObjectKey{Name: persistencePod, Namespace: persistenceNamespace}, pod)
+	if err != nil {
+		return persistedData{}, err
+	}
+
+	node := &corev1.Node{}
+	err = wcClient.Get(ctx, cr.ObjectKey{Name: pod.Spec.NodeName}, node)
+	if err != nil {
+		return persistedData{}, err
+	}
+
+	return persistedData{PodUID: pod.UID, NodeName: node.Name, NodeUID: node.UID}, nil
+}
+
+// nodeStillExists reports whether the node with the given name and UID is still part of the cluster.
+func nodeStillExists(ctx context.Context, wcClient *client.Client, name string, uid types.UID) (bool, error) {
+	node := &corev1.Node{}
+	err := wcClient.Get(ctx, cr.ObjectKey{Name: name}, node)
+	if apierror.IsNotFound(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return node.UID == uid, nil
+}
+
+func deletePersistenceNamespace(ctx context.Context, wcClient *client.Client) error {
+	logger.Log("Deleting Namespace '%s'", persistenceNamespace)
+	err := wcClient.Delete(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: persistenceNamespace}})
+	if err != nil && !apierror.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func firstField(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
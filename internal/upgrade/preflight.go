@@ -0,0 +1,615 @@
+package upgrade
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierror "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	"github.com/giantswarm/clustertest/v5/pkg/application"
+	"github.com/giantswarm/clustertest/v5/pkg/client"
+	"github.com/giantswarm/clustertest/v5/pkg/env"
+	"github.com/giantswarm/clustertest/v5/pkg/logger"
+)
+
+const (
+	preflightCheckValues      = "values"
+	preflightCheckRemovedAPIs = "removed-apis"
+	preflightCheckCRDs        = "deprecated-crds"
+
+	// chartSourceURL is where the files of a Giant Swarm chart are fetched from, by app name and git ref.
+	chartSourceURL = "https://raw.githubusercontent.com/giantswarm/%[1]s/%[2]s/helm/%[1]s/%[3]s"
+)
+
+// commitVersion matches the versions of charts built from a commit, e.g. "0.38.0-5f4372ac697fce58d524830a985ede2082d7f461".
+var commitVersion = regexp.MustCompile(`-([0-9a-f]{40})$`)
+
+var releaseGVK = schema.GroupVersionKind{Group: "release.giantswarm.io", Version: "v1alpha1", Kind: "Release"}
+
+// removedAPI is a built-in API version that Kubernetes stops serving in RemovedIn.
+type removedAPI struct {
+	GroupVersionKind schema.GroupVersionKind
+	RemovedIn        *semver.Version
+	// Replacement is the API version serving the same objects from RemovedIn on, if any.
+	Replacement string
+}
+
+// removedAPIs lists the built-in API versions removed since Kubernetes 1.25, following
+// https://kubernetes.io/docs/reference/using-api/deprecation-guide/.
+var removedAPIs = []removedAPI{
+	{schema.GroupVersionKind{Group: "batch", Version: "v1beta1", Kind: "CronJob"}, semver.MustParse("1.25"), "batch/v1"},
+	{schema.GroupVersionKind{Group: "discovery.k8s.io", Version: "v1beta1", Kind: "EndpointSlice"}, semver.MustParse("1.25"), "discovery.k8s.io/v1"},
+	{schema.GroupVersionKind{Group: "events.k8s.io", Version: "v1beta1", Kind: "Event"}, semver.MustParse("1.25"), "events.k8s.io/v1"},
+	{schema.GroupVersionKind{Group: "autoscaling", Version: "v2beta1", Kind: "HorizontalPodAutoscaler"}, semver.MustParse("1.25"), "autoscaling/v2"},
+	{schema.GroupVersionKind{Group: "policy", Version: "v1beta1", Kind: "PodDisruptionBudget"}, semver.MustParse("1.25"), "policy/v1"},
+	{schema.GroupVersionKind{Group: "policy", Version: "v1beta1", Kind: "PodSecurityPolicy"}, semver.MustParse("1.25"), ""},
+	{schema.GroupVersionKind{Group: "node.k8s.io", Version: "v1beta1", Kind: "RuntimeClass"}, semver.MustParse("1.25"), "node.k8s.io/v1"},
+	{schema.GroupVersionKind{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta1", Kind: "FlowSchema"}, semver.MustParse("1.26"), "flowcontrol.apiserver.k8s.io/v1"},
+	{schema.GroupVersionKind{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta1", Kind: "PriorityLevelConfiguration"}, semver.MustParse("1.26"), "flowcontrol.apiserver.k8s.io/v1"},
+	{schema.GroupVersionKind{Group: "autoscaling", Version: "v2beta2", Kind: "HorizontalPodAutoscaler"}, semver.MustParse("1.26"), "autoscaling/v2"},
+	{schema.GroupVersionKind{Group: "storage.k8s.io", Version: "v1beta1", Kind: "CSIStorageCapacity"}, semver.MustParse("1.27"), "storage.k8s.io/v1"},
+	{schema.GroupVersionKind{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta2", Kind: "FlowSchema"}, semver.MustParse("1.29"), "flowcontrol.apiserver.k8s.io/v1"},
+	{schema.GroupVersionKind{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta2", Kind: "PriorityLevelConfiguration"}, semver.MustParse("1.29"), "flowcontrol.apiserver.k8s.io/v1"},
+	{schema.GroupVersionKind{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta3", Kind: "FlowSchema"}, semver.MustParse("1.32"), "flowcontrol.apiserver.k8s.io/v1"},
+	{schema.GroupVersionKind{Group: "flowcontrol.apiserver.k8s.io", Version: "v1beta3", Kind: "PriorityLevelConfiguration"}, semver.MustParse("1.32"), "flowcontrol.apiserver.k8s.io/v1"},
+}
+
+// preflightFinding is a problem found before the upgrade. Blocking findings stop the upgrade.
+type preflightFinding struct {
+	Check    string
+	Blocking bool
+	Message  string
+}
+
+// preflightReport holds the findings of the preflight checks and the difference between the
+// values of the cluster app before and after the upgrade.
+type preflightReport struct {
+	Findings   []preflightFinding
+	ValuesDiff valuesDiff
+}
+
+func (r *preflightReport) add(check string, blocking bool, format string, args ...interface{}) {
+	r.Findings = append(r.Findings, preflightFinding{Check: check, Blocking: blocking, Message: fmt.Sprintf(format, args...)})
+}
+
+// Blocking returns the findings that stop the upgrade.
+func (r *preflightReport) Blocking() []preflightFinding {
+	blocking := []preflightFinding{}
+	for _, finding := range r.Findings {
+		if finding.Blocking {
+			blocking = append(blocking, finding)
+		}
+	}
+	return blocking
+}
+
+func (r *preflightReport) String() string {
+	b := &strings.Builder{}
+	if len(r.Findings) == 0 {
+		b.WriteString("No findings\n")
+	}
+	for _, finding := range r.Findings {
+		severity := "WARNING"
+		if finding.Blocking {
+			severity = "BLOCKING"
+		}
+		fmt.Fprintf(b, "%s [%s] %s\n", severity, finding.Check, finding.Message)
+	}
+	b.WriteString(r.ValuesDiff.String())
+	return b.String()
+}
+
+// valuesDiff lists the paths of the values that were added, removed or changed.
+type valuesDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+func (d valuesDiff) String() string {
+	if len(d.Added)+len(d.Removed)+len(d.Changed) == 0 {
+		return "Rendered values are unchanged\n"
+	}
+	b := &strings.Builder{}
+	b.WriteString("Rendered values diff:\n")
+	for _, path := range d.Added {
+		fmt.Fprintf(b, "  + %s\n", path)
+	}
+	for _, path := range d.Removed {
+		fmt.Fprintf(b, "  - %s\n", path)
+	}
+	for _, path := range d.Changed {
+		fmt.Fprintf(b, "  ~ %s\n", path)
+	}
+	return b.String()
+}
+
+// runPreflight checks that the cluster can be upgraded from the deployed cluster App to
+// builtCluster, which targets the Giant Swarm release releaseVersion.
+func runPreflight(ctx context.Context, mcClient, wcClient *client.Client, current *v1alpha1.App, builtCluster *application.BuiltCluster, releaseVersion string) *preflightReport {
+	report := &preflightReport{}
+
+	from := chartSource{AppName: current.Spec.Name, Version: current.Spec.Version}
+	to := chartSource{AppName: builtCluster.Cluster.App.Spec.Name, Version: builtCluster.Cluster.App.Spec.Version}
+	layers, err := appValuesLayers(ctx, mcClient, current)
+	if err != nil {
+		report.add(preflightCheckValues, false, "couldn't get the values of the deployed cluster App: %v", err)
+	} else {
+		// Only the user config ConfigMap is replaced by the upgrade, the other values stay as they are.
+		toLayers := withUserValues(layers, []byte(builtCluster.Cluster.ConfigMap.Data["values"]))
+		checkValues(ctx, report, from, to, layerValues(layers), layerValues(toLayers))
+	}
+
+	if releaseVersion == "" {
+		logger.Log("Not checking for removed APIs, the target release is unknown")
+	} else if target, err := targetKubernetesVersion(ctx, mcClient, to.AppName, releaseVersion); err != nil {
+		report.add(preflightCheckRemovedAPIs, false, "couldn't get the target Kubernetes version: %v", err)
+	} else {
+		logger.Log("Checking for APIs removed in Kubernetes %s", target)
+		checkRemovedAPIs(ctx, report, wcClient, target)
+	}
+
+	checkDeprecatedCRDs(ctx, report, wcClient)
+	return report
+}
+
+// releaseVersion returns the Giant Swarm release version of release, which is the one set in
+// the E2E_RELEASE_VERSION environment var when it is empty.
+func releaseVersion(release application.ReleasePair) string {
+	if release.Version != "" {
+		return release.Version
+	}
+	return os.Getenv(env.ReleaseVersion)
+}
+
+// valuesLayer is a ConfigMap or Secret holding values of an App, merged by app-operator in
+// the order of their priority.
+type valuesLayer struct {
+	Priority int
+	// User is true for the user config ConfigMap, which clustertest builds.
+	User   bool
+	Values []byte
+}
+
+// appValuesLayers returns the values of the catalog config, the config, the user config and
+// the extra configs of app, in the order app-operator merges them: by priority, with the
+// ConfigMap before the Secret and the extra configs after the config of the same priority.
+func appValuesLayers(ctx context.Context, mcClient *client.Client, app *v1alpha1.App) ([]valuesLayer, error) {
+	layers := []valuesLayer{}
+	add := func(priority int, user bool, kind, name, namespace string) error {
+		if name == "" {
+			return nil
+		}
+		values, err := valuesFrom(ctx, mcClient, kind, name, namespace)
+		if err != nil {
+			return err
+		}
+		layers = append(layers, valuesLayer{Priority: priority, User: user, Values: values})
+		return nil
+	}
+
+	catalogNamespace := app.Spec.CatalogNamespace
+	if catalogNamespace == "" {
+		catalogNamespace = metav1.NamespaceDefault
+	}
+	catalog := &v1alpha1.Catalog{}
+	err := mcClient.Get(ctx, cr.ObjectKey{Name: app.Spec.Catalog, Namespace: catalogNamespace}, catalog)
+	switch {
+	case apierror.IsNotFound(err):
+		logger.Log("Catalog '%s' not found, not merging its values", app.Spec.Catalog)
+	case err != nil:
+		return nil, err
+	case catalog.Spec.Config != nil:
+		if configMap := catalog.Spec.Config.ConfigMap; configMap != nil {
+			if err := add(v1alpha1.ConfigPriorityCatalog, false, "configMap", configMap.Name, configMap.Namespace); err != nil {
+				return nil, err
+			}
+		}
+		if secret := catalog.Spec.Config.Secret; secret != nil {
+			if err := add(v1alpha1.ConfigPriorityCatalog, false, "secret", secret.Name, secret.Namespace); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, source := range []struct {
+		priority              int
+		user                  bool
+		kind, name, namespace string
+	}{
+		{v1alpha1.ConfigPriorityCluster, false, "configMap", app.Spec.Config.ConfigMap.Name, app.Spec.Config.ConfigMap.Namespace},
+		{v1alpha1.ConfigPriorityCluster, false, "secret", app.Spec.Config.Secret.Name, app.Spec.Config.Secret.Namespace},
+		{v1alpha1.ConfigPriorityUser, true, "configMap", app.Spec.UserConfig.ConfigMap.Name, app.Spec.UserConfig.ConfigMap.Namespace},
+		{v1alpha1.ConfigPriorityUser, false, "secret", app.Spec.UserConfig.Secret.Name, app.Spec.UserConfig.Secret.Namespace},
+	} {
+		if err := add(source.priority, source.user, source.kind, source.name, source.namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, extra := range app.Spec.ExtraConfigs {
+		priority := extra.Priority
+		if priority == 0 {
+			priority = v1alpha1.ConfigPriorityDefault
+		}
+		kind := extra.Kind
+		if kind == "" {
+			kind = "configMap"
+		}
+		if err := add(priority, false, kind, extra.Name, extra.Namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(layers, func(i, j int) bool { return layers[i].Priority < layers[j].Priority })
+	return layers, nil
+}
+
+// valuesFrom returns the values of a ConfigMap or Secret holding App values.
+func valuesFrom(ctx context.Context, mcClient *client.Client, kind, name, namespace string) ([]byte, error) {
+	switch kind {
+	case "configMap":
+		configMap := &corev1.ConfigMap{}
+		if err := mcClient.Get(ctx, cr.ObjectKey{Name: name, Namespace: namespace}, configMap); err != nil {
+			return nil, err
+		}
+		return []byte(configMap.Data["values"]), nil
+	case "secret":
+		secret := &corev1.Secret{}
+		if err := mcClient.Get(ctx, cr.ObjectKey{Name: name, Namespace: namespace}, secret); err != nil {
+			return nil, err
+		}
+		return secret.Data["values"], nil
+	default:
+		return nil, fmt.Errorf("unknown config kind %q of %s/%s", kind, namespace, name)
+	}
+}
+
+// withUserValues returns a copy of layers with the values of the user config ConfigMap
+// replaced by values, adding it if the App has none.
+func withUserValues(layers []valuesLayer, values []byte) []valuesLayer {
+	result := []valuesLayer{}
+	replaced := false
+	for _, layer := range layers {
+		if layer.User {
+			layer.Values = values
+			replaced = true
+		}
+		result = append(result, layer)
+	}
+	if !replaced {
+		// The ConfigMap goes before the user config Secret and the extra configs of the same priority.
+		i := slices.IndexFunc(result, func(layer valuesLayer) bool { return layer.Priority >= v1alpha1.ConfigPriorityUser })
+		if i < 0 {
+			i = len(result)
+		}
+		result = slices.Insert(result, i, valuesLayer{Priority: v1alpha1.ConfigPriorityUser, User: true, Values: values})
+	}
+	return result
+}
+
+func layerValues(layers []valuesLayer) [][]byte {
+	values := [][]byte{}
+	for _, layer := range layers {
+		values = append(values, layer.Values)
+	}
+	return values
+}
+
+// chartSource identifies the chart files of a cluster app version.
+type chartSource struct {
+	AppName string
+	Version string
+}
+
+// ref returns the git ref the chart version was built from.
+func (s chartSource) ref() string {
+	if match := commitVersion.FindStringSubmatch(s.Version); match != nil {
+		return match[1]
+	}
+	return "v" + strings.TrimPrefix(s.Version, "v")
+}
+
+func (s chartSource) fetch(ctx context.Context, httpClient *http.Client, file string) ([]byte, error) {
+	url := fmt.Sprintf(chartSourceURL, s.AppName, s.ref(), file)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// checkValues renders the values of the cluster app before and after the upgrade from the
+// chart defaults and the values of the App, diffs them and validates the new ones against the
+// values schema of the target chart.
+func checkValues(ctx context.Context, report *preflightReport, from, to chartSource, fromValues, toValues [][]byte) {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+
+	toDefaults, err := to.fetch(ctx, httpClient, "values.yaml")
+	if err != nil {
+		report.add(preflightCheckValues, false, "couldn't fetch the default values of %s %s: %v", to.AppName, to.Version, err)
+		return
+	}
+	toRendered, err := renderValues(toDefaults, toValues...)
+	if err != nil {
+		report.add(preflightCheckValues, true, "couldn't render the values of %s %s: %v", to.AppName, to.Version, err)
+		return
+	}
+
+	fromDefaults, err := from.fetch(ctx, httpClient, "values.yaml")
+	if err != nil {
+		report.add(preflightCheckValues, false, "couldn't fetch the default values of %s %s: %v", from.AppName, from.Version, err)
+	} else if fromRendered, err := renderValues(fromDefaults, fromValues...); err != nil {
+		report.add(preflightCheckValues, false, "couldn't render the values of %s %s: %v", from.AppName, from.Version, err)
+	} else {
+		report.ValuesDiff = diffValues(fromRendered, toRendered)
+	}
+
+	valuesSchema, err := to.fetch(ctx, httpClient, "values.schema.json")
+	if err != nil {
+		report.add(preflightCheckValues, false, "couldn't fetch the values schema of %s %s: %v", to.AppName, to.Version, err)
+		return
+	}
+	if err := validateValues(valuesSchema, toRendered); err != nil {
+		report.add(preflightCheckValues, true, "values are rejected by the schema of %s %s: %v", to.AppName, to.Version, err)
+	}
+}
+
+// renderValues merges each of values in turn into the chart defaults the way Helm does: maps
+// are merged recursively, other values replace the defaults and null removes a default.
+func renderValues(defaults []byte, values ...[]byte) (map[string]interface{}, error) {
+	rendered := map[string]interface{}{}
+	if err := yaml.Unmarshal(defaults, &rendered); err != nil {
+		return nil, fmt.Errorf("failed to parse default values: %w", err)
+	}
+	for _, layer := range values {
+		user := map[string]interface{}{}
+		if err := yaml.Unmarshal(layer, &user); err != nil {
+			return nil, fmt.Errorf("failed to parse values: %w", err)
+		}
+		mergeValues(rendered, user)
+	}
+	return rendered, nil
+}
+
+func mergeValues(dst, src map[string]interface{}) {
+	for key, value := range src {
+		if value == nil {
+			delete(dst, key)
+			continue
+		}
+		srcMap, srcIsMap := value.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && dstIsMap {
+			mergeValues(dstMap, srcMap)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// diffValues compares the leaves of two sets of values. Lists are compared as a whole.
+func diffValues(from, to map[string]interface{}) valuesDiff {
+	fromLeaves := map[string]interface{}{}
+	flattenValues("", from, fromLeaves)
+	toLeaves := map[string]interface{}{}
+	flattenValues("", to, toLeaves)
+
+	diff := valuesDiff{}
+	for path, value := range toLeaves {
+		previous, ok := fromLeaves[path]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, path)
+		case !reflect.DeepEqual(previous, value):
+			diff.Changed = append(diff.Changed, path)
+		}
+	}
+	for path := range fromLeaves {
+		if _, ok := toLeaves[path]; !ok {
+			diff.Removed = append(diff.Removed, path)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+	return diff
+}
+
+func flattenValues(prefix string, values map[string]interface{}, leaves map[string]interface{}) {
+	for key, value := range values {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenValues(path, nested, leaves)
+			continue
+		}
+		leaves[path] = value
+	}
+}
+
+// validateValues validates the rendered values against a values.schema.json.
+func validateValues(valuesSchema []byte, values map[string]interface{}) error {
+	schemaDoc, err := jsonschema.UnmarshalJSON(bytes.NewReader(valuesSchema))
+	if err != nil {
+		return fmt.Errorf("failed to parse values schema: %w", err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("values.schema.json", schemaDoc); err != nil {
+		return fmt.Errorf("failed to load values schema: %w", err)
+	}
+	valuesSchemaCompiled, err := compiler.Compile("values.schema.json")
+	if err != nil {
+		return fmt.Errorf("failed to compile values schema: %w", err)
+	}
+
+	// Round-trip through JSON so the numbers have the types the validator expects.
+	data, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	return valuesSchemaCompiled.Validate(doc)
+}
+
+// targetKubernetesVersion returns the Kubernetes version of the Giant Swarm release the
+// cluster is upgraded to, read from its Release on the MC.
+func targetKubernetesVersion(ctx context.Context, mcClient *client.Client, clusterAppName, releaseVersion string) (*semver.Version, error) {
+	release := &unstructured.Unstructured{}
+	release.SetGroupVersionKind(releaseGVK)
+	name := fmt.Sprintf("%s-%s", strings.TrimPrefix(clusterAppName, "cluster-"), strings.TrimPrefix(releaseVersion, "v"))
+	if err := mcClient.Get(ctx, cr.ObjectKey{Name: name}, release); err != nil {
+		return nil, fmt.Errorf("failed to get Release %s: %w", name, err)
+	}
+
+	components, _, err := unstructured.NestedSlice(release.Object, "spec", "components")
+	if err != nil {
+		return nil, err
+	}
+	for _, component := range components {
+		c, ok := component.(map[string]interface{})
+		if !ok || c["name"] != "kubernetes" {
+			continue
+		}
+		version, _ := c["version"].(string)
+		return semver.NewVersion(version)
+	}
+	return nil, fmt.Errorf("release %s has no kubernetes component", name)
+}
+
+// checkRemovedAPIs reports the objects of the workload cluster that are only served by API
+// versions removed in the target Kubernetes version. Objects of a kind whose replacement
+// version is already served are converted by the API server and aren't reported.
+func checkRemovedAPIs(ctx context.Context, report *preflightReport, wcClient *client.Client, target *semver.Version) {
+	served := func(gvk schema.GroupVersionKind) bool {
+		_, err := wcClient.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+		return err == nil
+	}
+
+	for _, api := range removedAPIsFor(target, served) {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(api.GroupVersionKind.GroupVersion().WithKind(api.GroupVersionKind.Kind + "List"))
+		if err := wcClient.List(ctx, list, cr.Limit(100)); err != nil {
+			report.add(preflightCheckRemovedAPIs, false, "couldn't list %s: %v", api.GroupVersionKind, err)
+			continue
+		}
+		if len(list.Items) == 0 {
+			continue
+		}
+
+		names := []string{}
+		for _, item := range list.Items {
+			names = append(names, cr.ObjectKeyFromObject(&item).String())
+		}
+		report.add(preflightCheckRemovedAPIs, true, "%s %s is removed in Kubernetes %s and still has objects: %s",
+			api.GroupVersionKind.GroupVersion(), api.GroupVersionKind.Kind, api.RemovedIn.Original(), strings.Join(names, ", "))
+	}
+}
+
+// removedAPIsFor returns the removed APIs the cluster serves without serving their replacement,
+// for the ones removed in target or before.
+func removedAPIsFor(target *semver.Version, served func(schema.GroupVersionKind) bool) []removedAPI {
+	result := []removedAPI{}
+	for _, api := range removedAPIs {
+		if target.LessThan(api.RemovedIn) || !served(api.GroupVersionKind) {
+			continue
+		}
+		if api.Replacement != "" {
+			replacement, err := schema.ParseGroupVersion(api.Replacement)
+			if err == nil && served(replacement.WithKind(api.GroupVersionKind.Kind)) {
+				continue
+			}
+		}
+		result = append(result, api)
+	}
+	return result
+}
+
+// checkDeprecatedCRDs reports the custom resources whose CRD only serves deprecated versions.
+func checkDeprecatedCRDs(ctx context.Context, report *preflightReport, wcClient *client.Client) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind("CustomResourceDefinitionList"))
+	if err := wcClient.List(ctx, list); err != nil {
+		report.add(preflightCheckCRDs, false, "couldn't list CustomResourceDefinitions: %v", err)
+		return
+	}
+
+	for _, item := range list.Items {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, crd); err != nil {
+			report.add(preflightCheckCRDs, false, "couldn't decode CustomResourceDefinition %s: %v", item.GetName(), err)
+			continue
+		}
+		version, ok := onlyDeprecatedVersion(crd)
+		if !ok {
+			continue
+		}
+
+		objects := &unstructured.UnstructuredList{}
+		objects.SetGroupVersionKind(schema.GroupVersionKind{Group: crd.Spec.Group, Version: version, Kind: crd.Spec.Names.ListKind})
+		if err := wcClient.List(ctx, objects, cr.Limit(1)); err != nil {
+			if !meta.IsNoMatchError(err) {
+				report.add(preflightCheckCRDs, false, "couldn't list %s: %v", crd.Name, err)
+			}
+			continue
+		}
+		if len(objects.Items) > 0 {
+			report.add(preflightCheckCRDs, false, "%s only serves deprecated versions and has objects", crd.Name)
+		}
+	}
+}
+
+// onlyDeprecatedVersion returns a served version of the CRD if all of its served versions
+// are deprecated.
+func onlyDeprecatedVersion(crd *apiextensionsv1.CustomResourceDefinition) (string, bool) {
+	version := ""
+	for _, v := range crd.Spec.Versions {
+		if !v.Served {
+			continue
+		}
+		if !v.Deprecated {
+			return "", false
+		}
+		version = v.Name
+	}
+	return version, version != ""
+}
@@ -0,0 +1,328 @@
+package upgrade
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/giantswarm/apiextensions-application/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cr "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/testenv"
+)
+
+func TestRenderValuesAndDiff(t *testing.T) {
+	fromDefaults := []byte(`
+global:
+  controlPlane:
+    replicas: 3
+  metadata:
+    servicePriority: highest
+  providerSpecific:
+    region: ""
+`)
+	fromValues := []byte(`
+global:
+  metadata:
+    name: test
+  providerSpecific:
+    region: eu-west-1
+`)
+	toDefaults := []byte(`
+global:
+  controlPlane:
+    replicas: 3
+    oidc: {}
+  metadata:
+    servicePriority: highest
+  nodePools:
+    pool0:
+      maxSize: 4
+`)
+	toValues := []byte(`
+global:
+  metadata:
+    name: test
+    servicePriority: null
+  nodePools:
+    pool0:
+      maxSize: 10
+`)
+
+	from, err := renderValues(fromDefaults, fromValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := renderValues(toDefaults, toValues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := valuesDiff{
+		Added:   []string{"global.controlPlane.oidc", "global.nodePools.pool0.maxSize"},
+		Removed: []string{"global.metadata.servicePriority", "global.providerSpecific.region"},
+	}
+	if diff := diffValues(from, to); !reflect.DeepEqual(diff, expected) {
+		t.Errorf("expected diff %+v, got %+v", expected, diff)
+	}
+
+	to["global"].(map[string]interface{})["controlPlane"].(map[string]interface{})["replicas"] = 1
+	if diff := diffValues(from, to); !reflect.DeepEqual(diff.Changed, []string{"global.controlPlane.replicas"}) {
+		t.Errorf("expected replicas to be changed, got %+v", diff)
+	}
+}
+
+func TestAppValuesLayers(t *testing.T) {
+	configMap := func(name, values string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "org-test"}, Data: map[string]string{"values": values}}
+	}
+	secret := func(name, values string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "org-test"}, Data: map[string][]byte{"values": []byte(values)}}
+	}
+
+	catalog := &v1alpha1.Catalog{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "giantswarm"},
+		Spec: v1alpha1.CatalogSpec{Config: &v1alpha1.CatalogSpecConfig{
+			ConfigMap: &v1alpha1.CatalogSpecConfigConfigMap{Name: "catalog", Namespace: "org-test"},
+		}},
+	}
+	app := &v1alpha1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "org-test"},
+		Spec: v1alpha1.AppSpec{
+			Catalog:          "cluster",
+			CatalogNamespace: "giantswarm",
+			Config: v1alpha1.AppSpecConfig{
+				ConfigMap: v1alpha1.AppSpecConfigConfigMap{Name: "cluster", Namespace: "org-test"},
+			},
+			UserConfig: v1alpha1.AppSpecUserConfig{
+				ConfigMap: v1alpha1.AppSpecUserConfigConfigMap{Name: "user", Namespace: "org-test"},
+				Secret:    v1alpha1.AppSpecUserConfigSecret{Name: "user", Namespace: "org-test"},
+			},
+			ExtraConfigs: []v1alpha1.AppExtraConfig{
+				{Kind: "secret", Name: "extra-high", Namespace: "org-test", Priority: v1alpha1.ConfigPriorityMaximum},
+				{Name: "extra-default", Namespace: "org-test"},
+			},
+		},
+	}
+	mcClient := testenv.NewClient([]cr.Object{
+		catalog,
+		configMap("catalog", "layer: catalog"),
+		configMap("cluster", "layer: cluster"),
+		configMap("user", "layer: user-configmap"),
+		secret("user", "layer: user-secret"),
+		configMap("extra-default", "layer: extra-default"),
+		secret("extra-high", "layer: extra-high"),
+	}...)
+
+	layers, err := appValuesLayers(context.Background(), mcClient, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := []string{}
+	for _, layer := range layerValues(layers) {
+		values = append(values, string(layer))
+	}
+	expected := []string{"layer: catalog", "layer: extra-default", "layer: cluster", "layer: user-configmap", "layer: user-secret", "layer: extra-high"}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected layers %v, got %v", expected, values)
+	}
+
+	toLayers := withUserValues(layers, []byte("layer: built"))
+	if got := string(toLayers[3].Values); got != "layer: built" {
+		t.Errorf("expected the user ConfigMap to be replaced, got %q", got)
+	}
+	if got := string(layers[3].Values); got != "layer: user-configmap" {
+		t.Errorf("expected the deployed layers to be unchanged, got %q", got)
+	}
+
+	// The Secret and the extra config of higher priority still win over the built user values.
+	rendered, err := renderValues([]byte("layer: defaults"), layerValues(toLayers)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rendered["layer"] != "extra-high" {
+		t.Errorf("expected the highest priority layer to win, got %v", rendered["layer"])
+	}
+
+	app.Spec.UserConfig.ConfigMap.Name = ""
+	layers, err = appValuesLayers(context.Background(), mcClient, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toLayers = withUserValues(layers, []byte("layer: built"))
+	if got := string(toLayers[3].Values); len(toLayers) != len(layers)+1 || got != "layer: built" {
+		t.Errorf("expected the built user values to be added before the user Secret, got %q", got)
+	}
+}
+
+func TestValidateValues(t *testing.T) {
+	valuesSchema := []byte(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "global": {
+      "type": "object",
+      "properties": {
+        "controlPlane": {
+          "type": "object",
+          "properties": {
+            "replicas": {"type": "integer", "enum": [1, 3]}
+          }
+        }
+      },
+      "additionalProperties": false
+    }
+  }
+}`)
+
+	valid, err := renderValues([]byte("global:\n  controlPlane:\n    replicas: 3\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validateValues(valuesSchema, valid); err != nil {
+		t.Errorf("expected values to be valid, got %v", err)
+	}
+
+	invalid, err := renderValues([]byte("global:\n  controlPlane:\n    replicas: 3\n"), []byte("global:\n  controlPlane:\n    replicas: 2\n  connectivity: {}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = validateValues(valuesSchema, invalid)
+	if err == nil {
+		t.Fatal("expected values to be rejected")
+	}
+	for _, expected := range []string{"replicas", "connectivity"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error to mention %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestChartSourceRef(t *testing.T) {
+	testCases := []struct {
+		version  string
+		expected string
+	}{
+		{version: "2.3.0", expected: "v2.3.0"},
+		{version: "v2.3.0", expected: "v2.3.0"},
+		{version: "2.3.0-rc.1", expected: "v2.3.0-rc.1"},
+		{version: "2.3.0-5f4372ac697fce58d524830a985ede2082d7f461", expected: "5f4372ac697fce58d524830a985ede2082d7f461"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.version, func(t *testing.T) {
+			if ref := (chartSource{AppName: "cluster-aws", Version: tc.version}).ref(); ref != tc.expected {
+				t.Errorf("expected ref %q, got %q", tc.expected, ref)
+			}
+		})
+	}
+}
+
+func TestRemovedAPIsFor(t *testing.T) {
+	served := func(gvks ...schema.GroupVersionKind) func(schema.GroupVersionKind) bool {
+		return func(gvk schema.GroupVersionKind) bool {
+			for _, s := range gvks {
+				if s == gvk {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	flowSchema := func(version string) schema.GroupVersionKind {
+		return schema.GroupVersionKind{Group: "flowcontrol.apiserver.k8s.io", Version: version, Kind: "FlowSchema"}
+	}
+	psp := schema.GroupVersionKind{Group: "policy", Version: "v1beta1", Kind: "PodSecurityPolicy"}
+
+	testCases := []struct {
+		name     string
+		target   string
+		served   func(schema.GroupVersionKind) bool
+		expected []schema.GroupVersionKind
+	}{
+		{
+			name:     "not removed yet",
+			target:   "1.31.4",
+			served:   served(flowSchema("v1beta3")),
+			expected: []schema.GroupVersionKind{},
+		},
+		{
+			name:     "removed without replacement being served",
+			target:   "1.32.0",
+			served:   served(flowSchema("v1beta3")),
+			expected: []schema.GroupVersionKind{flowSchema("v1beta3")},
+		},
+		{
+			name:     "removed with replacement being served",
+			target:   "1.32.0",
+			served:   served(flowSchema("v1beta3"), flowSchema("v1")),
+			expected: []schema.GroupVersionKind{},
+		},
+		{
+			name:     "removed without replacement",
+			target:   "1.25.0",
+			served:   served(psp),
+			expected: []schema.GroupVersionKind{psp},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := []schema.GroupVersionKind{}
+			for _, api := range removedAPIsFor(semver.MustParse(tc.target), tc.served) {
+				result = append(result, api.GroupVersionKind)
+			}
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestOnlyDeprecatedVersion(t *testing.T) {
+	crd := func(versions ...apiextensionsv1.CustomResourceDefinitionVersion) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{Spec: apiextensionsv1.CustomResourceDefinitionSpec{Versions: versions}}
+	}
+
+	if _, ok := onlyDeprecatedVersion(crd(
+		apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1alpha1", Served: true, Deprecated: true},
+		apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1", Served: true},
+	)); ok {
+		t.Error("expected a CRD serving a non-deprecated version not to be reported")
+	}
+
+	version, ok := onlyDeprecatedVersion(crd(
+		apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1alpha1", Served: true, Deprecated: true},
+		apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1", Served: false},
+	))
+	if !ok || version != "v1alpha1" {
+		t.Errorf("expected v1alpha1 to be reported, got %q", version)
+	}
+}
+
+func TestPreflightReport(t *testing.T) {
+	report := &preflightReport{}
+	report.add(preflightCheckCRDs, false, "%s only serves deprecated versions", "foos.example.com")
+	report.add(preflightCheckRemovedAPIs, true, "%s is removed", "batch/v1beta1")
+
+	if blocking := report.Blocking(); len(blocking) != 1 || blocking[0].Check != preflightCheckRemovedAPIs {
+		t.Errorf("expected one blocking finding, got %+v", blocking)
+	}
+
+	s := report.String()
+	for _, expected := range []string{
+		"WARNING [deprecated-crds] foos.example.com only serves deprecated versions",
+		"BLOCKING [removed-apis] batch/v1beta1 is removed",
+		"Rendered values are unchanged",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("expected report to contain %q:\n%s", expected, s)
+		}
+	}
+}
@@ -2,12 +2,12 @@ package upgrade
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
-	"sort"
+	"strings"
 	"time"
 
-	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	kubeadm "sigs.k8s.io/cluster-api/api/controlplane/kubeadm/v1beta2"
@@ -34,6 +34,8 @@ type nodeInfo struct {
 	Name      string
 	UID       types.UID
 	CreatedAt time.Time
+	// Pool is the control plane or the worker pool the node belongs to.
+	Pool string
 }
 
 const (
@@ -42,20 +44,28 @@ const (
 )
 
 type TestConfig struct {
+	// ControlPlaneNodesTimeout and WorkerNodesTimeout override the controlPlaneNodesReadyTimeout
+	// and workerNodesReadyTimeout timeouts for the upgrade specs only. Zero uses the timeout registry.
 	ControlPlaneNodesTimeout     time.Duration
 	WorkerNodesTimeout           time.Duration
 	ObservabilityBundleInstalled bool
 	SecurityBundleInstalled      bool
 	ControlPlaneType             string
+	// MaxWorkloadDowntime is the longest total time the test app may be unreachable on each
+	// probed path while the cluster is upgraded.
+	MaxWorkloadDowntime time.Duration
+	// MaxAPIServerUnavailability is the longest total time each API server check may fail
+	// while the cluster is upgraded.
+	MaxAPIServerUnavailability time.Duration
 }
 
 func NewTestConfigWithDefaults() *TestConfig {
 	return &TestConfig{
-		ControlPlaneNodesTimeout:     15 * time.Minute,
-		WorkerNodesTimeout:           15 * time.Minute,
 		ObservabilityBundleInstalled: true,
 		SecurityBundleInstalled:      true,
 		ControlPlaneType:             ControlPlaneTypeKubeadm,
+		MaxWorkloadDowntime:          time.Minute,
+		MaxAPIServerUnavailability:   time.Minute,
 	}
 }
 
@@ -95,189 +105,263 @@ func controlPlaneUpdateSpecForType(cpType string) (controlPlaneUpdateSpec, bool)
 	}
 }
 
-func Run(cfg *TestConfig) {
+func Run(env *state.Environment, cfg *TestConfig) {
 	Context("upgrade", func() {
 		var cluster *application.Cluster
 		var wcClient *client.Client
 		var preUpgradeControlPlaneResourceGeneration int64
-		var initialNodes map[string]nodeInfo
-		var initialNodeCount int
+		var rollWatcher *nodeRollWatcher
+		var nodesRolled bool
+		var persisted persistedData
+		var prober *availabilityProber
+		var watcher *apiServerWatcher
 
 		preUpgradeControlPlaneResourceGeneration = 0
 
 		BeforeAll(func() {
 			var err error
-			cluster = state.GetCluster()
+			cluster = env.Cluster()
 
-			preUpgradeControlPlane, kcpErr := state.GetFramework().GetControlPlaneResource(state.GetContext(), cluster.Name, cluster.GetNamespace())
-			Expect(kcpErr).NotTo(HaveOccurred())
-			if preUpgradeControlPlane != nil {
-				preUpgradeControlPlaneResourceGeneration = preUpgradeControlPlane.GetGeneration()
-			}
-
-			wcClient, err = state.GetFramework().WC(cluster.Name)
+			wcClient, err = env.Framework().WC(cluster.Name)
 			Expect(err).NotTo(HaveOccurred())
 
-			nodes := &corev1.NodeList{}
-			err = wcClient.List(state.GetContext(), nodes)
+			rollWatcher, err = startNodeRollWatcher(env.Context(), wcClient, env.MC(), cluster.Name, cluster.GetNamespace())
 			Expect(err).NotTo(HaveOccurred())
-			initialNodes = make(map[string]nodeInfo, len(nodes.Items))
-			for _, node := range nodes.Items {
-				initialNodes[node.Name] = nodeInfo{
-					Name:      node.Name,
-					UID:       node.UID,
-					CreatedAt: node.CreationTimestamp.Time,
-				}
-			}
-			initialNodeCount = len(nodes.Items)
-			logger.Log("Node roll detection - Captured %d initial nodes before upgrade", initialNodeCount)
-		})
+			logger.Log("Node roll detection - Captured %d initial nodes before upgrade", len(rollWatcher.tracker.initial))
 
-		BeforeEach(func() {
-			var err error
-			cluster = state.GetCluster()
-			wcClient, err = state.GetFramework().WC(cluster.Name)
-			Expect(err).NotTo(HaveOccurred())
-		})
-
-		It("has all the control-plane nodes running", func() {
-			if cfg.ControlPlaneType == ControlPlaneTypeAWSManaged {
-				Skip("Skipping control plane nodes readiness check for EKS clusters")
+			// The API server is called directly with the CAPI kubeconfig of the cluster, which
+			// the test runner can't reach for private clusters.
+			apiServer, err := newAPIServerClient(env.Context(), env.MC(), cluster.Name, cluster.GetNamespace())
+			if err == nil {
+				err = apiServer.Ready(env.Context())
+			}
+			if err != nil {
+				logger.Log("Not watching the API server, it isn't reachable: %v", err)
+				apiServer = nil
+			} else {
+				watcher = startAPIServerWatcher(env.Context(), apiServer)
 			}
 
-			replicas, err := state.GetFramework().GetExpectedControlPlaneReplicas(state.GetContext(), state.GetCluster().Name, state.GetCluster().GetNamespace())
-			Expect(err).NotTo(HaveOccurred())
+			Eventually(func() error {
+				return createAvailabilityApp(env.Context(), wcClient)
+			}).
+				WithTimeout(env.Timeout(timeout.ResourceApply)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
 
-			Eventually(
-				wait.ConsistentWaitCondition(
-					wait.AreNumNodesReady(state.GetContext(), wcClient, int(replicas), &cr.MatchingLabels{"node-role.kubernetes.io/control-plane": ""}),
-					12,
-					5*time.Second,
-				)).
-				WithTimeout(cfg.ControlPlaneNodesTimeout).
+			Eventually(checkAvailabilityAppReady(env.Context(), wcClient)).
+				WithTimeout(env.Timeout(timeout.WorkloadsReady)).
 				WithPolling(wait.DefaultInterval).
 				Should(Succeed())
+
+			if apiServer != nil {
+				if err := probeAPIServer(env.Context(), apiServer); err != nil {
+					logger.Log("Not probing the app through the API server Service proxy: %v", err)
+					apiServer = nil
+				}
+			}
+
+			prober = startAvailabilityProber(env.Context(), wcClient, apiServer)
 		})
 
-		It("has all the worker nodes running", func() {
-			values := &application.ClusterValues{}
-			err := state.GetFramework().MC().GetHelmValues(cluster.Name, cluster.GetNamespace(), values)
+		BeforeEach(func() {
+			var err error
+			cluster = env.Cluster()
+			wcClient, err = env.Framework().WC(cluster.Name)
 			Expect(err).NotTo(HaveOccurred())
-
-			Eventually(wait.Consistent(common.CheckWorkerNodesReady(state.GetContext(), wcClient, values), 12, 5*time.Second)).
-				WithTimeout(cfg.WorkerNodesTimeout).
-				WithPolling(wait.DefaultInterval).
-				Should(Succeed())
 		})
 
-		It("has Cluster Available condition with Status='True'", func() {
-			// Overriding the default timeout, when clusterReadyTimeout is set
-			timeout := state.GetTestTimeout(timeout.ClusterReadyTimeout, 15*time.Minute)
+		// healthChecks checks the nodes, the Cluster conditions, the apps and the workloads of the cluster.
+		// They run before the upgrade and after every intermediate release of the upgrade path.
+		healthChecks := func() {
+			It("has all the control-plane nodes running", func() {
+				if cfg.ControlPlaneType == ControlPlaneTypeAWSManaged {
+					Skip("Skipping control plane nodes readiness check for EKS clusters")
+				}
 
-			mcClient := state.GetFramework().MC()
-			cluster := state.GetCluster()
-			Eventually(wait.IsClusterConditionSet(state.GetContext(), mcClient, cluster.Name, cluster.GetNamespace(), capi.AvailableCondition, metav1.ConditionTrue, "")).
-				WithTimeout(timeout).
-				WithPolling(wait.DefaultInterval).
-				Should(BeTrue())
-		})
+				replicas, err := env.Framework().GetExpectedControlPlaneReplicas(env.Context(), env.Cluster().Name, env.Cluster().GetNamespace())
+				Expect(err).NotTo(HaveOccurred())
 
-		It("has all machine pools ready and running", func() {
-			mcClient := state.GetFramework().MC()
-			cluster := state.GetCluster()
+				Eventually(
+					wait.ConsistentWaitCondition(
+						wait.AreNumNodesReady(env.Context(), wcClient, int(replicas), &cr.MatchingLabels{"node-role.kubernetes.io/control-plane": ""}),
+						12,
+						5*time.Second,
+					)).
+					WithTimeout(timeoutOrDefault(env, cfg.ControlPlaneNodesTimeout, timeout.ControlPlaneNodesReady)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			})
+
+			It("has all the worker nodes running", func() {
+				values := &application.ClusterValues{}
+				err := env.MC().GetHelmValues(cluster.Name, cluster.GetNamespace(), values)
+				Expect(err).NotTo(HaveOccurred())
 
-			machinePools, err := state.GetFramework().GetMachinePools(state.GetContext(), cluster.Name, cluster.GetNamespace())
-			Expect(err).NotTo(HaveOccurred())
-			if len(machinePools) == 0 {
-				Skip("Machine pools are not found")
-			}
+				Eventually(wait.Consistent(common.CheckWorkerNodesReady(env.Context(), wcClient, values), 12, 5*time.Second)).
+					WithTimeout(timeoutOrDefault(env, cfg.WorkerNodesTimeout, timeout.WorkerNodesReady)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			})
+
+			It("has Cluster Available condition with Status='True'", func() {
+				// Overriding the default timeout, when clusterReadyTimeout is set
+				timeout := env.Timeout(timeout.ClusterReadyTimeout)
+
+				mcClient := env.MC()
+				cluster := env.Cluster()
+				Eventually(wait.IsClusterConditionSet(env.Context(), mcClient, cluster.Name, cluster.GetNamespace(), capi.AvailableCondition, metav1.ConditionTrue, "")).
+					WithTimeout(timeout).
+					WithPolling(wait.DefaultInterval).
+					Should(BeTrue())
+			})
+
+			It("has all machine pools ready and running", func() {
+				mcClient := env.MC()
+				cluster := env.Cluster()
+
+				machinePools, err := env.Framework().GetMachinePools(env.Context(), cluster.Name, cluster.GetNamespace())
+				Expect(err).NotTo(HaveOccurred())
+				if len(machinePools) == 0 {
+					Skip("Machine pools are not found")
+				}
 
-			Eventually(wait.Consistent(common.CheckMachinePoolsReadyAndRunning(state.GetContext(), mcClient, cluster.Name, cluster.GetNamespace()), 5, 5*time.Second)).
-				WithTimeout(30 * time.Minute).
-				WithPolling(wait.DefaultInterval).
-				Should(Succeed())
-		})
+				Eventually(wait.Consistent(common.CheckMachinePoolsReadyAndRunning(env.Context(), mcClient, cluster.Name, cluster.GetNamespace()), 5, 5*time.Second)).
+					WithTimeout(env.Timeout(timeout.MachinePoolsReady)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			})
+
+			common.RunApps(env, &common.TestConfig{
+				ObservabilityBundleInstalled: cfg.ObservabilityBundleInstalled,
+				SecurityBundleInstalled:      cfg.SecurityBundleInstalled,
+			})
+
+			It("has all its Deployments Ready (means all replicas are running)", func() {
+				Eventually(
+					wait.ConsistentWaitCondition(
+						wait.AreAllDeploymentsReady(env.Context(), wcClient),
+						10,
+						time.Second,
+					)).
+					WithTimeout(env.Timeout(timeout.WorkloadsReady)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			})
+
+			It("has all its StatefulSets Ready (means all replicas are running)", func() {
+				Eventually(
+					wait.ConsistentWaitCondition(
+						wait.AreAllStatefulSetsReady(env.Context(), wcClient),
+						10,
+						time.Second,
+					)).
+					WithTimeout(env.Timeout(timeout.WorkloadsReady)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			})
+
+			It("has all its DaemonSets Ready (means all daemon pods are running)", func() {
+				Eventually(
+					wait.ConsistentWaitCondition(
+						wait.AreAllDaemonSetsReady(env.Context(), wcClient),
+						10,
+						time.Second,
+					)).
+					WithTimeout(env.Timeout(timeout.WorkloadsReady)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			})
+
+			It("has all of its Pods in the Running state", func() {
+				Eventually(
+					wait.ConsistentWaitCondition(
+						wait.AreAllPodsInSuccessfulPhase(env.Context(), wcClient),
+						10,
+						time.Second,
+					)).
+					WithTimeout(env.Timeout(timeout.WorkloadsReady)).
+					WithPolling(wait.DefaultInterval).
+					Should(Succeed())
+			})
+		}
+
+		// buildRelease builds the cluster with release applied. Set app versions to `""` so that
+		// the release the upgrade ends with makes use of the overrides set in the
+		// `E2E_OVERRIDE_VERSIONS` environment var, while intermediate releases use the app
+		// versions they pin.
+		buildRelease := func(release application.ReleasePair, intermediate bool) (*application.Cluster, *application.BuiltCluster, error) {
+			releaseCluster := cluster.
+				WithAppVersions("").
+				WithRelease(release)
+			if !intermediate {
+				builtCluster, err := releaseCluster.Build()
+				return releaseCluster, builtCluster, err
+			}
 
-		common.RunApps(&common.TestConfig{
-			ObservabilityBundleInstalled: cfg.ObservabilityBundleInstalled,
-			SecurityBundleInstalled:      cfg.SecurityBundleInstalled,
-		})
+			var builtCluster *application.BuiltCluster
+			err := withoutOverrideVersions(func() error {
+				var err error
+				builtCluster, err = releaseCluster.Build()
+				return err
+			})
+			return releaseCluster, builtCluster, err
+		}
+
+		// preflight checks that release can be applied to the cluster and fails before it is
+		// applied if it can't, rather than waiting for the cluster to become ready again.
+		preflight := func(release application.ReleasePair, intermediate bool) {
+			current, err := env.Framework().GetApp(env.Context(), cluster.ClusterApp.InstallName, cluster.GetNamespace())
+			Expect(err).NotTo(HaveOccurred())
 
-		It("has all its Deployments Ready (means all replicas are running)", func() {
-			Eventually(
-				wait.ConsistentWaitCondition(
-					wait.AreAllDeploymentsReady(state.GetContext(), wcClient),
-					10,
-					time.Second,
-				)).
-				WithTimeout(15 * time.Minute).
-				WithPolling(wait.DefaultInterval).
-				Should(Succeed())
-		})
+			_, builtCluster, err := buildRelease(release, intermediate)
+			Expect(err).NotTo(HaveOccurred())
 
-		It("has all its StatefulSets Ready (means all replicas are running)", func() {
-			Eventually(
-				wait.ConsistentWaitCondition(
-					wait.AreAllStatefulSetsReady(state.GetContext(), wcClient),
-					10,
-					time.Second,
-				)).
-				WithTimeout(15 * time.Minute).
-				WithPolling(wait.DefaultInterval).
-				Should(Succeed())
-		})
+			report := runPreflight(env.Context(), env.MC(), wcClient, current, builtCluster, releaseVersion(release))
+			logger.Log("Upgrade preflight:\n%s", report)
+			helper.RecordUpgradePreflight(report.String())
 
-		It("has all its DaemonSets Ready (means all daemon pods are running)", func() {
-			Eventually(
-				wait.ConsistentWaitCondition(
-					wait.AreAllDaemonSetsReady(state.GetContext(), wcClient),
-					10,
-					time.Second,
-				)).
-				WithTimeout(15 * time.Minute).
-				WithPolling(wait.DefaultInterval).
-				Should(Succeed())
-		})
+			if blocking := report.Blocking(); len(blocking) > 0 {
+				messages := []string{}
+				for _, finding := range blocking {
+					messages = append(messages, fmt.Sprintf("[%s] %s", finding.Check, finding.Message))
+				}
+				Fail(fmt.Sprintf("The upgrade preflight found %d blocking problems:\n%s", len(blocking), strings.Join(messages, "\n")))
+			}
+		}
 
-		It("has all of its Pods in the Running state", func() {
-			Eventually(
-				wait.ConsistentWaitCondition(
-					wait.AreAllPodsInSuccessfulPhase(state.GetContext(), wcClient),
-					10,
-					time.Second,
-				)).
-				WithTimeout(15 * time.Minute).
-				WithPolling(wait.DefaultInterval).
-				Should(Succeed())
-		})
+		// applyRelease applies release to the cluster and waits for the cluster App to be deployed.
+		applyRelease := func(release application.ReleasePair, intermediate bool) {
+			// The control plane roll check compares against the generation from right before the release is applied.
+			controlPlane, err := env.Framework().GetControlPlaneResource(env.Context(), cluster.Name, cluster.GetNamespace())
+			Expect(err).NotTo(HaveOccurred())
+			if controlPlane != nil {
+				preUpgradeControlPlaneResourceGeneration = controlPlane.GetGeneration()
+			}
 
-		It("should apply new version successfully", func() {
-			cluster = cluster.
-				// Set app versions to `""` so that it makes use of the overrides set in the `E2E_OVERRIDE_VERSIONS` environment var
-				WithAppVersions("").
-				// Set release versions to `""` so that it makes use of the overrides set in the `E2E_RELEASE_VERSION` environment var
-				WithRelease(application.ReleasePair{Version: "", Commit: ""})
-			applyCtx, cancelApplyCtx := context.WithTimeout(state.GetContext(), 20*time.Minute)
+			applyCtx, cancelApplyCtx := context.WithTimeout(env.Context(), env.Timeout(timeout.UpgradeApply))
 			defer cancelApplyCtx()
 
-			builtCluster, err := cluster.Build()
+			var builtCluster *application.BuiltCluster
+			cluster, builtCluster, err = buildRelease(release, intermediate)
 			Expect(err).NotTo(HaveOccurred())
 
-			_, err = state.GetFramework().ApplyBuiltCluster(applyCtx, builtCluster)
+			_, err = env.Framework().ApplyBuiltCluster(applyCtx, builtCluster)
 			Expect(err).NotTo(HaveOccurred())
 
 			Eventually(
-				wait.IsAppVersion(state.GetContext(), state.GetFramework().MC(), builtCluster.Cluster.App.Name, builtCluster.Cluster.App.Namespace, builtCluster.Cluster.App.Spec.Version),
-				10*time.Minute, 5*time.Second,
+				wait.IsAppVersion(env.Context(), env.MC(), builtCluster.Cluster.App.Name, builtCluster.Cluster.App.Namespace, builtCluster.Cluster.App.Spec.Version),
+				env.Timeout(timeout.ClusterAppUpgraded), 5*time.Second,
 			).Should(BeTrue())
 
 			Eventually(
-				wait.IsAppDeployed(state.GetContext(), state.GetFramework().MC(), builtCluster.Cluster.App.Name, builtCluster.Cluster.App.Namespace),
-				10*time.Minute, 5*time.Second,
+				wait.IsAppDeployed(env.Context(), env.MC(), builtCluster.Cluster.App.Name, builtCluster.Cluster.App.Namespace),
+				env.Timeout(timeout.ClusterAppUpgraded), 5*time.Second,
 			).Should(BeTrue())
-		})
+		}
 
-		It("successfully finishes the control plane nodes rolling update if it is needed", func() {
+		// waitForControlPlaneRoll waits for the control plane rolling update triggered by the last applied release.
+		waitForControlPlaneRoll := func() {
 			spec, ok := controlPlaneUpdateSpecForType(cfg.ControlPlaneType)
 			if !ok {
 				Skip(fmt.Sprintf("No control plane update check defined for control plane type %q", cfg.ControlPlaneType))
@@ -290,7 +374,7 @@ func Run(cfg *TestConfig) {
 			controlPlaneUpdateStarted := false
 
 			for i := 0; i < numberOfChecks; i++ {
-				controlPlane, err := state.GetFramework().GetControlPlaneResource(state.GetContext(), cluster.Name, cluster.GetNamespace())
+				controlPlane, err := env.Framework().GetControlPlaneResource(env.Context(), cluster.Name, cluster.GetNamespace())
 				Expect(err).NotTo(HaveOccurred())
 
 				if controlPlane == nil {
@@ -332,12 +416,68 @@ func Run(cfg *TestConfig) {
 				Fail("Control plane resource generation changed but the rolling update was never observed to start or complete")
 			}
 
-			mcClient := state.GetFramework().MC()
+			mcClient := env.MC()
 			Eventually(
-				wait.IsControlPlaneConditionSet(state.GetContext(), mcClient, cluster.Name, cluster.GetNamespace(), spec.completeCondition, spec.completeStatus, spec.completeReason),
-				30*time.Minute,
+				wait.IsControlPlaneConditionSet(env.Context(), mcClient, cluster.Name, cluster.GetNamespace(), spec.completeCondition, spec.completeStatus, spec.completeReason),
+				env.Timeout(timeout.ControlPlaneRoll),
 				30*time.Second,
 			).Should(BeTrue())
+		}
+
+		healthChecks()
+
+		It("writes data to a StatefulSet volume before the upgrade", func() {
+			Eventually(func() error {
+				return createPersistenceStatefulSet(env.Context(), wcClient)
+			}).
+				WithTimeout(env.Timeout(timeout.ResourceApply)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+
+			Eventually(checkPersistencePodReady(env.Context(), wcClient)).
+				WithTimeout(env.Timeout(timeout.WorkloadsReady)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+
+			var err error
+			persisted, err = writePersistedData(env.Context(), wcClient)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		releases, err := intermediateReleases()
+		if err != nil {
+			// The spec tree can't be built without the releases to upgrade through.
+			panic(err)
+		}
+		for _, release := range releases {
+			Context(fmt.Sprintf("upgrade to intermediate release %s", release), func() {
+				It(fmt.Sprintf("passes the preflight checks for release %s", release), func() {
+					preflight(application.ReleasePair{Version: release}, true)
+				})
+
+				It(fmt.Sprintf("should apply release %s successfully", release), func() {
+					applyRelease(application.ReleasePair{Version: release}, true)
+				})
+
+				It("successfully finishes the control plane nodes rolling update if it is needed", func() {
+					waitForControlPlaneRoll()
+				})
+
+				healthChecks()
+			})
+		}
+
+		It("passes the pre-upgrade preflight checks", func() {
+			preflight(application.ReleasePair{Version: "", Commit: ""}, false)
+		})
+
+		It("should apply new version successfully", func() {
+			// Set release versions to `""` so that it makes use of the overrides set in the `E2E_RELEASE_VERSION` environment var
+			applyRelease(application.ReleasePair{Version: "", Commit: ""}, false)
+		})
+
+		It("successfully finishes the control plane nodes rolling update if it is needed", func() {
+			waitForControlPlaneRoll()
 		})
 
 		It("detects if nodes were rolled", func() {
@@ -350,82 +490,137 @@ func Run(cfg *TestConfig) {
 			}
 
 			// Log initial nodes with UIDs for debugging
-			initialNodeNames := make([]string, 0, len(initialNodes))
-			for nodeName := range initialNodes {
-				initialNodeNames = append(initialNodeNames, nodeName)
-			}
-			sort.Strings(initialNodeNames)
-			logger.Log("Node roll detection - Initial nodes (%d): %v", len(initialNodes), initialNodeNames)
-			for _, name := range initialNodeNames {
+			initialNodes := rollWatcher.tracker.initial
+			logger.Log("Node roll detection - Initial nodes (%d): %v", len(initialNodes), sortedNodeNames(initialNodes))
+			for _, name := range sortedNodeNames(initialNodes) {
 				info := initialNodes[name]
-				logger.Log("  - %s (UID: %s, Created: %s)", name, info.UID, info.CreatedAt.Format(time.RFC3339))
+				logger.Log("  - %s (pool: %s, UID: %s, Created: %s)", name, info.Pool, info.UID, info.CreatedAt.Format(time.RFC3339))
 			}
 
-			rolled := false
-			var rolledNodes []string
-			var replacedNodes []string
-			timeout := 15 * time.Minute // node rolls can take a long time in some providers
+			// Poll for node rolling without failing the test if it doesn't happen (e.g. scale-up)
+			rollTimeout := env.Timeout(timeout.NodeRollDetection) // node rolls can take a long time in some providers
 			startTime := time.Now()
+			for !rollWatcher.tracker.Report().Rolled && time.Since(startTime) < rollTimeout {
+				time.Sleep(10 * time.Second)
+			}
 
-			// Poll for node rolling without failing the test if it doesn't happen (e.g. scale-up)
-			for {
-				nodes := &corev1.NodeList{}
-				if err := wcClient.List(state.GetContext(), nodes); err != nil {
-					logger.Log("Failed to list nodes for roll detection: %v", err)
-				} else {
-					// Build current node map with UIDs
-					currentNodes := make(map[string]nodeInfo, len(nodes.Items))
-					currentNodeNames := make([]string, 0, len(nodes.Items))
-					for _, node := range nodes.Items {
-						currentNodes[node.Name] = nodeInfo{
-							Name:      node.Name,
-							UID:       node.UID,
-							CreatedAt: node.CreationTimestamp.Time,
-						}
-						currentNodeNames = append(currentNodeNames, node.Name)
-					}
-
-					rolledNodes = nil
-					replacedNodes = nil
-
-					// Check each initial node
-					for nodeName, initialInfo := range initialNodes {
-						if currentInfo, exists := currentNodes[nodeName]; exists {
-							// Node with same name exists - check if it was replaced (different UID)
-							if currentInfo.UID != initialInfo.UID {
-								replacedNodes = append(replacedNodes, nodeName)
-								rolled = true
-								logger.Log("Node %s was replaced (UID changed: %s -> %s)", nodeName, initialInfo.UID, currentInfo.UID)
-							}
-						} else {
-							// Node no longer exists - it was rolled away
-							rolledNodes = append(rolledNodes, nodeName)
-							rolled = true
-							logger.Log("Node %s was rolled (not found in current nodes: %v)", nodeName, currentNodeNames)
-						}
-					}
-
-					// Also check for new nodes that appeared (could indicate scale-up vs roll)
-					// If node count increased and no nodes were removed, it's likely a scale-up, not a roll
-					if len(nodes.Items) > initialNodeCount && len(rolledNodes) == 0 && len(replacedNodes) == 0 {
-						logger.Log("Node count increased from %d to %d without removing existing nodes - likely a scale-up, not a roll",
-							initialNodeCount, len(nodes.Items))
-					}
+			// Once a pool started rolling, give every rolling pool the same time again to finish,
+			// so the report tells how long each of them took.
+			if rollWatcher.tracker.Report().Rolled {
+				startTime = time.Now()
+				for !rollWatcher.tracker.Report().RollComplete() && time.Since(startTime) < rollTimeout {
+					time.Sleep(10 * time.Second)
 				}
+			}
 
-				if rolled || time.Since(startTime) >= timeout {
-					break
-				}
-				time.Sleep(10 * time.Second)
+			report := rollWatcher.Stop()
+			logger.Log("%s", report)
+
+			reportJSON, err := json.Marshal(report)
+			Expect(err).NotTo(HaveOccurred())
+			helper.RecordNodeRollReport(string(reportJSON))
+
+			nodesRolled = report.Rolled
+			helper.RecordNodeRolling(report.Rolled)
+		})
+
+		It("kept the workload available during the upgrade", func() {
+			if prober == nil {
+				Fail("The availability prober wasn't started")
+			}
+
+			timeline := prober.Stop()
+			logger.Log("Workload availability timeline:\n%s", timeline)
+			helper.RecordWorkloadAvailability(timeline.String())
+
+			for _, target := range timeline.Targets {
+				Expect(timeline.Downtime(target)).To(BeNumerically("<=", cfg.MaxWorkloadDowntime),
+					"app was unreachable %s for longer than the budget of %s", target, cfg.MaxWorkloadDowntime)
+			}
+		})
+
+		It("kept the API server available during the upgrade", func() {
+			if watcher == nil {
+				Skip("The API server isn't reachable from the test runner")
 			}
 
-			// Final summary
-			if rolled {
-				logger.Log("Node roll detection result: rolled=true (removed: %v, replaced: %v)", rolledNodes, replacedNodes)
+			result := watcher.Stop()
+			logger.Log("API server availability:\n%s", result)
+			helper.RecordAPIServerAvailability(result.String())
+
+			for _, check := range result.Timeline.Targets {
+				Expect(result.Timeline.Downtime(check)).To(BeNumerically("<=", cfg.MaxAPIServerUnavailability),
+					"API server failed the %s check for longer than the threshold of %s", check, cfg.MaxAPIServerUnavailability)
+			}
+		})
+
+		It("keeps the StatefulSet data after the nodes were rolled", func() {
+			if persisted.Checksum == "" {
+				Fail("No data was written to the StatefulSet volume before the upgrade")
+			}
+
+			if nodesRolled {
+				// The roll detection only waits for the first node to go, the node of the
+				// StatefulSet pod may be rolled later.
+				Eventually(func() (bool, error) {
+					return nodeStillExists(env.Context(), wcClient, persisted.NodeName, persisted.NodeUID)
+				}).
+					WithTimeout(env.Timeout(timeout.NodeRollDetection)).
+					WithPolling(wait.DefaultInterval).
+					Should(BeFalse(), "node %s running the StatefulSet pod was not rolled", persisted.NodeName)
 			} else {
-				logger.Log("Node roll detection result: rolled=false - all %d initial nodes still present with same UIDs", len(initialNodes))
+				logger.Log("Nodes were not rolled, only checking the data of the StatefulSet pod on node '%s'", persisted.NodeName)
+			}
+
+			Eventually(checkPersistencePodReady(env.Context(), wcClient)).
+				WithTimeout(env.Timeout(timeout.WorkloadsReady)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
+
+			if nodesRolled {
+				current, err := currentPersistenceLocation(env.Context(), wcClient)
+				Expect(err).NotTo(HaveOccurred())
+				logger.Log("StatefulSet pod moved from node '%s' to node '%s'", persisted.NodeName, current.NodeName)
+				Expect(current.NodeUID).NotTo(Equal(persisted.NodeUID), "StatefulSet pod was not rescheduled onto a new node")
+				Expect(current.PodUID).NotTo(Equal(persisted.PodUID), "StatefulSet pod was not recreated")
 			}
-			helper.RecordNodeRolling(rolled)
+
+			checksum, err := readPersistedChecksum(env.Context(), wcClient)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(checksum).To(Equal(persisted.Checksum), "data on the StatefulSet volume changed during the upgrade")
+		})
+
+		AfterAll(func() {
+			if rollWatcher != nil {
+				rollWatcher.Stop()
+			}
+			if prober != nil {
+				prober.Stop()
+			}
+			if watcher != nil {
+				watcher.Stop()
+			}
+
+			wc, err := env.WC()
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func() error {
+				if err := deleteAvailabilityNamespace(env.Context(), wc); err != nil {
+					return err
+				}
+				return deletePersistenceNamespace(env.Context(), wc)
+			}).
+				WithTimeout(env.Timeout(timeout.ResourceApply)).
+				WithPolling(wait.DefaultInterval).
+				Should(Succeed())
 		})
 	})
 }
+
+// timeoutOrDefault returns override if set, otherwise the effective timeout for key.
+func timeoutOrDefault(env *state.Environment, override time.Duration, key timeout.TestKey) time.Duration {
+	if override > 0 {
+		return override
+	}
+	return env.Timeout(key)
+}
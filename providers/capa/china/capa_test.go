@@ -1,20 +1,14 @@
 package china
 
 import (
-	"time"
-
 	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
 
 	"github.com/giantswarm/cluster-test-suites/v7/internal/common"
-	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
-	"github.com/giantswarm/cluster-test-suites/v7/internal/timeout"
 )
 
 var _ = Describe("Common tests", func() {
-	BeforeEach(func() {
-		// Set the timeout for deploying apps to 25 minutes for China test
-		state.SetTestTimeout(timeout.DeployApps, time.Minute*25)
-	})
+	manifest := common.MustLoadSuiteManifest(common.SuiteManifestPath)
+	manifest.Setup(testEnv)
 
-	common.Run(common.NewTestConfigWithDefaults())
+	common.Run(testEnv, manifest.TestConfig())
 })
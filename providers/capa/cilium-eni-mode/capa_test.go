@@ -11,21 +11,23 @@ import (
 
 	"github.com/giantswarm/cluster-test-suites/v7/internal/common"
 	"github.com/giantswarm/cluster-test-suites/v7/internal/ecr"
-	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
 )
 
 var _ = Describe("Cilium ENI mode tests", func() {
-	common.Run(common.NewTestConfigWithDefaults())
+	manifest := common.MustLoadSuiteManifest(common.SuiteManifestPath)
+	manifest.Setup(testEnv)
+
+	common.Run(testEnv, manifest.TestConfig())
 
 	// ECR Credential Provider specific tests
-	ecr.Run()
+	ecr.Run(testEnv)
 
 	runSecondaryPodIPs()
 })
 
 func runSecondaryPodIPs() {
 	It("assigns IP addresses from secondary VPC CIDR to pods", func() {
-		wcClient, err := state.GetFramework().WC(state.GetCluster().Name)
+		wcClient, err := testEnv.WC()
 		if err != nil {
 			Fail(err.Error())
 		}
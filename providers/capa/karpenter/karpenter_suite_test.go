@@ -0,0 +1,23 @@
+package karpenter
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+	. "github.com/onsi/gomega"    //nolint:staticcheck
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/suite"
+
+	"github.com/giantswarm/cluster-standup-teardown/v6/pkg/clusterbuilder/providers/capa"
+)
+
+// testEnv is populated by the BeforeSuite registered in suite.Setup and shared with the specs.
+var testEnv *state.Environment
+
+func TestCAPAKarpenter(t *testing.T) {
+	testEnv = suite.Setup(false, &capa.ClusterBuilder{})
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CAPA Karpenter Suite")
+}
@@ -0,0 +1,18 @@
+package karpenter
+
+import (
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/common"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/karpenter"
+)
+
+var _ = Describe("Karpenter tests", func() {
+	manifest := common.MustLoadSuiteManifest(common.SuiteManifestPath)
+	manifest.Setup(testEnv)
+
+	common.Run(testEnv, manifest.TestConfig())
+
+	// Karpenter NodePool, scale-up and consolidation tests
+	karpenter.Run(testEnv)
+})
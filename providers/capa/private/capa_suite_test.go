@@ -6,13 +6,17 @@ import (
 	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
 	. "github.com/onsi/gomega"    //nolint:staticcheck
 
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
 	"github.com/giantswarm/cluster-test-suites/v7/internal/suite"
 
 	"github.com/giantswarm/cluster-standup-teardown/v6/pkg/clusterbuilder/providers/capa"
 )
 
+// testEnv is populated by the BeforeSuite registered in suite.Setup and shared with the specs.
+var testEnv *state.Environment
+
 func TestCAPAPrivate(t *testing.T) {
-	suite.Setup(false, &capa.PrivateClusterBuilder{})
+	testEnv = suite.Setup(false, &capa.PrivateClusterBuilder{})
 
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "CAPA Private Suite")
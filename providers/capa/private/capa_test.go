@@ -8,10 +8,11 @@ import (
 )
 
 var _ = Describe("Common tests", func() {
-	cfg := common.NewTestConfigWithDefaults()
-	cfg.GatewayAPISupported = false
-	common.Run(cfg)
+	manifest := common.MustLoadSuiteManifest(common.SuiteManifestPath)
+	manifest.Setup(testEnv)
+
+	common.Run(testEnv, manifest.TestConfig())
 
 	// ECR Credential Provider specific tests
-	ecr.Run()
+	ecr.Run(testEnv)
 })
@@ -13,6 +13,7 @@ import (
 	"github.com/giantswarm/cluster-standup-teardown/v6/pkg/values"
 	"github.com/giantswarm/clustertest/v5/pkg/env"
 
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
 	"github.com/giantswarm/cluster-test-suites/v7/internal/suite"
 )
 
@@ -49,6 +50,9 @@ func armSupported() bool {
 	return !core.LessThan(armMinRelease)
 }
 
+// testEnv is populated by the BeforeSuite registered in suite.Setup and shared with the specs.
+var testEnv *state.Environment
+
 func TestCAPAStandard(t *testing.T) {
 	opts := []suite.Option{
 		suite.WithExtraClusterValues(func() (string, error) {
@@ -58,7 +62,7 @@ func TestCAPAStandard(t *testing.T) {
 			return values.MustLoadValuesFile("./test_data/cluster_values_arm.yaml"), nil
 		}),
 	}
-	suite.SetupWithOptions(false, &capa.ClusterBuilder{}, opts)
+	testEnv = suite.SetupWithOptions(false, &capa.ClusterBuilder{}, opts)
 
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "CAPA Standard Suite")
@@ -9,11 +9,18 @@ import (
 )
 
 var _ = Describe("Basic upgrade test", Ordered, func() {
-	upgrade.Run(upgrade.NewTestConfigWithDefaults())
+	manifest := common.MustLoadSuiteManifest(common.SuiteManifestPath)
+	manifest.Setup(testEnv)
+	ccfg := manifest.TestConfig()
+
+	cfg := upgrade.NewTestConfigWithDefaults()
+	cfg.ObservabilityBundleInstalled = ccfg.ObservabilityBundleInstalled
+	cfg.SecurityBundleInstalled = ccfg.SecurityBundleInstalled
+	upgrade.Run(testEnv, cfg)
 
 	// Finally run the common tests after upgrade is completed
-	common.Run(common.NewTestConfigWithDefaults())
+	common.Run(testEnv, ccfg)
 
 	// ECR Credential Provider specific tests
-	ecr.Run()
+	ecr.Run(testEnv)
 })
@@ -7,10 +7,8 @@ import (
 )
 
 var _ = XDescribe("Common tests", func() {
-	cfg := common.NewTestConfigWithDefaults()
-	// No autoscaling on-prem
-	cfg.AutoScalingSupported = false
-	// Disabled until https://github.com/giantswarm/roadmap/issues/1037
-	cfg.ExternalDnsSupported = false
-	common.Run(cfg)
+	manifest := common.MustLoadSuiteManifest(common.SuiteManifestPath)
+	manifest.Setup(testEnv)
+
+	common.Run(testEnv, manifest.TestConfig())
 })
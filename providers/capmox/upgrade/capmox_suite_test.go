@@ -8,11 +8,15 @@ import (
 
 	"github.com/giantswarm/cluster-standup-teardown/v6/pkg/clusterbuilder/providers/capmox"
 
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
 	"github.com/giantswarm/cluster-test-suites/v7/internal/suite"
 )
 
+// testEnv is populated by the BeforeSuite registered in suite.Setup and shared with the specs.
+var testEnv *state.Environment
+
 func TestCAPMOXUpgrade(t *testing.T) {
-	suite.Setup(true, &capmox.ClusterBuilder{})
+	testEnv = suite.Setup(true, &capmox.ClusterBuilder{})
 
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "CAPMOX Upgrade Suite")
@@ -7,12 +7,8 @@ import (
 )
 
 var _ = Describe("Common tests", func() {
-	cfg := common.NewTestConfigWithDefaults()
-	// No autoscaling on-prem
-	cfg.AutoScalingSupported = false
-	// Disabled until https://github.com/giantswarm/roadmap/issues/1037
-	cfg.ExternalDnsSupported = false
-	cfg.GatewayAPISupported = false
+	manifest := common.MustLoadSuiteManifest(common.SuiteManifestPath)
+	manifest.Setup(testEnv)
 
-	common.Run(cfg)
+	common.Run(testEnv, manifest.TestConfig())
 })
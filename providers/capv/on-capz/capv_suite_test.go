@@ -8,11 +8,15 @@ import (
 
 	"github.com/giantswarm/cluster-standup-teardown/v6/pkg/clusterbuilder/providers/capv"
 
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
 	"github.com/giantswarm/cluster-test-suites/v7/internal/suite"
 )
 
+// testEnv is populated by the BeforeSuite registered in suite.Setup and shared with the specs.
+var testEnv *state.Environment
+
 func TestCAPVOnCAPZ(t *testing.T) {
-	suite.Setup(false, &capv.ClusterBuilder{CustomKubeContext: "capv-on-capz"})
+	testEnv = suite.Setup(false, &capv.ClusterBuilder{CustomKubeContext: "capv-on-capz"})
 
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "CAPV on CAPZ Suite")
@@ -8,14 +8,15 @@ import (
 )
 
 var _ = Describe("Basic upgrade test", Ordered, func() {
-	upgrade.Run(upgrade.NewTestConfigWithDefaults())
+	manifest := common.MustLoadSuiteManifest(common.SuiteManifestPath)
+	manifest.Setup(testEnv)
+	ccfg := manifest.TestConfig()
+
+	cfg := upgrade.NewTestConfigWithDefaults()
+	cfg.ObservabilityBundleInstalled = ccfg.ObservabilityBundleInstalled
+	cfg.SecurityBundleInstalled = ccfg.SecurityBundleInstalled
+	upgrade.Run(testEnv, cfg)
 
 	// Finally run the common tests after upgrade is completed
-	cfg := common.NewTestConfigWithDefaults()
-	// No autoscaling on-prem
-	cfg.AutoScalingSupported = false
-	// Disabled until https://github.com/giantswarm/roadmap/issues/1037
-	cfg.ExternalDnsSupported = false
-	cfg.GatewayAPISupported = false
-	common.Run(cfg)
+	common.Run(testEnv, ccfg)
 })
@@ -14,11 +14,8 @@ type ClusterValues struct {
 }
 
 var _ = Describe("Common tests", func() {
-	cfg := common.NewTestConfigWithDefaults()
-	// Disabled until https://github.com/giantswarm/roadmap/issues/2693
-	cfg.AutoScalingSupported = false
-	// Disabled until wildcard ingress support is added
-	cfg.ExternalDnsSupported = false
-	cfg.GatewayAPISupported = false
-	common.Run(cfg)
+	manifest := common.MustLoadSuiteManifest(common.SuiteManifestPath)
+	manifest.Setup(testEnv)
+
+	common.Run(testEnv, manifest.TestConfig())
 })
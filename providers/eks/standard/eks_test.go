@@ -7,15 +7,8 @@ import (
 )
 
 var _ = Describe("Common tests", func() {
-	cfg := common.NewTestConfigWithDefaults()
-	// EKS does not have metrics for k8s control plane components.
-	cfg.ControlPlaneMetricsSupported = false
-	// EKS doesn't have any of the Giant Swarm apps deployed
-	cfg.ObservabilityBundleInstalled = false
-	cfg.SecurityBundleInstalled = false
-	cfg.ExternalDnsSupported = false
-	cfg.AutoScalingSupported = false
-	cfg.CertManagerSupported = false
-	cfg.GatewayAPISupported = false
-	common.Run(cfg)
+	manifest := common.MustLoadSuiteManifest(common.SuiteManifestPath)
+	manifest.Setup(testEnv)
+
+	common.Run(testEnv, manifest.TestConfig())
 })
@@ -15,10 +15,13 @@ import (
 	"github.com/giantswarm/cluster-test-suites/v7/internal/suite"
 )
 
+// testEnv is populated by the BeforeSuite registered in suite.Setup and shared with the specs.
+var testEnv *state.Environment
+
 func TestEKSUpgrade(t *testing.T) {
-	suite.Setup(true, &capa.ManagedClusterBuilder{}, func(client *clustertestclient.Client) {
+	testEnv = suite.Setup(true, &capa.ManagedClusterBuilder{}, func(client *clustertestclient.Client) {
 		Eventually(
-			wait.AreNumNodesReady(state.GetContext(), client, 2, clustertestclient.DoesNotHaveLabels{"node-role.kubernetes.io/control-plane"}),
+			wait.AreNumNodesReady(testEnv.Context(), client, 2, clustertestclient.DoesNotHaveLabels{"node-role.kubernetes.io/control-plane"}),
 			20*time.Minute, 15*time.Second,
 		).Should(BeTrue())
 	})
@@ -8,23 +8,16 @@ import (
 )
 
 var _ = Describe("Basic upgrade test", Ordered, func() {
+	manifest := common.MustLoadSuiteManifest(common.SuiteManifestPath)
+	manifest.Setup(testEnv)
+	ccfg := manifest.TestConfig()
+
 	cfg := upgrade.NewTestConfigWithDefaults()
 	cfg.ControlPlaneType = upgrade.ControlPlaneTypeAWSManaged
-	// EKS doesn't have any of the Giant Swarm apps deployed
-	cfg.ObservabilityBundleInstalled = false
-	cfg.SecurityBundleInstalled = false
-	upgrade.Run(cfg)
+	cfg.ObservabilityBundleInstalled = ccfg.ObservabilityBundleInstalled
+	cfg.SecurityBundleInstalled = ccfg.SecurityBundleInstalled
+	upgrade.Run(testEnv, cfg)
 
 	// Finally run the common tests after upgrade is completed
-	ccfg := common.NewTestConfigWithDefaults()
-	// EKS does not have metrics for k8s control plane components.
-	ccfg.ControlPlaneMetricsSupported = false
-	// EKS doesn't have any of the Giant Swarm apps deployed
-	ccfg.ObservabilityBundleInstalled = false
-	ccfg.SecurityBundleInstalled = false
-	ccfg.ExternalDnsSupported = false
-	ccfg.AutoScalingSupported = false
-	ccfg.CertManagerSupported = false
-	ccfg.GatewayAPISupported = false
-	common.Run(ccfg)
+	common.Run(testEnv, ccfg)
 })
@@ -0,0 +1,35 @@
+package standard
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+	. "github.com/onsi/gomega"    //nolint:staticcheck
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/local"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/state"
+	"github.com/giantswarm/cluster-test-suites/v7/internal/suite"
+)
+
+// testEnv is populated by the BeforeSuite registered in suite.Setup and shared with the specs.
+var testEnv *state.Environment
+
+func TestLocalStandard(t *testing.T) {
+	localCluster, err := local.Start(local.Config{})
+	if errors.Is(err, local.ErrNotConfigured) {
+		t.Skipf("Skipping local suite: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("failed to start local cluster: %v", err)
+	}
+	defer localCluster.Stop() // nolint:errcheck
+
+	testEnv = suite.SetupWithOptions(false, localCluster.ClusterBuilder(), []suite.Option{
+		suite.WithStandup(localCluster.Standup),
+		suite.WithTeardown(localCluster.Teardown),
+	})
+
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Local Standard Suite")
+}
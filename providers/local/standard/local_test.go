@@ -0,0 +1,14 @@
+package standard
+
+import (
+	. "github.com/onsi/ginkgo/v2" //nolint:staticcheck
+
+	"github.com/giantswarm/cluster-test-suites/v7/internal/common"
+)
+
+var _ = Describe("Common tests", func() {
+	manifest := common.MustLoadSuiteManifest(common.SuiteManifestPath)
+	manifest.Setup(testEnv)
+
+	common.Run(testEnv, manifest.TestConfig())
+})